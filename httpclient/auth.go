@@ -12,6 +12,7 @@ import (
 	"strings"
 
 	"github.com/Carbonfrost/joe-cli"
+	"github.com/Carbonfrost/joe-cli-http/tls"
 	"github.com/Carbonfrost/joe-cli/extensions/bind"
 	"github.com/Carbonfrost/joe-cli/extensions/provider"
 )
@@ -232,7 +233,8 @@ func taggedProviderArgumentFlag(v *provider.Value) cli.Prototype {
 }
 
 // PromptForCredentials will display prompts for user and/or password credentials if
-// authentication is required.
+// authentication is required.  The pass phrase of an encrypted client
+// certificate key is also prompted for if it wasn't specified.
 func PromptForCredentials() cli.Action {
 	return cli.Before(cli.ActionFunc(promptForPassword))
 }
@@ -240,7 +242,9 @@ func PromptForCredentials() cli.Action {
 func promptForPassword(c *cli.Context) error {
 	client := FromContext(c)
 	client.AddAuthenticatorMiddleware(WithPromptForCredentials)
-	return nil
+	return tls.WithPasswordPrompt(func() (string, error) {
+		return c.ReadPasswordString("Enter pass phrase: ")
+	}).Execute(c)
 }
 
 func SetUser(s ...*UserInfo) cli.Action {
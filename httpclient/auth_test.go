@@ -5,9 +5,13 @@
 package httpclient_test
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
+	"strings"
 
+	"github.com/Carbonfrost/joe-cli"
 	"github.com/Carbonfrost/joe-cli-http/httpclient"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -74,3 +78,23 @@ var _ = Describe("NewBearerAuthenticator", func() {
 		Entry("Custom plus values 2", "X-Token Bearer A", HaveKeyWithValue("X-Token", []string{"Bearer A TOKEN"})),
 	)
 })
+
+var _ = Describe("PromptForCredentials", func() {
+
+	DescribeTable("pass phrase of the client key", func(args string, expectedPrompt string) {
+		var stderr bytes.Buffer
+		app := &cli.App{
+			Uses:   httpclient.New(),
+			Action: func() {},
+			Stdin:  strings.NewReader(""),
+			Stderr: &stderr,
+		}
+
+		argv, _ := cli.Split("app --cert ../tls/testdata/cert.pem --key ../tls/testdata/key-aes256.pem " + args)
+		_ = app.RunContext(context.Background(), argv)
+		Expect(stderr.String()).To(Equal(expectedPrompt))
+	},
+		Entry("prompts when not specified", "", "Enter pass phrase: "),
+		Entry("doesn't prompt with --pass", "--pass secret", ""),
+	)
+})
@@ -0,0 +1,565 @@
+// Copyright 2026 The Joe-cli Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/Carbonfrost/joe-cli"
+)
+
+// BenchFormat is the output format of the benchmark report
+type BenchFormat int
+
+// Benchmark report formats
+const (
+	BenchText BenchFormat = iota
+	BenchJSON
+)
+
+const (
+	defaultBenchRequests    = 200
+	defaultBenchConcurrency = 50
+	benchHistogramBuckets   = 10
+)
+
+// Benchmark contains the settings for load testing.  The client
+// configuration is shared by all workers, which issue the request
+// concurrently until the number of requests or duration is reached.
+type Benchmark struct {
+	// Requests is the total number of requests to issue
+	Requests int
+	// Concurrency is the number of workers issuing requests
+	Concurrency int
+	// Duration stops the benchmark after the given amount of time, even if
+	// the number of requests was not reached
+	Duration time.Duration
+	// Format of the report
+	Format BenchFormat
+
+	enabled bool
+}
+
+// BenchReport summarizes the results of the benchmark.  Times are expressed
+// in seconds.
+type BenchReport struct {
+	Requests          int               `json:"requests"`
+	Concurrency       int               `json:"concurrency"`
+	Total             float64           `json:"total"`
+	RequestsPerSecond float64           `json:"requestsPerSecond"`
+	BytesTransferred  int64             `json:"bytesTransferred"`
+	Latency           BenchLatency      `json:"latency"`
+	Percentiles       []BenchPercentile `json:"percentiles"`
+	Histogram         []BenchBucket     `json:"histogram"`
+	StatusCodes       map[string]int    `json:"statusCodes"`
+	Errors            map[string]int    `json:"errors"`
+	ErrorSamples      map[string]string `json:"errorSamples,omitempty"`
+}
+
+// BenchLatency summarizes latencies in seconds
+type BenchLatency struct {
+	Fastest float64 `json:"fastest"`
+	Slowest float64 `json:"slowest"`
+	Average float64 `json:"average"`
+}
+
+// BenchPercentile is the latency in seconds at the given percentile
+type BenchPercentile struct {
+	Percentile int     `json:"percentile"`
+	Latency    float64 `json:"latency"`
+}
+
+// BenchBucket counts responses whose latency in seconds is at most Mark and
+// greater than the previous bucket
+type BenchBucket struct {
+	Mark  float64 `json:"mark"`
+	Count int     `json:"count"`
+}
+
+type benchResult struct {
+	latency time.Duration
+	status  int
+	size    int64
+	err     error
+}
+
+type benchTarget struct {
+	req     *http.Request
+	body    []byte
+	release func()
+}
+
+var (
+	benchFormatStrings = map[BenchFormat]string{
+		BenchText: "text",
+		BenchJSON: "json",
+	}
+
+	benchPercentiles = []int{10, 25, 50, 75, 90, 95, 99}
+)
+
+// SetBench enables benchmark mode, which issues the request repeatedly and
+// prints a report instead of the response
+func (c *Client) SetBench(v bool) error {
+	c.benchmark.enabled = v
+	return nil
+}
+
+// SetBenchRequests sets the number of requests to issue in benchmark mode
+func (c *Client) SetBenchRequests(v int) error {
+	if v < 0 {
+		return fmt.Errorf("number of requests must not be negative")
+	}
+	c.benchmark.Requests = v
+	return nil
+}
+
+// SetBenchConcurrency sets the number of workers used in benchmark mode
+func (c *Client) SetBenchConcurrency(v int) error {
+	if v < 1 {
+		return fmt.Errorf("concurrency must be at least 1")
+	}
+	c.benchmark.Concurrency = v
+	return nil
+}
+
+// SetBenchDuration sets how long the benchmark runs
+func (c *Client) SetBenchDuration(v time.Duration) error {
+	c.benchmark.Duration = v
+	return nil
+}
+
+// SetBenchFormat sets the format of the benchmark report
+func (c *Client) SetBenchFormat(v BenchFormat) error {
+	c.benchmark.Format = v
+	return nil
+}
+
+// Bench issues the request repeatedly from concurrent workers and
+// reports the results.  When several locations are requested, workers
+// cycle through them.
+func (c *Client) Bench(ctx context.Context) (*BenchReport, error) {
+	targets, err := c.benchTargets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		for _, t := range targets {
+			t.release()
+		}
+	}()
+
+	b := c.benchmark.withDefaults()
+	client := c.ensureClient(ctx)
+	jobs := make(chan int)
+	results := make([][]benchResult, b.Concurrency)
+
+	var wg sync.WaitGroup
+	start := time.Now()
+	for w := range b.Concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[w] = append(results[w], benchOne(client, targets[i%len(targets)]))
+			}
+		}()
+	}
+
+	var deadline <-chan time.Time
+	if b.Duration > 0 {
+		timer := time.NewTimer(b.Duration)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+
+dispatch:
+	for i := 0; b.Requests == 0 || i < b.Requests; i++ {
+		select {
+		case jobs <- i:
+		case <-deadline:
+			break dispatch
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	return newBenchReport(b, time.Since(start), slices.Concat(results...)), nil
+}
+
+func (b Benchmark) withDefaults() Benchmark {
+	if b.Requests == 0 && b.Duration == 0 {
+		b.Requests = defaultBenchRequests
+	}
+	if b.Concurrency == 0 {
+		b.Concurrency = defaultBenchConcurrency
+	}
+	if b.Requests > 0 && b.Concurrency > b.Requests {
+		b.Concurrency = b.Requests
+	}
+	return b
+}
+
+func (c *Client) benchTargets(ctx context.Context) ([]benchTarget, error) {
+	c.ensureExprHandling(cli.FromContext(ctx))
+
+	locations, err := c.ensureLocationResolver().Resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(locations) == 0 {
+		return nil, fmt.Errorf("no URL to benchmark")
+	}
+
+	targets := make([]benchTarget, 0, len(locations))
+	for _, l := range locations {
+		rctx, u, err := l.URL(ctx)
+		if err != nil {
+			return nil, err
+		}
+		rctx, release := requestContext(ctx, rctx)
+
+		c.Request.URL = u
+		c.Request.Host = u.Host
+		c.Request = c.Request.WithContext(rctx)
+		if err := c.generateMiddleware(l).Handle(c.Request, nil); err != nil {
+			release()
+			return nil, err
+		}
+
+		// The body is buffered so that it can be sent by each request
+		var body []byte
+		if c.Request.Body != nil {
+			body, err = io.ReadAll(c.Request.Body)
+			if err != nil {
+				release()
+				return nil, err
+			}
+			c.Request.Body.Close()
+			c.Request.Body = nil
+		}
+		targets = append(targets, benchTarget{
+			req:     c.Request.Clone(rctx),
+			body:    body,
+			release: release,
+		})
+	}
+	return targets, nil
+}
+
+func benchOne(client *http.Client, t benchTarget) benchResult {
+	req := t.req.Clone(t.req.Context())
+	if t.body != nil {
+		req.Body = io.NopCloser(bytes.NewReader(t.body))
+		req.ContentLength = int64(len(t.body))
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(t.body)), nil
+		}
+	}
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return benchResult{latency: time.Since(start), err: err}
+	}
+	size, err := io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	return benchResult{
+		latency: time.Since(start),
+		status:  resp.StatusCode,
+		size:    size,
+		err:     err,
+	}
+}
+
+func newBenchReport(b Benchmark, total time.Duration, results []benchResult) *BenchReport {
+	r := &BenchReport{
+		Requests:     len(results),
+		Concurrency:  b.Concurrency,
+		Total:        total.Seconds(),
+		StatusCodes:  map[string]int{},
+		Errors:       map[string]int{},
+		ErrorSamples: map[string]string{},
+	}
+	if total > 0 {
+		r.RequestsPerSecond = float64(len(results)) / total.Seconds()
+	}
+
+	latencies := make([]float64, 0, len(results))
+	var sum float64
+	for _, res := range results {
+		r.BytesTransferred += res.size
+		if res.err != nil {
+			class := classifyBenchError(res.err)
+			r.Errors[class]++
+			if _, ok := r.ErrorSamples[class]; !ok {
+				r.ErrorSamples[class] = res.err.Error()
+			}
+			continue
+		}
+		r.StatusCodes[strconv.Itoa(res.status)]++
+
+		latencies = append(latencies, res.latency.Seconds())
+		sum += res.latency.Seconds()
+	}
+	if len(latencies) == 0 {
+		return r
+	}
+
+	sort.Float64s(latencies)
+	r.Latency = BenchLatency{
+		Fastest: latencies[0],
+		Slowest: latencies[len(latencies)-1],
+		Average: sum / float64(len(latencies)),
+	}
+	for _, p := range benchPercentiles {
+		i := int(math.Ceil(float64(p)/100*float64(len(latencies)))) - 1
+		r.Percentiles = append(r.Percentiles, BenchPercentile{
+			Percentile: p,
+			Latency:    latencies[max(i, 0)],
+		})
+	}
+	r.Histogram = benchHistogram(latencies)
+	return r
+}
+
+func benchHistogram(sorted []float64) []BenchBucket {
+	fastest, slowest := sorted[0], sorted[len(sorted)-1]
+	width := (slowest - fastest) / benchHistogramBuckets
+
+	buckets := make([]BenchBucket, benchHistogramBuckets+1)
+	for i := range buckets {
+		buckets[i].Mark = fastest + width*float64(i)
+	}
+	buckets[benchHistogramBuckets].Mark = slowest
+
+	i := 0
+	for _, l := range sorted {
+		for i < len(buckets)-1 && l > buckets[i].Mark {
+			i++
+		}
+		buckets[i].Count++
+	}
+	return buckets
+}
+
+// classifyBenchError groups errors into broad classes for reporting
+func classifyBenchError(err error) string {
+	var (
+		dnsErr    *net.DNSError
+		netErr    net.Error
+		certErr   *tls.CertificateVerificationError
+		unknownCA x509.UnknownAuthorityError
+		hostErr   x509.HostnameError
+		recordErr tls.RecordHeaderError
+		alertErr  tls.AlertError
+		opErr     *net.OpError
+	)
+	switch {
+	case errors.As(err, &dnsErr):
+		return "dns"
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return "timeout"
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return "connection refused"
+	case errors.Is(err, syscall.ECONNRESET), errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return "connection reset"
+	case errors.As(err, &certErr), errors.As(err, &unknownCA), errors.As(err, &hostErr),
+		errors.As(err, &recordErr), errors.As(err, &alertErr):
+		return "tls"
+	case errors.As(err, &opErr):
+		return "network"
+	default:
+		return "other"
+	}
+}
+
+// Write writes the report in the given format
+func (r *BenchReport) Write(w io.Writer, format BenchFormat) error {
+	if format == BenchJSON {
+		e := json.NewEncoder(w)
+		e.SetIndent("", "    ")
+		return e.Encode(r)
+	}
+
+	fmt.Fprintln(w, "Summary:")
+	fmt.Fprintf(w, "  Total:\t%.4f secs\n", r.Total)
+	fmt.Fprintf(w, "  Requests:\t%d\n", r.Requests)
+	fmt.Fprintf(w, "  Concurrency:\t%d\n", r.Concurrency)
+	fmt.Fprintf(w, "  Slowest:\t%.4f secs\n", r.Latency.Slowest)
+	fmt.Fprintf(w, "  Fastest:\t%.4f secs\n", r.Latency.Fastest)
+	fmt.Fprintf(w, "  Average:\t%.4f secs\n", r.Latency.Average)
+	fmt.Fprintf(w, "  Requests/sec:\t%.4f\n", r.RequestsPerSecond)
+	fmt.Fprintf(w, "  Transferred:\t%d bytes\n", r.BytesTransferred)
+
+	if len(r.Histogram) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "Response time histogram:")
+		most := 0
+		for _, b := range r.Histogram {
+			most = max(most, b.Count)
+		}
+		for _, b := range r.Histogram {
+			bar := 0
+			if most > 0 {
+				bar = b.Count * 40 / most
+			}
+			fmt.Fprintf(w, "  %4.3f [%d]\t|%s\n", b.Mark, b.Count, strings.Repeat("■", bar))
+		}
+	}
+
+	if len(r.Percentiles) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "Latency distribution:")
+		for _, p := range r.Percentiles {
+			fmt.Fprintf(w, "  %d%% in %.4f secs\n", p.Percentile, p.Latency)
+		}
+	}
+
+	if len(r.StatusCodes) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "Status code distribution:")
+		for _, code := range sortedKeys(r.StatusCodes) {
+			fmt.Fprintf(w, "  [%s]\t%d responses\n", code, r.StatusCodes[code])
+		}
+	}
+
+	if len(r.Errors) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "Error distribution:")
+		for _, class := range sortedKeys(r.Errors) {
+			fmt.Fprintf(w, "  [%d]\t%s (%s)\n", r.Errors[class], class, r.ErrorSamples[class])
+		}
+	}
+	return nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func (BenchFormat) Synopsis() string {
+	return "text|json"
+}
+
+func (f BenchFormat) String() string {
+	return benchFormatStrings[f]
+}
+
+func (f *BenchFormat) Set(arg string) error {
+	for k, v := range benchFormatStrings {
+		if v == arg {
+			*f = k
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown benchmark format %q", arg)
+}
+
+// BenchAndPrint runs the benchmark and prints the report to stdout
+func BenchAndPrint() cli.Action {
+	return cli.ActionOf(func(c context.Context) error {
+		client := FromContext(c)
+		report, err := client.Bench(c)
+		if err != nil {
+			return err
+		}
+		return report.Write(cli.FromContext(c).Stdout, client.benchmark.Format)
+	})
+}
+
+func SetBench() cli.Action {
+	return cli.Pipeline(
+		&cli.Prototype{
+			Name:     "bench",
+			HelpText: "Issue the request repeatedly using concurrent workers and report latency and throughput",
+			Category: benchOptions,
+		},
+		withBindingTrue((*Client).SetBench),
+		tagged,
+	)
+}
+
+func SetBenchRequests(v ...int) cli.Action {
+	return cli.Pipeline(
+		&cli.Prototype{
+			Name:      "requests",
+			Aliases:   []string{"n"},
+			UsageText: "N",
+			HelpText:  "Number of requests to issue in benchmark mode",
+			Category:  benchOptions,
+		},
+		withBinding((*Client).SetBenchRequests, v),
+		tagged,
+	)
+}
+
+func SetBenchConcurrency(v ...int) cli.Action {
+	return cli.Pipeline(
+		&cli.Prototype{
+			Name:      "concurrency",
+			Aliases:   []string{"c"},
+			UsageText: "N",
+			HelpText:  "Number of workers issuing requests concurrently in benchmark mode",
+			Category:  benchOptions,
+		},
+		withBinding((*Client).SetBenchConcurrency, v),
+		tagged,
+	)
+}
+
+func SetBenchDuration(v ...time.Duration) cli.Action {
+	return cli.Pipeline(
+		&cli.Prototype{
+			Name:      "duration",
+			UsageText: "DURATION",
+			HelpText:  "Stop the benchmark after {DURATION} even if the number of requests was not reached",
+			Category:  benchOptions,
+		},
+		withBinding((*Client).SetBenchDuration, v),
+		tagged,
+	)
+}
+
+func SetBenchFormat(v ...BenchFormat) cli.Action {
+	return cli.Pipeline(
+		&cli.Prototype{
+			Name:       "bench-format",
+			HelpText:   "Format of the benchmark report",
+			Category:   benchOptions,
+			Completion: cli.ValueCompletion("text", "json"),
+		},
+		withBinding((*Client).SetBenchFormat, v),
+		tagged,
+	)
+}
+
+var _ flag.Value = (*BenchFormat)(nil)
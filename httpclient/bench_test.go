@@ -0,0 +1,100 @@
+// Copyright 2026 The Joe-cli Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package httpclient_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+
+	"github.com/Carbonfrost/joe-cli-http/httpclient"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gstruct"
+)
+
+var _ = Describe("Bench", func() {
+
+	var (
+		server *httptest.Server
+		count  atomic.Int64
+		bodies atomic.Int64
+	)
+
+	BeforeEach(func() {
+		count.Store(0)
+		bodies.Store(0)
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			n := count.Add(1)
+			if b, _ := io.ReadAll(r.Body); string(b) == "payload" {
+				bodies.Add(1)
+			}
+			if n%4 == 0 {
+				w.WriteHeader(http.StatusTeapot)
+			}
+			_, _ = w.Write([]byte("hello"))
+		}))
+		DeferCleanup(server.Close)
+	})
+
+	bench := func(args ...string) string {
+		out, err := runClient(append([]string{"--bench"}, args...)...)
+		Expect(err).NotTo(HaveOccurred())
+		return out
+	}
+
+	It("issues the number of requests", func() {
+		out := bench("-n", "40", "-c", "5", "--bench-format", "json", "--body", "payload", server.URL)
+
+		var report httpclient.BenchReport
+		Expect(json.Unmarshal([]byte(out), &report)).To(Succeed())
+
+		Expect(count.Load()).To(Equal(int64(40)))
+		Expect(bodies.Load()).To(Equal(int64(40)))
+		Expect(report).To(MatchFields(IgnoreExtras, Fields{
+			"Requests":         Equal(40),
+			"Concurrency":      Equal(5),
+			"BytesTransferred": Equal(int64(200)),
+			"StatusCodes":      Equal(map[string]int{"200": 30, "418": 10}),
+			"Errors":           BeEmpty(),
+			"Histogram":        HaveLen(11),
+			"Percentiles":      HaveLen(7),
+		}))
+		Expect(report.Latency.Fastest).To(BeNumerically("<=", report.Latency.Slowest))
+	})
+
+	It("reports errors by class", func() {
+		server.Close()
+		out := bench("-n", "3", "--bench-format", "json", server.URL)
+
+		var report httpclient.BenchReport
+		Expect(json.Unmarshal([]byte(out), &report)).To(Succeed())
+		Expect(report.Errors).To(Equal(map[string]int{"connection refused": 3}))
+	})
+
+	It("stops after the duration", func() {
+		out := bench("-c", "2", "--duration", "50ms", "--bench-format", "json", server.URL)
+
+		var report httpclient.BenchReport
+		Expect(json.Unmarshal([]byte(out), &report)).To(Succeed())
+		Expect(report.Requests).To(BeNumerically(">", 0))
+		Expect(report.Total).To(BeNumerically(">=", 0.05))
+	})
+
+	It("prints a text report", func() {
+		out := bench("-n", "4", "-c", "1", server.URL)
+
+		Expect(out).To(And(
+			ContainSubstring("Requests:\t4\n"),
+			ContainSubstring("Response time histogram:"),
+			ContainSubstring("50% in "),
+			ContainSubstring("  [200]\t3 responses\n"),
+			ContainSubstring("  [418]\t1 responses\n"),
+		))
+	})
+})
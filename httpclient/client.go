@@ -70,6 +70,8 @@ type Client struct {
 
 	downloader           Downloader
 	downloaderMiddleware []DownloaderMiddleware
+	pretty               *PrettyMode
+	benchmark            Benchmark
 
 	transport  cacheable[http.RoundTripper]
 	traceLevel TraceLevel
@@ -78,8 +80,12 @@ type Client struct {
 	interfaceResolver cacheable[InterfaceResolver]
 	dialer            *net.Dialer
 	dnsDialer         *net.Dialer
+	dialOverrides     dialOverrides
+	dns               dnsSettings
+	ipFamily          IPFamily
 	auth              Authenticator
 	authMiddleware    []AuthenticatorMiddleware
+	proxy             proxySettings
 
 	bodyForm     []*cli.NameValue
 	queryString  url.Values
@@ -170,13 +176,14 @@ func New(options ...Option) *Client {
 	h := &Client{
 		dnsDialer:   &net.Dialer{},
 		queryString: url.Values{},
+		logger:      nopTraceLogger{},
 		Request: &http.Request{
 			Method: "GET",
 		},
 	}
 	h.dialer = &net.Dialer{
 		Resolver: &net.Resolver{
-			Dial: h.dnsDialer.DialContext,
+			Dial: h.dialDNS,
 		},
 	}
 
@@ -329,6 +336,13 @@ func WithBody(b io.ReadCloser) Option {
 	})
 }
 
+// WithPretty sets how response bodies written to stdout are formatted
+func WithPretty(v PrettyMode) Option {
+	return func(c *Client) {
+		c.SetPretty(v)
+	}
+}
+
 func WithTraceLevel(v TraceLevel) Option {
 	return func(c *Client) {
 		c.SetTraceLevel(v)
@@ -410,7 +424,12 @@ func (c *Client) actualCheckRedirect() func(*http.Request, []*http.Request) erro
 	// Wrap with support from the logger
 	return func(req *http.Request, via []*http.Request) error {
 		err := redirect(req, via)
-		c.exprHandlingCache.eval(nil, req, nil)
+
+		var hop *responseStats
+		if r := statsRecorderFromContext(req.Context()); r != nil {
+			hop = r.redirected(via[len(via)-1], req.Response)
+		}
+		c.exprHandlingCache.eval(nil, req, nil, hop)
 		c.logger.Redirected(req, via, err)
 		return err
 	}
@@ -441,21 +460,30 @@ func (c *Client) doOne(ctx context.Context, l Location) (*Response, error) {
 	if err != nil {
 		return nil, err
 	}
+	rctx, release := requestContext(ctx, rctx)
+	defer release()
+
 	c.Request.URL = u
 	c.Request.Host = u.Host
-	c.Request = c.Request.WithContext(rctx)
+	stats := newStatsRecorder()
+	c.Request = c.Request.WithContext(stats.withContext(rctx))
 	c.generateMiddleware(l).Handle(c.Request, nil)
+	stats.countUpload(c.Request)
 
 	netResp, err := client.Do(c.Request)
 	if err != nil {
 		return nil, err
 	}
+	stats.done(netResp)
 	resp := &Response{
 		Response: netResp,
 	}
 
-	c.exprHandlingCache.eval(c.Request, nil, resp)
+	// Expressions are evaluated after the download so that the body size
+	// and total time are available.  As in cURL, this means that the output
+	// of --write-out follows the response body.
 	err = c.handleDownload(ctx, resp)
+	c.exprHandlingCache.eval(c.Request, nil, resp, resp.stats())
 	if err != nil {
 		return nil, err
 	}
@@ -463,6 +491,37 @@ func (c *Client) doOne(ctx context.Context, l Location) (*Response, error) {
 	return resp, nil
 }
 
+// requestContext derives the context of a request from rctx, which provides its
+// values.  The transport uses the context of the request from its own goroutines,
+// so the context is detached from the cli.Context, whose underlying context
+// changes as actions run.  The request is still canceled when the context
+// underlying ctx or rctx is done, and it keeps the deadline of rctx.  The function
+// returned releases the resources used to cancel the request.
+func requestContext(ctx, rctx context.Context) (context.Context, func()) {
+	result, cancel := context.WithCancelCause(context.WithoutCancel(rctx))
+	var stops []func() bool
+	for _, parent := range []context.Context{ctx, rctx} {
+		if c, ok := parent.(*cli.Context); ok {
+			parent = c.Context()
+		}
+		stops = append(stops, context.AfterFunc(parent, func() {
+			cancel(context.Cause(parent))
+		}))
+	}
+
+	cancelDeadline := context.CancelFunc(func() {})
+	if deadline, ok := rctx.Deadline(); ok {
+		result, cancelDeadline = context.WithDeadline(result, deadline)
+	}
+	return result, func() {
+		for _, stop := range stops {
+			stop()
+		}
+		cancelDeadline()
+		cancel(nil)
+	}
+}
+
 func (c *Client) handleDownload(ctx context.Context, response *Response) error {
 	if c.FailFast && !response.Success() {
 		return fmt.Errorf("request failed (%s): %s %s", response.Status, response.Request.Method, response.Request.URL)
@@ -474,8 +533,7 @@ func (c *Client) handleDownload(ctx context.Context, response *Response) error {
 	}
 
 	if c.IncludeResponseHeaders {
-		err = response.CopyHeadersTo(output)
-		fmt.Fprintln(output)
+		err = copyHeadersTo(output, response)
 	}
 	if err != nil {
 		return err
@@ -494,6 +552,21 @@ func (c *Client) handleDownload(ctx context.Context, response *Response) error {
 	return nil
 }
 
+// headersWriter is implemented by download writers that render
+// response headers differently from the body
+type headersWriter interface {
+	WriteHeaders(*Response) error
+}
+
+func copyHeadersTo(output io.Writer, response *Response) error {
+	if hw, ok := output.(headersWriter); ok {
+		return hw.WriteHeaders(response)
+	}
+	err := response.CopyHeadersTo(output)
+	fmt.Fprintln(output)
+	return err
+}
+
 func (c *Client) applyAuth(ctx context.Context) error {
 	auth := c.Authenticator()
 	for _, a := range c.authMiddleware {
@@ -598,6 +671,11 @@ func (c *Client) SetNoOutput(b bool) error {
 	return nil
 }
 
+func (c *Client) SetPretty(v PrettyMode) error {
+	c.pretty = &v
+	return nil
+}
+
 func (c *Client) SetIntegrity(i Integrity) error {
 	c.AddDownloaderMiddleware(func(_ context.Context, downloader Downloader) Downloader {
 		return NewIntegrityDownloader(i, downloader)
@@ -725,7 +803,8 @@ func (c *Client) openDownload(ctx context.Context, resp *Response) (io.WriteClos
 func (c *Client) actualDownloader(ctx context.Context) Downloader {
 	downloader := c.downloader
 	if c.downloader == nil {
-		downloader = NewDownloaderTo(cli.FromContext(ctx).Stdout)
+		// Only output to stdout is pretty-printed so that files are byte-exact
+		downloader = NewPrettyDownloader(c.prettyMode(ctx), NewDownloaderTo(cli.FromContext(ctx).Stdout))
 	}
 	for _, d := range c.downloaderMiddleware {
 		downloader = d(ctx, downloader)
@@ -733,6 +812,18 @@ func (c *Client) actualDownloader(ctx context.Context) Downloader {
 	return downloader
 }
 
+// prettyMode gets the pretty mode.  Unless it was set, output is
+// formatted and colored when stdout is a terminal.
+func (c *Client) prettyMode(ctx context.Context) PrettyMode {
+	if c.pretty != nil {
+		return *c.pretty
+	}
+	if stdoutColorCapable(ctx) {
+		return PrettyAll
+	}
+	return PrettyNone
+}
+
 func (c *Client) SetAuth(auth Authenticator) error {
 	c.auth = auth
 	return nil
@@ -803,7 +894,7 @@ func (c *Client) redactHeader(name, value string) string {
 
 func redactHeader(name, s string) string {
 	switch {
-	case strings.EqualFold(name, "authorization"):
+	case strings.EqualFold(name, "authorization"), strings.EqualFold(name, "proxy-authorization"):
 		h, r, _ := strings.Cut(s, " ")
 		switch h {
 		case "Bearer":
@@ -827,7 +918,7 @@ func redactGeneric(s string) string {
 	return s[0:4] + strings.Repeat("*", len(s)-6) + s[len(s)-2:]
 }
 
-func (e *exprHandling) eval(initial, req *http.Request, resp *Response) {
+func (e *exprHandling) eval(initial, req *http.Request, resp *Response, stats *responseStats) {
 	expanders := []expander.Interface{
 		expander.Func(expr.ExpandGlobals),
 		expander.Prefix("color", expander.Colors()),
@@ -836,7 +927,12 @@ func (e *exprHandling) eval(initial, req *http.Request, resp *Response) {
 	}
 
 	if resp == nil {
-		expanders = append(expanders, noResponseExpander, noHeaderExpander)
+		expanders = append(expanders,
+			expandStats(stats),
+			expander.Prefix("tls", ExpandTLS(stats.tlsState())),
+			noResponseExpander,
+			noHeaderExpander,
+		)
 	} else {
 		expanders = append(expanders, ExpandResponse(resp))
 	}
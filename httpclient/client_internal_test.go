@@ -5,6 +5,10 @@
 package httpclient
 
 import (
+	"context"
+	"errors"
+	"time"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 )
@@ -50,4 +54,47 @@ var _ = Describe("Client", func() {
 			),
 		)
 	})
+
+	Describe("requestContext", func() {
+
+		It("is canceled when the context is canceled", func() {
+			ctx, cancel := context.WithCancelCause(context.Background())
+			result, release := requestContext(ctx, context.Background())
+			defer release()
+
+			cause := errors.New("cause")
+			cancel(cause)
+			Eventually(result.Done()).Should(BeClosed())
+			Expect(context.Cause(result)).To(Equal(cause))
+		})
+
+		It("is canceled when the request context is canceled", func() {
+			rctx, cancel := context.WithCancel(context.Background())
+			result, release := requestContext(context.Background(), rctx)
+			defer release()
+
+			cancel()
+			Eventually(result.Done()).Should(BeClosed())
+			Expect(result.Err()).To(MatchError(context.Canceled))
+		})
+
+		It("keeps the deadline of the request context", func() {
+			deadline := time.Now().Add(time.Hour)
+			rctx, cancel := context.WithDeadline(context.Background(), deadline)
+			defer cancel()
+
+			result, release := requestContext(context.Background(), rctx)
+			defer release()
+
+			actual, ok := result.Deadline()
+			Expect(ok).To(BeTrue())
+			Expect(actual).To(Equal(deadline))
+		})
+
+		It("is canceled when released", func() {
+			result, release := requestContext(context.Background(), context.Background())
+			release()
+			Expect(result.Done()).To(BeClosed())
+		})
+	})
 })
@@ -25,6 +25,8 @@ const (
 	networkOptions  = "Network interface options"
 	requestOptions  = "Request options"
 	responseOptions = "Response options"
+	proxyOptions    = "Proxy options"
+	benchOptions    = "Benchmark options"
 )
 
 var (
@@ -44,6 +46,9 @@ func ContextValue(c *Client) cli.Action {
 
 func FetchAndPrint() cli.Action {
 	return cli.ActionOf(func(c context.Context) error {
+		if FromContext(c).benchmark.enabled {
+			return cli.Do(c, BenchAndPrint())
+		}
 		_, err := Do(c)
 		return err
 	})
@@ -70,11 +75,16 @@ func FlagsAndArgs() cli.Action {
 			{Uses: SetNoOutput()},
 			{Uses: SetIntegrity()},
 			{Uses: SetDownload()},
+			{Uses: SetPretty()},
 
 			// DNS options
 			{Uses: SetDNSInterface()},
 			{Uses: SetPreferGo()},
 			{Uses: SetStrictErrorsDNS()},
+			{Uses: SetResolve()},
+			{Uses: SetConnectTo()},
+			{Uses: SetDNSServers()},
+			{Uses: SetDoHURL()},
 
 			{Uses: SetDialKeepAlive()},
 			{Uses: SetDisableDialKeepAlive()},
@@ -82,8 +92,17 @@ func FlagsAndArgs() cli.Action {
 			// Network interface options
 			{Uses: SetBindAddress()},
 			{Uses: SetInterface()},
+			{Uses: SetIPv4()},
+			{Uses: SetIPv6()},
+			{Uses: SetHappyEyeballsTimeout()},
 			{Uses: ListInterfaces()},
 
+			// Proxy options
+			{Uses: SetProxy()},
+			{Uses: SetNoProxy()},
+			{Uses: SetProxyUser()},
+			{Uses: SetProxyHeader()},
+
 			{Uses: SetVerbose()},
 			{Uses: SetTraceLevel()},
 			{Uses: SetRequestID()},
@@ -93,6 +112,13 @@ func FlagsAndArgs() cli.Action {
 			{Uses: SetStripComponents()},
 			{Uses: SetFailFast()},
 
+			// Benchmark options
+			{Uses: SetBench()},
+			{Uses: SetBenchRequests()},
+			{Uses: SetBenchConcurrency()},
+			{Uses: SetBenchDuration()},
+			{Uses: SetBenchFormat()},
+
 			// Auth
 			{Uses: ListAuthenticators()},
 			{Uses: SetUser()},
@@ -302,6 +328,19 @@ func SetIntegrity(i ...Integrity) cli.Action {
 	)
 }
 
+func SetPretty(v ...PrettyMode) cli.Action {
+	return cli.Pipeline(
+		&cli.Prototype{
+			Name:       "pretty",
+			HelpText:   "Controls formatting and syntax highlighting of JSON, XML, and HTML written to stdout.  By default, both are used when stdout is a terminal",
+			Category:   responseOptions,
+			Completion: cli.ValueCompletion("all", "format", "colors", "none"),
+		},
+		withBinding((*Client).SetPretty, v),
+		tagged,
+	)
+}
+
 func SetDownload() cli.Action {
 	return cli.Pipeline(
 		&cli.Prototype{
@@ -555,7 +594,7 @@ func SetWriteOut(w ...Expr) cli.Action {
 	return cli.Pipeline(
 		&cli.Prototype{
 			Name:     "write-out",
-			HelpText: "Evaluate the expression and print out the result",
+			HelpText: "Evaluate the expression and print out the result after the response is downloaded",
 			Aliases:  []string{"w"},
 			Category: requestOptions,
 		},
@@ -568,7 +607,7 @@ func SetWriteErr(w ...Expr) cli.Action {
 	return cli.Pipeline(
 		&cli.Prototype{
 			Name:     "write-err",
-			HelpText: "Evaluate the expression and print out the result to stderr",
+			HelpText: "Evaluate the expression and print out the result to stderr after the response is downloaded",
 			Aliases:  []string{"W"},
 			Category: requestOptions,
 		},
@@ -0,0 +1,353 @@
+// Copyright 2026 The Joe-cli Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package httpclient
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Carbonfrost/joe-cli"
+)
+
+// IPFamily restricts the address family used for DNS lookups and dials
+type IPFamily int
+
+// IP families
+const (
+	IPAny IPFamily = iota
+	IPv4
+	IPv6
+)
+
+// dialOverrides pins host names to addresses (--resolve) and
+// redirects connections to other hosts (--connect-to).  Both are
+// keyed by host:port, where an empty host or port matches any.
+type dialOverrides struct {
+	resolve   map[string][]string
+	connectTo map[string]string
+}
+
+// SetResolve pins a host and port to the given addresses using the syntax
+// host:port:addr[,addr...].  The host can be * to match any host.  The Host
+// header and the TLS server name are not changed.
+func (c *Client) SetResolve(s string) error {
+	fields := splitHostPortFields(s)
+	if len(fields) != 3 {
+		return fmt.Errorf("invalid resolve %q: expected host:port:addr[,addr]", s)
+	}
+	host, port, list := fields[0], fields[1], fields[2]
+	if err := checkPort(port, false); err != nil {
+		return fmt.Errorf("invalid resolve %q: %w", s, err)
+	}
+	if host == "*" {
+		host = ""
+	}
+
+	var addrs []string
+	for addr := range strings.SplitSeq(list, ",") {
+		addr = strings.Trim(strings.TrimSpace(addr), "[]")
+		if net.ParseIP(addr) == nil {
+			return fmt.Errorf("invalid resolve %q: not an IP address %q", s, addr)
+		}
+		addrs = append(addrs, addr)
+	}
+
+	if c.dialOverrides.resolve == nil {
+		c.dialOverrides.resolve = map[string][]string{}
+	}
+	c.dialOverrides.resolve[overrideKey(host, port)] = addrs
+	return nil
+}
+
+// SetConnectTo causes connections to a host and port to be made to another
+// host and port instead using the syntax host:port:host2:port2.  Either
+// host or port can be empty to match any.  The Host header and the TLS
+// server name are not changed.
+func (c *Client) SetConnectTo(s string) error {
+	fields := splitHostPortFields(s)
+	if len(fields) != 4 {
+		return fmt.Errorf("invalid connect-to %q: expected host:port:host2:port2", s)
+	}
+	if err := checkPort(fields[1], true); err != nil {
+		return fmt.Errorf("invalid connect-to %q: %w", s, err)
+	}
+	if err := checkPort(fields[3], true); err != nil {
+		return fmt.Errorf("invalid connect-to %q: %w", s, err)
+	}
+
+	if c.dialOverrides.connectTo == nil {
+		c.dialOverrides.connectTo = map[string]string{}
+	}
+	c.dialOverrides.connectTo[overrideKey(fields[0], fields[1])] = net.JoinHostPort(
+		strings.Trim(fields[2], "[]"), fields[3],
+	)
+	return nil
+}
+
+// SetIPv4 causes only IPv4 addresses to be resolved and dialed
+func (c *Client) SetIPv4(v bool) error {
+	if v {
+		c.ipFamily = IPv4
+	}
+	return nil
+}
+
+// SetIPv6 causes only IPv6 addresses to be resolved and dialed
+func (c *Client) SetIPv6(v bool) error {
+	if v {
+		c.ipFamily = IPv6
+	}
+	return nil
+}
+
+// SetHappyEyeballsTimeout sets how long to wait for an IPv6 connection
+// before falling back to IPv4 when both are available.  A negative value
+// disables fallback.
+func (c *Client) SetHappyEyeballsTimeout(v time.Duration) error {
+	c.Dialer().FallbackDelay = v
+	return nil
+}
+
+// Network gets the network with the family suffix, such as tcp4
+func (f IPFamily) Network(network string) string {
+	switch f {
+	case IPv4:
+		return network + "4"
+	case IPv6:
+		return network + "6"
+	}
+	return network
+}
+
+// String gets the name of the family, IPv4 or IPv6
+func (f IPFamily) String() string {
+	switch f {
+	case IPv4:
+		return "IPv4"
+	case IPv6:
+		return "IPv6"
+	}
+	return ""
+}
+
+func (f IPFamily) matches(ip net.IP) bool {
+	switch f {
+	case IPv4:
+		return ip.To4() != nil
+	case IPv6:
+		return ip.To4() == nil
+	}
+	return true
+}
+
+func ipFamilyOf(addr net.Addr) IPFamily {
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return IPAny
+	}
+	ip := net.ParseIP(host)
+	switch {
+	case ip == nil:
+		return IPAny
+	case ip.To4() != nil:
+		return IPv4
+	default:
+		return IPv6
+	}
+}
+
+func (c *Client) dialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	network = c.ipFamily.Network(network)
+
+	if target, ok := c.dialOverrides.connectToAddr(addr); ok {
+		if l, ok := c.logger.(DialTraceLogger); ok {
+			l.ConnectToOverride(addr, target)
+		}
+		addr = target
+	}
+
+	addrs, ok := c.dialOverrides.resolveAddrs(addr)
+	if !ok {
+		return c.dialer.DialContext(ctx, network, addr)
+	}
+
+	if l, ok := c.logger.(DialTraceLogger); ok {
+		l.ResolveOverride(addr, addrs)
+	}
+	_, port, _ := net.SplitHostPort(addr)
+
+	err := fmt.Errorf("no %s address in resolve override for %s", c.ipFamily, addr)
+	for _, ip := range addrs {
+		if !c.ipFamily.matches(net.ParseIP(ip)) {
+			continue
+		}
+		var conn net.Conn
+		conn, err = c.dialer.DialContext(ctx, network, net.JoinHostPort(ip, port))
+		if err == nil {
+			return conn, nil
+		}
+	}
+	return nil, err
+}
+
+func (d *dialOverrides) connectToAddr(addr string) (string, bool) {
+	if len(d.connectTo) == 0 {
+		return "", false
+	}
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", false
+	}
+
+	for _, key := range candidateOverrideKeys(host, port) {
+		if target, ok := d.connectTo[key]; ok {
+			// Empty host or port in the target means unchanged
+			toHost, toPort, _ := net.SplitHostPort(target)
+			if toHost == "" {
+				toHost = host
+			}
+			if toPort == "" {
+				toPort = port
+			}
+			return net.JoinHostPort(toHost, toPort), true
+		}
+	}
+	return "", false
+}
+
+func (d *dialOverrides) resolveAddrs(addr string) ([]string, bool) {
+	if len(d.resolve) == 0 {
+		return nil, false
+	}
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, false
+	}
+
+	key := overrideKey(host, port)
+	if addrs, ok := d.resolve[key]; ok {
+		return addrs, true
+	}
+	addrs, ok := d.resolve[overrideKey("", port)]
+	return addrs, ok
+}
+
+func candidateOverrideKeys(host, port string) []string {
+	return []string{
+		overrideKey(host, port),
+		overrideKey(host, ""),
+		overrideKey("", port),
+		overrideKey("", ""),
+	}
+}
+
+func overrideKey(host, port string) string {
+	return net.JoinHostPort(strings.ToLower(strings.Trim(host, "[]")), port)
+}
+
+func checkPort(port string, allowEmpty bool) error {
+	if port == "" && allowEmpty {
+		return nil
+	}
+	if p, err := strconv.Atoi(port); err != nil || p < 0 || p > 65535 {
+		return fmt.Errorf("invalid port %q", port)
+	}
+	return nil
+}
+
+// splitHostPortFields splits on colons except those within brackets, which
+// allows IPv6 addresses to be written as [::1]
+func splitHostPortFields(s string) []string {
+	var (
+		res   []string
+		depth int
+		start int
+	)
+	for i, r := range s {
+		switch r {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case ':':
+			if depth == 0 {
+				res = append(res, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(res, s[start:])
+}
+
+func SetResolve(s ...string) cli.Action {
+	return cli.Pipeline(
+		&cli.Prototype{
+			Name:      "resolve",
+			UsageText: "HOST:PORT:ADDR[,ADDR]",
+			HelpText:  "Resolve {HOST} and {PORT} to the specified addresses instead of using DNS",
+			Category:  dnsOptions,
+			Options:   cli.EachOccurrence,
+		},
+		withBinding((*Client).SetResolve, s),
+		tagged,
+	)
+}
+
+func SetConnectTo(s ...string) cli.Action {
+	return cli.Pipeline(
+		&cli.Prototype{
+			Name:      "connect-to",
+			UsageText: "HOST:PORT:HOST2:PORT2",
+			HelpText:  "Connect to {HOST2} and {PORT2} instead of {HOST} and {PORT}",
+			Category:  dnsOptions,
+			Options:   cli.EachOccurrence,
+		},
+		withBinding((*Client).SetConnectTo, s),
+		tagged,
+	)
+}
+
+func SetIPv4() cli.Action {
+	return cli.Pipeline(
+		&cli.Prototype{
+			Name:     "ipv4",
+			Aliases:  []string{"4"},
+			HelpText: "Resolve and connect using only IPv4 addresses",
+			Category: networkOptions,
+		},
+		withBindingTrue((*Client).SetIPv4),
+		tagged,
+	)
+}
+
+func SetIPv6() cli.Action {
+	return cli.Pipeline(
+		&cli.Prototype{
+			Name:     "ipv6",
+			Aliases:  []string{"6"},
+			HelpText: "Resolve and connect using only IPv6 addresses",
+			Category: networkOptions,
+		},
+		withBindingTrue((*Client).SetIPv6),
+		tagged,
+	)
+}
+
+func SetHappyEyeballsTimeout(v ...time.Duration) cli.Action {
+	return cli.Pipeline(
+		&cli.Prototype{
+			Name:      "happy-eyeballs-timeout",
+			UsageText: "DURATION",
+			HelpText:  "How long to wait for IPv6 before also trying IPv4; negative disables fallback",
+			Category:  networkOptions,
+		},
+		withBinding((*Client).SetHappyEyeballsTimeout, v),
+		tagged,
+	)
+}
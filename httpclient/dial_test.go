@@ -0,0 +1,146 @@
+// Copyright 2026 The Joe-cli Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package httpclient_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Dial overrides", func() {
+
+	var (
+		server *httptest.Server
+		port   string
+	)
+
+	newServer := func(factory func(http.Handler) *httptest.Server) {
+		server = factory(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var serverName string
+			if r.TLS != nil {
+				serverName = r.TLS.ServerName
+			}
+			fmt.Fprintf(w, "host=%s sni=%s", r.Host, serverName)
+		}))
+		DeferCleanup(server.Close)
+
+		u, _ := url.Parse(server.URL)
+		port = u.Port()
+	}
+
+	Describe("--resolve", func() {
+
+		It("connects to the pinned address", func() {
+			newServer(httptest.NewServer)
+			out, err := runClient("--resolve", "example.invalid:"+port+":127.0.0.1", "http://example.invalid:"+port+"/")
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out).To(Equal("host=example.invalid:" + port + " sni="))
+		})
+
+		It("tries each address in turn", func() {
+			newServer(httptest.NewServer)
+			out, err := runClient("--resolve", "example.invalid:"+port+":[::ffff:7f00:2],127.0.0.1", "http://example.invalid:"+port+"/")
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out).To(ContainSubstring("host=example.invalid:" + port))
+		})
+
+		It("keeps the TLS server name", func() {
+			newServer(httptest.NewTLSServer)
+			out, err := runClient("-k", "--resolve", "example.com:"+port+":127.0.0.1", "https://example.com:"+port+"/")
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out).To(Equal("host=example.com:" + port + " sni=example.com"))
+		})
+
+		It("supports wildcard host", func() {
+			newServer(httptest.NewServer)
+			out, err := runClient("--resolve", "*:"+port+":127.0.0.1", "http://example.invalid:"+port+"/")
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out).To(Equal("host=example.invalid:" + port + " sni="))
+		})
+	})
+
+	Describe("--connect-to", func() {
+
+		It("connects to the other host and port", func() {
+			newServer(httptest.NewServer)
+			out, err := runClient("--connect-to", "example.invalid:80:127.0.0.1:"+port, "http://example.invalid/")
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out).To(Equal("host=example.invalid sni="))
+		})
+
+		It("keeps the TLS server name", func() {
+			newServer(httptest.NewTLSServer)
+			out, err := runClient("-k", "--connect-to", "::127.0.0.1:"+port, "https://example.com/")
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out).To(Equal("host=example.com sni=example.com"))
+		})
+
+		It("combines with --resolve", func() {
+			newServer(httptest.NewServer)
+			out, err := runClient(
+				"--connect-to", "example.invalid:80:backend.invalid:"+port,
+				"--resolve", "backend.invalid:"+port+":127.0.0.1",
+				"http://example.invalid/",
+			)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out).To(Equal("host=example.invalid sni="))
+		})
+	})
+
+	Describe("IP family", func() {
+
+		It("dials IPv4 with --ipv4", func() {
+			newServer(httptest.NewServer)
+			out, err := runClient("-4", "--write-out", "%(remote.ip) %(remote.port)", "http://127.0.0.1:"+port+"/")
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out).To(Equal("host=127.0.0.1:" + port + " sni=127.0.0.1 " + port))
+		})
+
+		It("doesn't dial IPv4 addresses with --ipv6", func() {
+			newServer(httptest.NewServer)
+			_, err := runClient("--ipv6", "http://127.0.0.1:"+port+"/")
+
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("filters addresses from --resolve", func() {
+			newServer(httptest.NewServer)
+			out, err := runClient("--ipv4", "--resolve", "example.invalid:"+port+":[::1],127.0.0.1", "--write-out", " %(remote.ip)", "http://example.invalid:"+port+"/")
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out).To(HaveSuffix(" 127.0.0.1"))
+		})
+
+		It("reports when no address from --resolve matches", func() {
+			newServer(httptest.NewServer)
+			_, err := runClient("--ipv6", "--resolve", "example.invalid:"+port+":127.0.0.1", "http://example.invalid:"+port+"/")
+
+			Expect(err).To(MatchError(ContainSubstring("no IPv6 address in resolve override")))
+		})
+	})
+
+	DescribeTable("errors", func(flag, value, expected string) {
+		_, err := runClient(flag, value, "http://example.invalid/")
+		Expect(err).To(MatchError(ContainSubstring(expected)))
+	},
+		Entry("resolve missing addresses", "--resolve", "example.invalid:80", "expected host:port:addr[,addr]"),
+		Entry("resolve bad port", "--resolve", "example.invalid:http:127.0.0.1", `invalid port "http"`),
+		Entry("resolve bad address", "--resolve", "example.invalid:80:localhost", `not an IP address "localhost"`),
+		Entry("connect-to missing fields", "--connect-to", "example.invalid:80:127.0.0.1", "expected host:port:host2:port2"),
+	)
+})
@@ -0,0 +1,237 @@
+// Copyright 2026 The Joe-cli Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Carbonfrost/joe-cli"
+)
+
+const dnsMessageContentType = "application/dns-message"
+
+// dnsSettings selects the name servers used by the resolver.  When
+// either is set, Go's built-in resolver is used because it is the only
+// one that dials the name server via the Resolver.Dial hook.
+type dnsSettings struct {
+	servers []string
+	next    atomic.Uint32
+	dohURL  *url.URL
+	dohOnce sync.Once
+	doh     *http.Client
+	dohErr  error
+}
+
+// dohConn implements DNS-over-HTTPS (RFC 8484) as a connection to a
+// name server.  It implements net.PacketConn so that the resolver
+// writes and reads whole DNS messages instead of length-prefixed ones.
+// Each message that is written is sent as a POST; the reply is
+// available to the next read.
+type dohConn struct {
+	ctx    context.Context
+	client *http.Client
+	url    *url.URL
+	reply  bytes.Buffer
+}
+
+// SetDNSServers sets the name servers to use instead of the system
+// configuration.  The value is a comma-separated list of ip[:port].
+// UDP is used, falling back to TCP for truncated replies.
+func (c *Client) SetDNSServers(s string) error {
+	for item := range strings.SplitSeq(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		addr, err := parseDNSServer(item)
+		if err != nil {
+			return err
+		}
+		c.dns.servers = append(c.dns.servers, addr)
+	}
+	c.Dialer().Resolver.PreferGo = true
+	return nil
+}
+
+// SetDoHURL sets the URL of a DNS-over-HTTPS endpoint to use for name
+// resolution.  The URL must use https.
+func (c *Client) SetDoHURL(s string) error {
+	u, err := url.Parse(s)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "https" {
+		return fmt.Errorf("unsupported DoH URL scheme %q", u.Scheme)
+	}
+	c.dns.dohURL = u
+	c.Dialer().Resolver.PreferGo = true
+	return nil
+}
+
+// dialDNS provides the connection to the name server used by the resolver.
+// The address requested by the resolver derives from the system configuration,
+// which is replaced with the name servers that were explicitly set.
+func (c *Client) dialDNS(ctx context.Context, network, address string) (net.Conn, error) {
+	if c.dns.dohURL != nil {
+		client, err := c.dohClient(ctx)
+		if err != nil {
+			return nil, err
+		}
+		c.traceDNSQuery("https", c.dns.dohURL.Redacted())
+		return &dohConn{
+			ctx:    ctx,
+			client: client,
+			url:    c.dns.dohURL,
+		}, nil
+	}
+	if len(c.dns.servers) > 0 {
+		n := c.dns.next.Add(1) - 1
+		address = c.dns.servers[int(n)%len(c.dns.servers)]
+		c.traceDNSQuery(network, address)
+	}
+	return c.dnsDialer.DialContext(ctx, network, address)
+}
+
+func (c *Client) traceDNSQuery(network, server string) {
+	if l, ok := c.logger.(DNSTraceLogger); ok {
+		l.DNSQuery(network, server)
+	}
+}
+
+// dohClient gets the client used to query the DoH server, which is created
+// once because queries for each address family are made concurrently.
+// The DoH server itself is resolved using the system resolver, but the TLS
+// configuration and proxy are the same as for other requests.
+func (c *Client) dohClient(ctx context.Context) (*http.Client, error) {
+	c.dns.dohOnce.Do(func() {
+		tlsConfig, err := c.NewTLSConfig(ctx)
+		if err != nil {
+			c.dns.dohErr = err
+			return
+		}
+
+		t := http.DefaultTransport.(*http.Transport).Clone()
+		t.DialContext = c.dnsDialer.DialContext
+		t.TLSClientConfig = tlsConfig
+		t.Proxy = c.proxyFunc
+		c.dns.doh = &http.Client{Transport: c.setupProxyTransport(ctx, t)}
+	})
+	return c.dns.doh, c.dns.dohErr
+}
+
+func (d *dohConn) Write(b []byte) (int, error) {
+	req, err := http.NewRequestWithContext(d.ctx, "POST", d.url.String(), bytes.NewReader(b))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", dnsMessageContentType)
+	req.Header.Set("Accept", dnsMessageContentType)
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("DoH server returned %s", resp.Status)
+	}
+
+	d.reply.Reset()
+	if _, err := io.Copy(&d.reply, resp.Body); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+func (d *dohConn) Read(b []byte) (int, error) {
+	return d.reply.Read(b)
+}
+
+func (d *dohConn) ReadFrom(b []byte) (int, net.Addr, error) {
+	n, err := d.Read(b)
+	return n, d.RemoteAddr(), err
+}
+
+func (d *dohConn) WriteTo(b []byte, _ net.Addr) (int, error) {
+	return d.Write(b)
+}
+
+func (*dohConn) Close() error {
+	return nil
+}
+
+func (*dohConn) LocalAddr() net.Addr {
+	return dohAddr("")
+}
+
+func (d *dohConn) RemoteAddr() net.Addr {
+	return dohAddr(d.url.Host)
+}
+
+// Deadlines are handled by the context of the request
+func (*dohConn) SetDeadline(time.Time) error      { return nil }
+func (*dohConn) SetReadDeadline(time.Time) error  { return nil }
+func (*dohConn) SetWriteDeadline(time.Time) error { return nil }
+
+type dohAddr string
+
+func (dohAddr) Network() string  { return "https" }
+func (a dohAddr) String() string { return string(a) }
+
+func parseDNSServer(s string) (string, error) {
+	if ip := net.ParseIP(strings.Trim(s, "[]")); ip != nil {
+		return net.JoinHostPort(ip.String(), "53"), nil
+	}
+	host, port, err := net.SplitHostPort(s)
+	if err != nil || net.ParseIP(host) == nil {
+		return "", fmt.Errorf("invalid DNS server %q: expected ip[:port]", s)
+	}
+	if err := checkPort(port, false); err != nil {
+		return "", fmt.Errorf("invalid DNS server %q: %w", s, err)
+	}
+	return net.JoinHostPort(host, port), nil
+}
+
+func SetDNSServers(s ...string) cli.Action {
+	return cli.Pipeline(
+		&cli.Prototype{
+			Name:      "dns-servers",
+			UsageText: "IP[:PORT],...",
+			HelpText:  "Use the specified DNS servers instead of the system configuration",
+			Category:  dnsOptions,
+			Options:   cli.EachOccurrence,
+		},
+		withBinding((*Client).SetDNSServers, s),
+		tagged,
+	)
+}
+
+func SetDoHURL(s ...string) cli.Action {
+	return cli.Pipeline(
+		&cli.Prototype{
+			Name:      "doh-url",
+			UsageText: "URL",
+			HelpText:  "Resolve host names using the specified DNS-over-HTTPS server",
+			Category:  dnsOptions,
+		},
+		withBinding((*Client).SetDoHURL, s),
+		tagged,
+	)
+}
+
+var (
+	_ net.PacketConn = (*dohConn)(nil)
+	_ net.Conn       = (*dohConn)(nil)
+)
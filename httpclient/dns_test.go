@@ -0,0 +1,155 @@
+// Copyright 2026 The Joe-cli Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package httpclient_test
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+
+	"golang.org/x/net/dns/dnsmessage"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// stubDNS answers every A query with 127.0.0.1 and records the names
+// which were queried
+type stubDNS struct {
+	mu      sync.Mutex
+	queries []string
+}
+
+func (s *stubDNS) reply(msg []byte) []byte {
+	var p dnsmessage.Parser
+	h, err := p.Start(msg)
+	if err != nil {
+		return nil
+	}
+	q, err := p.Question()
+	if err != nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	s.queries = append(s.queries, q.Name.String())
+	s.mu.Unlock()
+
+	b := dnsmessage.NewBuilder(nil, dnsmessage.Header{
+		ID:                 h.ID,
+		Response:           true,
+		Authoritative:      true,
+		RecursionAvailable: true,
+	})
+	b.EnableCompression()
+	_ = b.StartQuestions()
+	_ = b.Question(q)
+	_ = b.StartAnswers()
+	if q.Type == dnsmessage.TypeA {
+		_ = b.AResource(
+			dnsmessage.ResourceHeader{Name: q.Name, Class: dnsmessage.ClassINET, TTL: 60},
+			dnsmessage.AResource{A: [4]byte{127, 0, 0, 1}},
+		)
+	}
+	res, _ := b.Finish()
+	return res
+}
+
+func (s *stubDNS) queried() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.queries...)
+}
+
+func (s *stubDNS) listenUDP() string {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	Expect(err).NotTo(HaveOccurred())
+	DeferCleanup(conn.Close)
+
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			_, _ = conn.WriteTo(s.reply(buf[:n]), addr)
+		}
+	}()
+	return conn.LocalAddr().String()
+}
+
+func (s *stubDNS) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" || r.Header.Get("Content-Type") != "application/dns-message" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	msg, _ := io.ReadAll(r.Body)
+	w.Header().Set("Content-Type", "application/dns-message")
+	_, _ = w.Write(s.reply(msg))
+}
+
+var _ = Describe("DNS servers", func() {
+
+	var (
+		dns  *stubDNS
+		port string
+	)
+
+	BeforeEach(func() {
+		dns = &stubDNS{}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, "host=%s", r.Host)
+		}))
+		DeferCleanup(server.Close)
+
+		u, _ := url.Parse(server.URL)
+		port = u.Port()
+	})
+
+	It("resolves using the specified DNS server", func() {
+		addr := dns.listenUDP()
+		out, err := runClient("--dns-servers", addr, "http://backend.example:"+port+"/")
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(Equal("host=backend.example:" + port))
+		Expect(dns.queried()).To(ContainElement("backend.example."))
+	})
+
+	It("resolves using DNS-over-HTTPS", func() {
+		doh := httptest.NewTLSServer(dns)
+		DeferCleanup(doh.Close)
+
+		out, err := runClient("-k", "--doh-url", doh.URL+"/dns-query", "http://backend.example:"+port+"/")
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(Equal("host=backend.example:" + port))
+		Expect(dns.queried()).To(ContainElement("backend.example."))
+	})
+
+	It("uses the TLS configuration of the client for DNS-over-HTTPS", func() {
+		doh := httptest.NewTLSServer(dns)
+		DeferCleanup(doh.Close)
+
+		_, err := runClient("--doh-url", doh.URL+"/dns-query", "http://backend.example:"+port+"/")
+
+		Expect(err).To(MatchError(ContainSubstring("certificate")))
+		Expect(dns.queried()).To(BeEmpty())
+	})
+
+	DescribeTable("errors", func(flag, value, expected string) {
+		_, err := runClient(flag, value, "http://backend.example/")
+		Expect(err).To(MatchError(ContainSubstring(expected)))
+	},
+		Entry("DNS server not IP", "--dns-servers", "dns.example", `invalid DNS server "dns.example"`),
+		Entry("DNS server bad port", "--dns-servers", "127.0.0.1:dns", `invalid port "dns"`),
+		Entry("DoH URL scheme", "--doh-url", "ftp://dns.example", `unsupported DoH URL scheme "ftp"`),
+		Entry("DoH URL without TLS", "--doh-url", "http://dns.example", `unsupported DoH URL scheme "http"`),
+	)
+})
@@ -6,10 +6,17 @@ package httpclient
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding"
+	"encoding/hex"
+	"math"
 	"net/http"
 	"regexp"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/Carbonfrost/joe-cli/extensions/expr/expander"
 )
@@ -49,7 +56,72 @@ func ExpandResponse(r *Response) expander.Interface {
 			return buf.String()
 		}
 		return nil
-	}), expander.Prefix("header", ExpandHeader(r.Header)))
+	}), expandStats(r.stats()), expander.Prefix("tls", ExpandTLS(r.TLS)), expander.Prefix("header", ExpandHeader(r.Header)))
+}
+
+// ExpandTLS provides the TLS connection state and the peer certificate.
+// When state is nil, as for plain HTTP, all values are empty.
+func ExpandTLS(state *tls.ConnectionState) expander.Interface {
+	return expander.Func(func(s string) any {
+		if state == nil {
+			if slices.Contains(tlsExpanderNames, s) {
+				return ""
+			}
+			return nil
+		}
+
+		switch s {
+		case "version":
+			return tls.VersionName(state.Version)
+		case "cipher":
+			return tls.CipherSuiteName(state.CipherSuite)
+		case "alpn":
+			return state.NegotiatedProtocol
+		case "resumed":
+			return state.DidResume
+		}
+
+		if !strings.HasPrefix(s, "peer.") {
+			return nil
+		}
+		var cert *x509.Certificate
+		if len(state.PeerCertificates) > 0 {
+			cert = state.PeerCertificates[0]
+		}
+		if cert == nil {
+			if slices.Contains(tlsExpanderNames, s) {
+				return ""
+			}
+			return nil
+		}
+
+		switch s {
+		case "peer.subject":
+			return cert.Subject.String()
+		case "peer.issuer":
+			return cert.Issuer.String()
+		case "peer.notAfter":
+			return cert.NotAfter.UTC().Format(time.RFC3339)
+		case "peer.daysRemaining":
+			return int(math.Floor(time.Until(cert.NotAfter).Hours() / 24))
+		case "peer.sha256":
+			sum := sha256.Sum256(cert.Raw)
+			return hex.EncodeToString(sum[:])
+		}
+		return nil
+	})
+}
+
+var tlsExpanderNames = []string{
+	"version",
+	"cipher",
+	"alpn",
+	"resumed",
+	"peer.subject",
+	"peer.issuer",
+	"peer.notAfter",
+	"peer.daysRemaining",
+	"peer.sha256",
 }
 
 func ExpandHeader(h http.Header) expander.Interface {
@@ -7,8 +7,13 @@ package httpclient_test
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io"
 	"net/http"
+	"net/http/httptest"
+	"strings"
 
 	"github.com/Carbonfrost/joe-cli"
 	"github.com/Carbonfrost/joe-cli-http/httpclient"
@@ -134,3 +139,52 @@ var _ = Describe("Expr", func() {
 
 	})
 })
+
+var _ = Describe("ExpandTLS", func() {
+
+	writeOut := func(expr string, args ...string) string {
+		var out bytes.Buffer
+		app := &cli.App{
+			Uses:   httpclient.New(),
+			Action: httpclient.FetchAndPrint(),
+			Stdout: &out,
+			Stderr: io.Discard,
+		}
+
+		args = append([]string{"_", "-k", "--output", "/dev/null", "--write-out", expr}, args...)
+		err := app.RunContext(context.Background(), args)
+		Expect(err).NotTo(HaveOccurred())
+		return out.String()
+	}
+
+	It("provides the TLS connection state", func() {
+		server := httptest.NewTLSServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+		DeferCleanup(server.Close)
+
+		cert := server.Certificate()
+		sum := sha256.Sum256(cert.Raw)
+		fields := strings.Split(writeOut(
+			"%(tls.version)|%(tls.cipher)|%(tls.alpn)|%(tls.resumed)|%(tls.peer.subject)|%(tls.peer.issuer)|%(tls.peer.notAfter)|%(tls.peer.daysRemaining)|%(tls.peer.sha256)",
+			server.URL,
+		), "|")
+
+		Expect(fields).To(HaveExactElements(
+			"TLS 1.3",
+			HavePrefix("TLS_"),
+			"http/1.1",
+			"false",
+			cert.Subject.String(),
+			cert.Issuer.String(),
+			cert.NotAfter.UTC().Format("2006-01-02T15:04:05Z07:00"),
+			MatchRegexp(`^\d+$`),
+			hex.EncodeToString(sum[:]),
+		))
+	})
+
+	It("is empty for plain HTTP", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+		DeferCleanup(server.Close)
+
+		Expect(writeOut("%(tls.version)%(tls.peer.subject)%(tls.peer.sha256)", server.URL)).To(BeEmpty())
+	})
+})
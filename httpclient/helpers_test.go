@@ -0,0 +1,37 @@
+// Copyright 2026 The Joe-cli Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package httpclient_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+
+	"github.com/Carbonfrost/joe-cli"
+	"github.com/Carbonfrost/joe-cli-http/httpclient"
+)
+
+// runClient runs a new client with the arguments and returns what it wrote
+// to stdout
+func runClient(args ...string) (string, error) {
+	return runClientWith(httpclient.New(), args...)
+}
+
+// runClientWith runs the client with the arguments and returns what it wrote
+// to stdout.  Idle connections are closed afterwards so that connections to
+// test servers don't outlive the app.
+func runClientWith(client *httpclient.Client, args ...string) (string, error) {
+	var out bytes.Buffer
+	app := &cli.App{
+		Uses:   client,
+		Action: httpclient.FetchAndPrint(),
+		Stdout: &out,
+		Stderr: io.Discard,
+	}
+	defer client.CloseIdleConnections()
+
+	err := app.RunContext(context.Background(), append([]string{"_"}, args...))
+	return out.String(), err
+}
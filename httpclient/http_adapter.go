@@ -6,6 +6,7 @@ package httpclient
 
 import (
 	"io"
+	"net"
 	"net/http"
 )
 
@@ -13,6 +14,30 @@ type Response struct {
 	*http.Response
 }
 
+// RemoteAddr gets the address of the server connection that produced the
+// response, or nil if unknown
+func (r *Response) RemoteAddr() net.Addr {
+	return r.stats().RemoteAddr()
+}
+
+// Timings gets the duration of each phase of the request that produced the
+// response
+func (r *Response) Timings() Timings {
+	return r.stats().Timings()
+}
+
+// stats gets the statistics of the response, which are recorded in the
+// context of its request
+func (r *Response) stats() *responseStats {
+	if r.Response == nil || r.Request == nil {
+		return nil
+	}
+	if rec := statsRecorderFromContext(r.Request.Context()); rec != nil {
+		return rec.hop()
+	}
+	return nil
+}
+
 func (r *Response) Success() bool {
 	return r.Response.StatusCode < 400
 }
@@ -14,6 +14,7 @@ import (
 	"net/http"
 	"net/http/httptrace"
 	"net/textproto"
+	"net/url"
 	"strconv"
 	"strings"
 	"text/template"
@@ -48,6 +49,26 @@ type TraceLogger interface {
 	Redirected(req *http.Request, via []*http.Request, err error)
 }
 
+// ProxyTraceLogger can be implemented by a TraceLogger to trace the CONNECT
+// requests sent to proxies
+type ProxyTraceLogger interface {
+	ProxyConnectStart(proxyURL *url.URL, target string)
+	ProxyConnectDone(proxyURL *url.URL, resp *http.Response)
+}
+
+// DialTraceLogger can be implemented by a TraceLogger to trace when addresses
+// are overridden by --resolve or --connect-to
+type DialTraceLogger interface {
+	ResolveOverride(hostPort string, addrs []string)
+	ConnectToOverride(hostPort, target string)
+}
+
+// DNSTraceLogger can be implemented by a TraceLogger to trace the name servers
+// which are queried when they were set explicitly
+type DNSTraceLogger interface {
+	DNSQuery(network, server string)
+}
+
 type nopTraceLogger struct{}
 
 type defaultTraceLogger struct {
@@ -155,6 +176,14 @@ const (
 {{ Gray }}* Connecting to {{ .HostPort | Blue }}{{ ResetColor }}...
 {{ end -}}
 
+{{- define "ProxyConnectStart" -}}
+{{ Gray }}* Establishing tunnel to {{ .Target | Blue }}{{ Gray }} via proxy {{ .Proxy | Blue }}{{ ResetColor }}...
+{{ end -}}
+
+{{- define "ProxyConnectDone" -}}
+{{ Gray }}* Proxy replied {{ .Status }} to CONNECT request{{ ResetColor }}
+{{ end -}}
+
 {{- define "DNSStart" -}}
 {{ Gray }}* Resolving name {{ .Host | Blue }}{{ResetColor}}...
 {{ end -}}
@@ -176,7 +205,7 @@ const (
 {{ end -}}
 
 {{- define "GotConn" -}}
-{{ Gray }}* Connected to {{ .Remote }} ({{ .LocalAddr }}{{ if .Reused }}, reused{{ end }}){{ResetColor}}
+{{ Gray }}* Connected to {{ .Remote }} ({{ if .Family }}{{ .Family }}, {{ end }}{{ .LocalAddr }}{{ if .Reused }}, reused{{ end }}){{ResetColor}}
 {{ end -}}
 
 {{- define "TLSHandshakeDone" -}}
@@ -189,6 +218,18 @@ const (
 {{- ResetColor -}}
 {{ end -}}
 
+{{- define "ResolveOverride" -}}
+{{ Gray }}* Resolved {{ .HostPort | Blue }}{{ Gray }} to {{ .Addrs | Join ", " }} (override){{ResetColor}}
+{{ end -}}
+
+{{- define "ConnectToOverride" -}}
+{{ Gray }}* Connecting to {{ .Target | Blue }}{{ Gray }} instead of {{ .HostPort | Blue }}{{ Gray }} (override){{ResetColor}}
+{{ end -}}
+
+{{- define "DNSQuery" -}}
+{{ Gray }}* Querying DNS server {{ .Server | Blue }}{{ Gray }} ({{ .Network }}){{ResetColor}}
+{{ end -}}
+
 {{- define "DNSDone" -}}
 {{ Gray }}* Resolved to {{ .Addrs | Join ", " }}{{ResetColor}}
 {{ end -}}
@@ -358,6 +399,45 @@ func (l *defaultTraceLogger) DNSStart(info httptrace.DNSStartInfo) {
 	})
 }
 
+func (l *defaultTraceLogger) ResolveOverride(hostPort string, addrs []string) {
+	if !l.flags.dns() {
+		return
+	}
+	l.render("ResolveOverride", struct {
+		HostPort string
+		Addrs    []string
+	}{
+		HostPort: hostPort,
+		Addrs:    addrs,
+	})
+}
+
+func (l *defaultTraceLogger) ConnectToOverride(hostPort, target string) {
+	if !l.flags.dns() {
+		return
+	}
+	l.render("ConnectToOverride", struct {
+		HostPort string
+		Target   string
+	}{
+		HostPort: hostPort,
+		Target:   target,
+	})
+}
+
+func (l *defaultTraceLogger) DNSQuery(network, server string) {
+	if !l.flags.dns() {
+		return
+	}
+	l.render("DNSQuery", struct {
+		Network string
+		Server  string
+	}{
+		Network: network,
+		Server:  server,
+	})
+}
+
 func (l *defaultTraceLogger) GetConn(hostPort string) {
 	if !l.flags.connections() {
 		return
@@ -392,10 +472,12 @@ func (l *defaultTraceLogger) GotConn(info httptrace.GotConnInfo) {
 
 	l.render("GotConn", struct {
 		Remote    string
+		Family    string
 		LocalAddr string
 		Reused    bool
 	}{
 		Remote:    info.Conn.RemoteAddr().String(),
+		Family:    ipFamilyOf(info.Conn.RemoteAddr()).String(),
 		LocalAddr: info.Conn.LocalAddr().String(),
 		Reused:    info.Reused,
 	})
@@ -570,6 +652,34 @@ func (l *defaultTraceLogger) ResponseDone(resp *http.Response, err error) {
 	}
 }
 
+func (l *defaultTraceLogger) ProxyConnectStart(proxyURL *url.URL, target string) {
+	if !l.flags.connections() {
+		return
+	}
+
+	l.render("ProxyConnectStart", struct {
+		Proxy  string
+		Target string
+	}{
+		Proxy:  proxyURL.Redacted(),
+		Target: target,
+	})
+}
+
+func (l *defaultTraceLogger) ProxyConnectDone(proxyURL *url.URL, resp *http.Response) {
+	if !l.flags.connections() {
+		return
+	}
+
+	l.render("ProxyConnectDone", struct {
+		Proxy  string
+		Status string
+	}{
+		Proxy:  proxyURL.Redacted(),
+		Status: resp.Status,
+	})
+}
+
 func (nopTraceLogger) ConnectDone(_, _ string, _ error) {
 }
 
@@ -628,6 +738,10 @@ func (t *traceableTransport) RoundTrip(req *http.Request) (*http.Response, error
 	return rsp, err
 }
 
+func (t *traceableTransport) CloseIdleConnections() {
+	closeIdleConnections(t.Transport)
+}
+
 func indexTraceString(j string) int {
 	for i, s := range traceString {
 		if s == j {
@@ -648,5 +762,8 @@ func traceTemplate(ctx context.Context) *template.Template {
 }
 
 var (
-	_ flag.Value = (*TraceLevel)(nil)
+	_ flag.Value       = (*TraceLevel)(nil)
+	_ ProxyTraceLogger = (*defaultTraceLogger)(nil)
+	_ DialTraceLogger  = (*defaultTraceLogger)(nil)
+	_ DNSTraceLogger   = (*defaultTraceLogger)(nil)
 )
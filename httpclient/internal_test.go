@@ -6,6 +6,7 @@ package httpclient // intentional
 
 import (
 	"bytes"
+	"context"
 	"io"
 	"net"
 	"net/http"
@@ -86,3 +87,13 @@ func newRequestAttributes(r *http.Request) *RequestAttributes {
 		Header:     r.Header,
 	}
 }
+
+// WithTraceOutput redirects trace output, which is otherwise written to stderr
+func WithTraceOutput(w io.Writer) Option {
+	return WithTransportMiddleware(func(_ context.Context, t http.RoundTripper) http.RoundTripper {
+		if l, ok := t.(*traceableTransport).logger.(*defaultTraceLogger); ok {
+			l.out = w
+		}
+		return t
+	})
+}
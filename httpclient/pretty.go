@@ -0,0 +1,569 @@
+// Copyright 2026 The Joe-cli Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"mime"
+	"sort"
+	"strings"
+
+	"github.com/Carbonfrost/joe-cli"
+	"github.com/Carbonfrost/joe-cli/extensions/expr/expander"
+)
+
+// PrettyMode controls how response bodies written to stdout are
+// formatted and colored
+type PrettyMode int
+
+// Pretty modes.  PrettyFormat reformats structured response bodies
+// (JSON, XML, and HTML), PrettyColors applies syntax highlighting when
+// stdout is a terminal, and PrettyAll combines them.
+const (
+	PrettyNone   PrettyMode = 0
+	PrettyFormat PrettyMode = 1
+	PrettyColors PrettyMode = 2
+	PrettyAll    PrettyMode = PrettyFormat | PrettyColors
+)
+
+type syntaxKind int
+
+const (
+	syntaxNone syntaxKind = iota
+	syntaxJSON
+	syntaxXML
+	syntaxHTML
+)
+
+type prettyDownloader struct {
+	Downloader
+	mode PrettyMode
+}
+
+// prettyWriter formats the response body according to its syntax.  The
+// underlying formatter is chosen when the download is opened.
+type prettyWriter struct {
+	io.WriteCloser
+	output io.WriteCloser
+	colors bool
+}
+
+type jsonFormatter struct {
+	out       io.Writer
+	indent    bool
+	colors    bool
+	stack     []byte
+	inString  bool
+	escape    bool
+	inLiteral bool
+	expectKey bool
+	pending   bool
+	topDone   bool
+	last      byte
+	buf       []byte
+}
+
+type markupFormatter struct {
+	out    io.Writer
+	kind   syntaxKind
+	indent bool
+	colors bool
+	buf    bytes.Buffer
+}
+
+var (
+	prettyStrings = map[PrettyMode]string{
+		PrettyNone:   "none",
+		PrettyFormat: "format",
+		PrettyColors: "colors",
+		PrettyAll:    "all",
+	}
+
+	// Design: blue for names and keys, green for string values, magenta for
+	// other literals, consistent with trace output
+	prettyPalette = struct {
+		key, str, literal, punct, comment, reset string
+	}{
+		key:     colorCode("blue"),
+		str:     colorCode("green"),
+		literal: colorCode("magenta"),
+		punct:   colorCode("gray"),
+		comment: colorCode("darkGray"),
+		reset:   colorCode("reset"),
+	}
+)
+
+// NewPrettyDownloader provides a downloader that reformats and colors the
+// response body based upon its Content-Type.  Colors are only used when
+// stdout of the context is capable of color.  Only responses containing
+// JSON, XML, or HTML are affected; other responses are copied as-is.
+func NewPrettyDownloader(mode PrettyMode, d Downloader) Downloader {
+	if mode == PrettyNone {
+		return d
+	}
+	return &prettyDownloader{
+		Downloader: d,
+		mode:       mode,
+	}
+}
+
+// NewPrettyDownloaderMiddleware provides middleware which formats the
+// response body
+func NewPrettyDownloaderMiddleware(mode PrettyMode) DownloaderMiddleware {
+	return func(_ context.Context, d Downloader) Downloader {
+		return NewPrettyDownloader(mode, d)
+	}
+}
+
+func (p *prettyDownloader) OpenDownload(ctx context.Context, resp *Response) (io.WriteCloser, error) {
+	output, err := p.Downloader.OpenDownload(ctx, resp)
+	if err != nil {
+		return nil, err
+	}
+
+	colors := p.mode&PrettyColors == PrettyColors && stdoutColorCapable(ctx)
+	indent := p.mode&PrettyFormat == PrettyFormat
+	if !colors && !indent {
+		return output, nil
+	}
+
+	var body io.WriteCloser
+	switch kind := detectSyntax(resp); kind {
+	case syntaxJSON:
+		body = &jsonFormatter{out: output, indent: indent, colors: colors}
+	case syntaxXML, syntaxHTML:
+		body = &markupFormatter{out: output, kind: kind, indent: indent, colors: colors}
+	default:
+		body = nopWriteCloser{output}
+	}
+	return &prettyWriter{
+		WriteCloser: body,
+		output:      output,
+		colors:      colors,
+	}, nil
+}
+
+// WriteHeaders writes the response headers, which are colored when
+// colors are enabled.  As in HTTP, each line ends with CRLF.
+func (w *prettyWriter) WriteHeaders(resp *Response) error {
+	if !w.colors {
+		if err := resp.CopyHeadersTo(w.output); err != nil {
+			return err
+		}
+		_, err := io.WriteString(w.output, "\r\n")
+		return err
+	}
+
+	keys := make([]string, 0, len(resp.Header))
+	for k := range resp.Header {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var buf bytes.Buffer
+	for _, k := range keys {
+		for _, v := range resp.Header[k] {
+			fmt.Fprintf(&buf, "%s%s%s: %s\r\n", prettyPalette.key, k, prettyPalette.reset, v)
+		}
+	}
+	buf.WriteString("\r\n")
+	_, err := w.output.Write(buf.Bytes())
+	return err
+}
+
+func (w *prettyWriter) Close() error {
+	return errors.Join(w.WriteCloser.Close(), w.output.Close())
+}
+
+func (j *jsonFormatter) Write(p []byte) (int, error) {
+	j.buf = j.buf[:0]
+	for _, c := range p {
+		j.writeByte(c)
+	}
+	if len(j.buf) == 0 {
+		return len(p), nil
+	}
+	j.last = j.buf[len(j.buf)-1]
+	if _, err := j.out.Write(j.buf); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (j *jsonFormatter) writeByte(c byte) {
+	if j.inString {
+		j.buf = append(j.buf, c)
+		switch {
+		case j.escape:
+			j.escape = false
+		case c == '\\':
+			j.escape = true
+		case c == '"':
+			j.inString = false
+			j.color(prettyPalette.reset)
+			j.valueDone()
+		}
+		return
+	}
+
+	if j.inLiteral {
+		if !isJSONDelim(c) {
+			j.buf = append(j.buf, c)
+			return
+		}
+		j.inLiteral = false
+		j.color(prettyPalette.reset)
+		j.valueDone()
+	}
+
+	switch c {
+	case ' ', '\t', '\r', '\n':
+		if !j.indent {
+			j.buf = append(j.buf, c)
+		}
+
+	case '{', '[':
+		j.beginValue()
+		j.punct(c)
+		j.stack = append(j.stack, c)
+		j.expectKey = c == '{'
+		j.pending = j.indent
+
+	case '}', ']':
+		if len(j.stack) > 0 {
+			j.stack = j.stack[:len(j.stack)-1]
+		}
+		if j.pending {
+			j.pending = false
+		} else {
+			j.newline()
+		}
+		j.punct(c)
+		j.valueDone()
+
+	case ',':
+		j.punct(c)
+		j.newline()
+		j.expectKey = len(j.stack) > 0 && j.stack[len(j.stack)-1] == '{'
+
+	case ':':
+		j.punct(c)
+		if j.indent {
+			j.buf = append(j.buf, ' ')
+		}
+		j.expectKey = false
+
+	case '"':
+		j.beginValue()
+		if j.expectKey {
+			j.color(prettyPalette.key)
+		} else {
+			j.color(prettyPalette.str)
+		}
+		j.buf = append(j.buf, c)
+		j.inString = true
+
+	default:
+		j.beginValue()
+		j.color(prettyPalette.literal)
+		j.buf = append(j.buf, c)
+		j.inLiteral = true
+	}
+}
+
+func (j *jsonFormatter) Close() error {
+	j.buf = j.buf[:0]
+	if j.inLiteral {
+		j.inLiteral = false
+		j.color(prettyPalette.reset)
+	}
+	if j.indent && j.last != 0 && j.last != '\n' {
+		j.buf = append(j.buf, '\n')
+	}
+	if len(j.buf) == 0 {
+		return nil
+	}
+	_, err := j.out.Write(j.buf)
+	return err
+}
+
+// beginValue is called at the start of any value so that the deferred
+// newline after an open brace is written and so that top-level values
+// in a stream (such as NDJSON) are separated
+func (j *jsonFormatter) beginValue() {
+	if j.pending {
+		j.pending = false
+		j.newline()
+	}
+	if j.indent && j.topDone && len(j.stack) == 0 {
+		j.buf = append(j.buf, '\n')
+	}
+	j.topDone = false
+}
+
+func (j *jsonFormatter) valueDone() {
+	if len(j.stack) == 0 {
+		j.topDone = true
+	}
+}
+
+func (j *jsonFormatter) newline() {
+	if !j.indent {
+		return
+	}
+	j.buf = append(j.buf, '\n')
+	for range j.stack {
+		j.buf = append(j.buf, "    "...)
+	}
+}
+
+func (j *jsonFormatter) punct(c byte) {
+	j.color(prettyPalette.punct)
+	j.buf = append(j.buf, c)
+	j.color(prettyPalette.reset)
+}
+
+func (j *jsonFormatter) color(code string) {
+	if j.colors {
+		j.buf = append(j.buf, code...)
+	}
+}
+
+func isJSONDelim(c byte) bool {
+	switch c {
+	case ' ', '\t', '\r', '\n', ',', ':', '{', '}', '[', ']', '"':
+		return true
+	}
+	return false
+}
+
+// Write buffers markup, which is formatted when the writer is closed.
+// Unlike JSON, markup is not formatted in a streaming manner.
+func (m *markupFormatter) Write(p []byte) (int, error) {
+	return m.buf.Write(p)
+}
+
+func (m *markupFormatter) Close() error {
+	var out bytes.Buffer
+	if err := m.format(&out); err != nil {
+		// Not well-formed, so the original is written unchanged
+		_, err = m.out.Write(m.buf.Bytes())
+		return err
+	}
+	_, err := m.out.Write(out.Bytes())
+	return err
+}
+
+func (m *markupFormatter) format(out *bytes.Buffer) error {
+	if m.kind == syntaxHTML {
+		m.formatHTML(out)
+		return nil
+	}
+	dec := xml.NewDecoder(bytes.NewReader(m.buf.Bytes()))
+
+	var (
+		depth   int
+		tokens  []xml.Token
+		lineEnd = func() {
+			if m.indent && out.Len() > 0 {
+				out.WriteByte('\n')
+			}
+		}
+		indent = func() {
+			if m.indent {
+				out.WriteString(strings.Repeat("  ", depth))
+			}
+		}
+	)
+
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		tokens = append(tokens, xml.CopyToken(tok))
+	}
+
+	for i := 0; i < len(tokens); i++ {
+		switch t := tokens[i].(type) {
+		case xml.StartElement:
+			lineEnd()
+			indent()
+
+			// Empty elements are self-closing
+			if i+1 < len(tokens) {
+				if _, ok := tokens[i+1].(xml.EndElement); ok {
+					m.writeStart(out, t, "/>")
+					i++
+					continue
+				}
+			}
+			m.writeStart(out, t, ">")
+
+			// Elements which only contain text are written on one line
+			if i+2 < len(tokens) {
+				if text, ok := tokens[i+1].(xml.CharData); ok {
+					if _, ok := tokens[i+2].(xml.EndElement); ok {
+						m.writeText(out, text, false)
+						m.writeEnd(out, tokens[i+2].(xml.EndElement))
+						i += 2
+						continue
+					}
+				}
+			}
+			depth++
+
+		case xml.EndElement:
+			depth = max(depth-1, 0)
+			lineEnd()
+			indent()
+			m.writeEnd(out, t)
+
+		case xml.CharData:
+			if m.indent && len(bytes.TrimSpace(t)) == 0 {
+				continue
+			}
+			lineEnd()
+			indent()
+			m.writeText(out, t, m.indent)
+
+		case xml.Comment:
+			lineEnd()
+			indent()
+			m.color(out, prettyPalette.comment)
+			fmt.Fprintf(out, "<!--%s-->", t)
+			m.color(out, prettyPalette.reset)
+
+		case xml.ProcInst:
+			lineEnd()
+			indent()
+			m.color(out, prettyPalette.punct)
+			fmt.Fprintf(out, "<?%s %s?>", t.Target, t.Inst)
+			m.color(out, prettyPalette.reset)
+
+		case xml.Directive:
+			lineEnd()
+			indent()
+			m.color(out, prettyPalette.punct)
+			fmt.Fprintf(out, "<!%s>", t)
+			m.color(out, prettyPalette.reset)
+		}
+	}
+	if m.indent {
+		out.WriteByte('\n')
+	}
+	return nil
+}
+
+func (m *markupFormatter) writeStart(out *bytes.Buffer, t xml.StartElement, closing string) {
+	m.color(out, prettyPalette.key)
+	out.WriteString("<" + qualifiedName(t.Name))
+	m.color(out, prettyPalette.reset)
+	for _, a := range t.Attr {
+		out.WriteByte(' ')
+		m.color(out, prettyPalette.literal)
+		out.WriteString(qualifiedName(a.Name))
+		m.color(out, prettyPalette.reset)
+		out.WriteByte('=')
+		m.color(out, prettyPalette.str)
+		out.WriteByte('"')
+		xml.EscapeText(out, []byte(a.Value))
+		out.WriteByte('"')
+		m.color(out, prettyPalette.reset)
+	}
+	m.color(out, prettyPalette.key)
+	out.WriteString(closing)
+	m.color(out, prettyPalette.reset)
+}
+
+func (m *markupFormatter) writeEnd(out *bytes.Buffer, t xml.EndElement) {
+	m.color(out, prettyPalette.key)
+	out.WriteString("</" + qualifiedName(t.Name) + ">")
+	m.color(out, prettyPalette.reset)
+}
+
+func (m *markupFormatter) writeText(out *bytes.Buffer, t xml.CharData, trim bool) {
+	if trim {
+		t = bytes.TrimSpace(t)
+	}
+	xml.EscapeText(out, t)
+}
+
+func (m *markupFormatter) color(out *bytes.Buffer, code string) {
+	if m.colors {
+		out.WriteString(code)
+	}
+}
+
+func qualifiedName(n xml.Name) string {
+	if n.Space == "" {
+		return n.Local
+	}
+	return n.Space + ":" + n.Local
+}
+
+func detectSyntax(resp *Response) syntaxKind {
+	if resp == nil || resp.Response == nil {
+		return syntaxNone
+	}
+	mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil {
+		return syntaxNone
+	}
+	switch {
+	case mediaType == "application/json",
+		mediaType == "text/json",
+		strings.HasSuffix(mediaType, "+json"):
+		return syntaxJSON
+	case mediaType == "text/html":
+		return syntaxHTML
+	case mediaType == "application/xml",
+		mediaType == "text/xml",
+		strings.HasSuffix(mediaType, "+xml"):
+		return syntaxXML
+	}
+	return syntaxNone
+}
+
+func stdoutColorCapable(ctx context.Context) bool {
+	c, ok := cli.TryFromContext(ctx)
+	if !ok || c.Stdout == nil {
+		return false
+	}
+	return c.Stdout.ColorCapable()
+}
+
+func colorCode(name string) string {
+	return expander.Colors().Expand(name).(string)
+}
+
+func (PrettyMode) Synopsis() string {
+	return "all|format|colors|none"
+}
+
+func (p PrettyMode) String() string {
+	return prettyStrings[p]
+}
+
+func (p *PrettyMode) Set(arg string) error {
+	for k, v := range prettyStrings {
+		if v == arg {
+			*p = k
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown pretty mode %q", arg)
+}
+
+var _ flag.Value = (*PrettyMode)(nil)
@@ -0,0 +1,348 @@
+// Copyright 2026 The Joe-cli Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package httpclient
+
+import (
+	"bytes"
+	"encoding/xml"
+	"slices"
+	"strings"
+)
+
+type htmlTokenKind int
+
+// htmlToken is a span of the original document.  Tokens are never
+// re-encoded so that entities, attribute quoting, and the contents of
+// raw text elements are written exactly as they were received.
+type htmlToken struct {
+	kind htmlTokenKind
+	raw  []byte
+	name string
+}
+
+const (
+	htmlText htmlTokenKind = iota
+	htmlStartTag
+	htmlEndTag
+	htmlSelfClosingTag
+	htmlRawElement
+	htmlComment
+	htmlDirective
+)
+
+// htmlRawTextElements have contents which are copied without formatting
+// because whitespace or markup within them is significant
+var htmlRawTextElements = []string{"pre", "textarea", "script", "style"}
+
+// formatHTML indents the document by adding line breaks and indentation
+// between tags.  Unlike XML, the document isn't parsed, so HTML that isn't
+// well-formed is still formatted.
+func (m *markupFormatter) formatHTML(out *bytes.Buffer) {
+	tokens := scanHTML(m.buf.Bytes())
+
+	var (
+		depth   int
+		lineEnd = func() {
+			if m.indent && out.Len() > 0 {
+				out.WriteByte('\n')
+			}
+		}
+		indent = func() {
+			if m.indent {
+				out.WriteString(strings.Repeat("  ", depth))
+			}
+		}
+	)
+
+	for i := 0; i < len(tokens); i++ {
+		t := tokens[i]
+		switch t.kind {
+		case htmlStartTag:
+			lineEnd()
+			indent()
+			m.writeHTMLTag(out, t.raw)
+			if isHTMLVoid(t.name) {
+				continue
+			}
+
+			// Elements which only contain text are written on one line
+			if i+2 < len(tokens) && tokens[i+1].kind == htmlText && isHTMLEndTag(tokens[i+2], t.name) {
+				m.writeHTMLText(out, tokens[i+1].raw)
+				m.writeHTMLTag(out, tokens[i+2].raw)
+				i += 2
+				continue
+			}
+			if i+1 < len(tokens) && isHTMLEndTag(tokens[i+1], t.name) {
+				m.writeHTMLTag(out, tokens[i+1].raw)
+				i++
+				continue
+			}
+			depth++
+
+		case htmlEndTag:
+			depth = max(depth-1, 0)
+			lineEnd()
+			indent()
+			m.writeHTMLTag(out, t.raw)
+
+		case htmlSelfClosingTag:
+			lineEnd()
+			indent()
+			m.writeHTMLTag(out, t.raw)
+
+		case htmlRawElement:
+			lineEnd()
+			indent()
+			m.writeHTMLRawElement(out, t)
+
+		case htmlText:
+			if m.indent && len(bytes.TrimSpace(t.raw)) == 0 {
+				continue
+			}
+			lineEnd()
+			indent()
+			m.writeHTMLText(out, t.raw)
+
+		case htmlComment:
+			lineEnd()
+			indent()
+			m.color(out, prettyPalette.comment)
+			out.Write(t.raw)
+			m.color(out, prettyPalette.reset)
+
+		case htmlDirective:
+			lineEnd()
+			indent()
+			m.color(out, prettyPalette.punct)
+			out.Write(t.raw)
+			m.color(out, prettyPalette.reset)
+		}
+	}
+	if m.indent {
+		out.WriteByte('\n')
+	}
+}
+
+func (m *markupFormatter) writeHTMLText(out *bytes.Buffer, text []byte) {
+	if m.indent {
+		text = bytes.TrimSpace(text)
+	}
+	out.Write(text)
+}
+
+// writeHTMLRawElement writes the start tag, contents, and end tag of a raw
+// text element.  Only the tags are colored.
+func (m *markupFormatter) writeHTMLRawElement(out *bytes.Buffer, t htmlToken) {
+	start := htmlTagEnd(t.raw, 0)
+	end := bytes.LastIndex(t.raw, []byte("</"))
+	if end < start {
+		end = len(t.raw)
+	}
+	m.writeHTMLTag(out, t.raw[:start])
+	out.Write(t.raw[start:end])
+	m.writeHTMLTag(out, t.raw[end:])
+}
+
+// writeHTMLTag writes the tag as it appeared in the document, inserting
+// colors around the name, attribute names, and attribute values
+func (m *markupFormatter) writeHTMLTag(out *bytes.Buffer, raw []byte) {
+	if !m.colors || len(raw) == 0 {
+		out.Write(raw)
+		return
+	}
+
+	i := 1
+	if i < len(raw) && raw[i] == '/' {
+		i++
+	}
+	i = htmlNameEnd(raw, i)
+	m.color(out, prettyPalette.key)
+	out.Write(raw[:i])
+	m.color(out, prettyPalette.reset)
+
+	for i < len(raw) {
+		c := raw[i]
+		switch {
+		case isHTMLSpace(c):
+			out.WriteByte(c)
+			i++
+
+		case c == '>' || (c == '/' && i+1 < len(raw) && raw[i+1] == '>'):
+			m.color(out, prettyPalette.key)
+			out.Write(raw[i:])
+			m.color(out, prettyPalette.reset)
+			return
+
+		case c == '=':
+			out.WriteByte(c)
+			i++
+			j := i
+			if j < len(raw) && (raw[j] == '"' || raw[j] == '\'') {
+				if k := bytes.IndexByte(raw[j+1:], raw[j]); k >= 0 {
+					j += k + 2
+				} else {
+					j = len(raw)
+				}
+			} else {
+				for j < len(raw) && !isHTMLSpace(raw[j]) && raw[j] != '>' {
+					j++
+				}
+			}
+			m.color(out, prettyPalette.str)
+			out.Write(raw[i:j])
+			m.color(out, prettyPalette.reset)
+			i = j
+
+		default:
+			j := htmlNameEnd(raw, i)
+			if j == i {
+				j++
+			}
+			m.color(out, prettyPalette.literal)
+			out.Write(raw[i:j])
+			m.color(out, prettyPalette.reset)
+			i = j
+		}
+	}
+}
+
+// scanHTML splits the document into tokens.  Every byte of the document is
+// contained in exactly one token.
+func scanHTML(doc []byte) []htmlToken {
+	var tokens []htmlToken
+	text := 0
+	flushText := func(end int) {
+		if end > text {
+			tokens = append(tokens, htmlToken{kind: htmlText, raw: doc[text:end]})
+		}
+	}
+
+	for i := 0; i < len(doc); {
+		if doc[i] != '<' || i+1 >= len(doc) {
+			i++
+			continue
+		}
+
+		var (
+			t   htmlToken
+			end int
+		)
+		switch next := doc[i+1]; {
+		case bytes.HasPrefix(doc[i:], []byte("<!--")):
+			end = indexAfter(doc, i+4, "-->")
+			t = htmlToken{kind: htmlComment}
+
+		case next == '!' || next == '?':
+			end = indexAfter(doc, i+2, ">")
+			t = htmlToken{kind: htmlDirective}
+
+		case next == '/' && i+2 < len(doc) && isHTMLNameStart(doc[i+2]):
+			end = htmlTagEnd(doc, i)
+			t = htmlToken{kind: htmlEndTag, name: htmlTagName(doc[i+2:])}
+
+		case isHTMLNameStart(next):
+			end = htmlTagEnd(doc, i)
+			t = htmlToken{kind: htmlStartTag, name: htmlTagName(doc[i+1:])}
+			switch {
+			case bytes.HasSuffix(doc[i:end], []byte("/>")):
+				t.kind = htmlSelfClosingTag
+			case isHTMLRawText(t.name):
+				t.kind = htmlRawElement
+				end = htmlRawElementEnd(doc, end, t.name)
+			}
+
+		default:
+			i++
+			continue
+		}
+
+		flushText(i)
+		t.raw = doc[i:end]
+		tokens = append(tokens, t)
+		i = end
+		text = end
+	}
+	flushText(len(doc))
+	return tokens
+}
+
+// htmlTagEnd finds the end of the tag which starts at i, skipping > within
+// quoted attribute values
+func htmlTagEnd(doc []byte, i int) int {
+	var quote byte
+	for ; i < len(doc); i++ {
+		c := doc[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '>':
+			return i + 1
+		}
+	}
+	return len(doc)
+}
+
+// htmlRawElementEnd finds the end of the end tag of the raw text element
+// whose contents start at i
+func htmlRawElementEnd(doc []byte, i int, name string) int {
+	closing := []byte("</" + name)
+	lower := bytes.ToLower(doc[i:])
+	for j := 0; ; {
+		k := bytes.Index(lower[j:], closing)
+		if k < 0 {
+			return len(doc)
+		}
+		j += k + len(closing)
+		if j >= len(lower) || isHTMLSpace(lower[j]) || lower[j] == '>' || lower[j] == '/' {
+			return htmlTagEnd(doc, i+j)
+		}
+	}
+}
+
+func indexAfter(doc []byte, i int, s string) int {
+	if k := bytes.Index(doc[i:], []byte(s)); k >= 0 {
+		return i + k + len(s)
+	}
+	return len(doc)
+}
+
+func htmlTagName(b []byte) string {
+	return strings.ToLower(string(b[:htmlNameEnd(b, 0)]))
+}
+
+func htmlNameEnd(b []byte, i int) int {
+	for i < len(b) && !isHTMLSpace(b[i]) && b[i] != '>' && b[i] != '/' && b[i] != '=' {
+		i++
+	}
+	return i
+}
+
+func isHTMLNameStart(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+func isHTMLSpace(c byte) bool {
+	switch c {
+	case ' ', '\t', '\r', '\n', '\f':
+		return true
+	}
+	return false
+}
+
+func isHTMLEndTag(t htmlToken, name string) bool {
+	return t.kind == htmlEndTag && t.name == name
+}
+
+func isHTMLRawText(name string) bool {
+	return slices.Contains(htmlRawTextElements, name)
+}
+
+func isHTMLVoid(name string) bool {
+	return slices.Contains(xml.HTMLAutoClose, name)
+}
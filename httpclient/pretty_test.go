@@ -0,0 +1,220 @@
+// Copyright 2026 The Joe-cli Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package httpclient_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/Carbonfrost/joe-cli"
+	"github.com/Carbonfrost/joe-cli-http/httpclient"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PrettyMode", func() {
+
+	Describe("Set", func() {
+		DescribeTable("examples", func(text string, expected httpclient.PrettyMode) {
+			var actual httpclient.PrettyMode
+			Expect(actual.Set(text)).To(Succeed())
+			Expect(actual).To(Equal(expected))
+			Expect(actual.String()).To(Equal(text))
+		},
+			Entry("all", "all", httpclient.PrettyAll),
+			Entry("format", "format", httpclient.PrettyFormat),
+			Entry("colors", "colors", httpclient.PrettyColors),
+			Entry("none", "none", httpclient.PrettyNone),
+		)
+
+		It("returns an error on unknown mode", func() {
+			var actual httpclient.PrettyMode
+			Expect(actual.Set("sparkly")).To(MatchError(`unknown pretty mode "sparkly"`))
+		})
+	})
+})
+
+var _ = Describe("NewPrettyDownloader", func() {
+
+	prettyPrint := func(mode httpclient.PrettyMode, contentType string, chunks ...string) string {
+		var out bytes.Buffer
+		d := httpclient.NewPrettyDownloader(mode, httpclient.NewDownloaderTo(&out))
+		w, err := d.OpenDownload(context.Background(), &httpclient.Response{
+			Response: &http.Response{
+				Header: http.Header{"Content-Type": []string{contentType}},
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		for _, c := range chunks {
+			_, err = w.Write([]byte(c))
+			Expect(err).NotTo(HaveOccurred())
+		}
+		Expect(w.Close()).To(Succeed())
+		return out.String()
+	}
+
+	DescribeTable("formatting examples", func(contentType string, input []string, expected string) {
+		Expect(prettyPrint(httpclient.PrettyFormat, contentType, input...)).To(Equal(expected))
+	},
+		Entry("JSON object",
+			"application/json",
+			[]string{`{"a":1,"b":[true,null,"x"]}`},
+			"{\n    \"a\": 1,\n    \"b\": [\n        true,\n        null,\n        \"x\"\n    ]\n}\n"),
+		Entry("JSON empty containers",
+			"application/json; charset=utf-8",
+			[]string{`{"a": {}, "b": [ ]}`},
+			"{\n    \"a\": {},\n    \"b\": []\n}\n"),
+		Entry("JSON split across writes",
+			"application/problem+json",
+			[]string{`{"ti`, `tle":"a \"q`, `uoted\" {value}","n":12`, `34}`},
+			"{\n    \"title\": \"a \\\"quoted\\\" {value}\",\n    \"n\": 1234\n}\n"),
+		Entry("JSON stream of values",
+			"application/json",
+			[]string{"{\"a\":1}\n{\"a\":2}\n"},
+			"{\n    \"a\": 1\n}\n{\n    \"a\": 2\n}\n"),
+		Entry("XML",
+			"application/xml",
+			[]string{`<?xml version="1.0"?><a x="1"><b>text</b><c/></a>`},
+			"<?xml version=\"1.0\"?>\n<a x=\"1\">\n  <b>text</b>\n  <c/>\n</a>\n"),
+		Entry("HTML",
+			"text/html",
+			[]string{`<html><body><p>Hi<br></p></body></html>`},
+			"<html>\n  <body>\n    <p>\n      Hi\n      <br>\n    </p>\n  </body>\n</html>\n"),
+		Entry("HTML entities and attributes are unchanged",
+			"text/html",
+			[]string{`<p title='a &amp; b' hidden>&nbsp;&copy; 2026</p>`},
+			"<p title='a &amp; b' hidden>&nbsp;&copy; 2026</p>\n"),
+		Entry("HTML raw text elements are unchanged",
+			"text/html",
+			[]string{"<div><pre>  a\n    b</pre><script>if (a < b && c > d) {}</script></div>"},
+			"<div>\n  <pre>  a\n    b</pre>\n  <script>if (a < b && c > d) {}</script>\n</div>\n"),
+		Entry("HTML that isn't well-formed",
+			"text/html",
+			[]string{`<ul><li>one<li>two</ul>`},
+			"<ul>\n  <li>\n    one\n    <li>\n      two\n    </ul>\n"),
+		Entry("malformed XML is unchanged",
+			"text/xml",
+			[]string{`<a><b></a>`},
+			`<a><b></a>`),
+		Entry("other content types are unchanged",
+			"text/plain",
+			[]string{`{"a":1}`},
+			`{"a":1}`),
+	)
+
+	It("doesn't use colors when stdout isn't capable of color", func() {
+		Expect(prettyPrint(httpclient.PrettyAll, "application/json", `{"a":1}`)).To(Equal("{\n    \"a\": 1\n}\n"))
+	})
+
+	It("colors JSON when stdout is capable of color", func() {
+		var out bytes.Buffer
+		stdout := cli.NewWriter(&out)
+		stdout.SetColorCapable(true)
+
+		d := httpclient.NewPrettyDownloader(httpclient.PrettyColors, httpclient.NewDownloaderTo(&out))
+		w, _ := d.OpenDownload(&cli.Context{Stdout: stdout}, &httpclient.Response{
+			Response: &http.Response{
+				Header: http.Header{"Content-Type": []string{"application/json"}},
+			},
+		})
+		w.Write([]byte(`{"key": "value"}`))
+		w.Close()
+
+		Expect(out.String()).To(And(
+			ContainSubstring("\x1b[34m\"key\"\x1b[0m"),
+			ContainSubstring("\x1b[32m\"value\"\x1b[0m"),
+		))
+	})
+
+	It("only inserts colors into HTML", func() {
+		const doc = "<p class=a title='x > y'>&lt;b&gt;\n<pre> x </pre></p>"
+		var out bytes.Buffer
+		stdout := cli.NewWriter(&out)
+		stdout.SetColorCapable(true)
+
+		d := httpclient.NewPrettyDownloader(httpclient.PrettyColors, httpclient.NewDownloaderTo(&out))
+		w, _ := d.OpenDownload(&cli.Context{Stdout: stdout}, &httpclient.Response{
+			Response: &http.Response{
+				Header: http.Header{"Content-Type": []string{"text/html"}},
+			},
+		})
+		w.Write([]byte(doc))
+		w.Close()
+
+		Expect(out.String()).To(ContainSubstring("\x1b[34m<p\x1b[0m"))
+		Expect(regexp.MustCompile("\x1b\\[[0-9;]*m").ReplaceAllString(out.String(), "")).To(Equal(doc))
+	})
+
+	Context("when used with the client", func() {
+
+		var terminal bool
+
+		BeforeEach(func() {
+			terminal = false
+		})
+
+		fetch := func(command string) string {
+			var out bytes.Buffer
+			stdout := cli.NewWriter(&out)
+			stdout.SetColorCapable(terminal)
+
+			app := &cli.App{
+				Uses: httpclient.New(
+					httpclient.WithTransport(httpclient.RoundTripperFunc(func(*http.Request) *http.Response {
+						return &http.Response{
+							StatusCode: http.StatusOK,
+							Header:     http.Header{"Content-Type": []string{"application/json"}},
+							Body:       io.NopCloser(strings.NewReader(`{"a":1}`)),
+						}
+					})),
+				),
+				Action: httpclient.FetchAndPrint(),
+				Stdout: stdout,
+			}
+
+			args, _ := cli.Split(command)
+			err := app.RunContext(context.Background(), args)
+			Expect(err).NotTo(HaveOccurred())
+			return out.String()
+		}
+
+		It("formats output", func() {
+			Expect(fetch("_ --pretty=format https://example.com")).To(Equal("{\n    \"a\": 1\n}\n"))
+		})
+
+		It("formats output after headers", func() {
+			Expect(fetch("_ -i --pretty=all https://example.com")).To(Equal("Content-Type: application/json\r\n\r\n{\n    \"a\": 1\n}\n"))
+		})
+
+		It("ends colored header lines with CRLF", func() {
+			terminal = true
+			Expect(fetch("_ -i --pretty=colors https://example.com")).To(HavePrefix(
+				"\x1b[34mContent-Type\x1b[0m: application/json\r\n\r\n",
+			))
+		})
+
+		It("doesn't format by default when stdout isn't a terminal", func() {
+			Expect(fetch("_ https://example.com")).To(Equal(`{"a":1}`))
+		})
+
+		It("formats and colors by default when stdout is a terminal", func() {
+			terminal = true
+			Expect(fetch("_ https://example.com")).To(And(
+				ContainSubstring("\x1b[34m\"a\"\x1b[0m"),
+				ContainSubstring("\n    "),
+			))
+		})
+
+		It("doesn't format when none is set and stdout is a terminal", func() {
+			terminal = true
+			Expect(fetch("_ --pretty=none https://example.com")).To(Equal(`{"a":1}`))
+		})
+	})
+})
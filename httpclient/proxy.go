@@ -0,0 +1,339 @@
+// Copyright 2026 The Joe-cli Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package httpclient
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/Carbonfrost/joe-cli"
+)
+
+// proxySettings contains the explicit proxy configuration of the client.
+// When no proxy URL is set, the proxy is obtained from the environment.
+type proxySettings struct {
+	url     *url.URL
+	noProxy []string
+	user    *UserInfo
+	header  http.Header
+	auth    Authenticator
+
+	mu          sync.Mutex
+	authHeader  http.Header
+	socksUser   *url.Userinfo
+	credentials bool
+}
+
+type proxyTransport struct {
+	client    *Client
+	Transport http.RoundTripper
+}
+
+var proxySchemes = []string{"http", "https", "socks5", "socks5h"}
+
+// ParseProxyURL parses the URL of a proxy.  When no scheme is present,
+// http:// is implied.  The schemes http, https, socks5, and socks5h are
+// supported.  Note that host names are resolved by the proxy for both
+// socks5 and socks5h.
+func ParseProxyURL(s string) (*url.URL, error) {
+	if !strings.Contains(s, "://") {
+		s = "http://" + s
+	}
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, err
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	for _, scheme := range proxySchemes {
+		if u.Scheme == scheme {
+			return u, nil
+		}
+	}
+	return nil, fmt.Errorf("unsupported proxy scheme %q", u.Scheme)
+}
+
+// SetProxy sets the proxy used for all requests except those excluded
+// by SetNoProxy
+func (c *Client) SetProxy(s string) error {
+	u, err := ParseProxyURL(s)
+	if err != nil {
+		return err
+	}
+	c.proxy.url = u
+	return nil
+}
+
+// SetNoProxy sets a comma-separated list of hosts which are not proxied.
+// Each item is a host name, domain suffix, IP address, or CIDR.  The value *
+// matches all hosts.
+func (c *Client) SetNoProxy(s string) error {
+	for item := range strings.SplitSeq(s, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			c.proxy.noProxy = append(c.proxy.noProxy, item)
+		}
+	}
+	return nil
+}
+
+// SetProxyUser sets the user and password used to authenticate with the proxy
+func (c *Client) SetProxyUser(user *UserInfo) error {
+	c.proxy.user = user
+	return nil
+}
+
+// SetProxyHeader adds a header that is sent to HTTP proxies
+func (c *Client) SetProxyHeader(n *HeaderValue) error {
+	if c.proxy.header == nil {
+		c.proxy.header = http.Header{}
+	}
+	c.proxy.header.Add(n.Name, n.Value)
+	return nil
+}
+
+// SetProxyAuth sets the authenticator used to authenticate with the proxy.
+// By default, basic auth is used when a proxy user is set.
+func (c *Client) SetProxyAuth(auth Authenticator) error {
+	c.proxy.auth = auth
+	return nil
+}
+
+// ProxyAuthenticator gets the authenticator used for the proxy
+func (c *Client) ProxyAuthenticator() Authenticator {
+	if c.proxy.auth == nil {
+		if c.proxy.user == nil {
+			return NoAuth
+		}
+		return BasicAuth
+	}
+	return c.proxy.auth
+}
+
+func (c *Client) proxyFunc(req *http.Request) (*url.URL, error) {
+	if matchNoProxy(c.proxy.noProxy, req.URL.Hostname()) {
+		return nil, nil
+	}
+	if c.proxy.url == nil {
+		return http.ProxyFromEnvironment(req)
+	}
+
+	u := c.proxy.url
+	if strings.HasPrefix(u.Scheme, "socks5") && c.proxy.user != nil {
+		// SOCKS credentials are only provided by the URL
+		if _, err := c.proxyAuthHeader(req.Context()); err != nil {
+			return nil, err
+		}
+		if c.proxy.socksUser == nil {
+			return nil, fmt.Errorf("SOCKS proxies only support authentication with a user and password")
+		}
+		uu := *u
+		uu.User = c.proxy.socksUser
+		return &uu, nil
+	}
+	return u, nil
+}
+
+func (c *Client) proxyConnectHeader(ctx context.Context, proxyURL *url.URL, target string) (http.Header, error) {
+	if l, ok := c.logger.(ProxyTraceLogger); ok {
+		l.ProxyConnectStart(proxyURL, target)
+	}
+	return c.proxyHeaders(ctx)
+}
+
+func (c *Client) onProxyConnectResponse(_ context.Context, proxyURL *url.URL, _ *http.Request, resp *http.Response) error {
+	if l, ok := c.logger.(ProxyTraceLogger); ok {
+		l.ProxyConnectDone(proxyURL, resp)
+	}
+	return nil
+}
+
+// proxyHeaders gets the headers sent to the proxy, which are the
+// explicit proxy headers and any headers generated by the proxy authenticator.
+func (c *Client) proxyHeaders(ctx context.Context) (http.Header, error) {
+	auth, err := c.proxyAuthHeader(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res := c.proxy.header.Clone()
+	if res == nil {
+		res = http.Header{}
+	}
+	for k, v := range auth {
+		res[k] = v
+	}
+	return res, nil
+}
+
+// proxyAuthHeader applies the proxy authenticator to obtain the headers
+// it generates.  The Authorization header is converted to Proxy-Authorization.
+// The credentials of basic auth are also kept for SOCKS proxies, which
+// includes any that were prompted for.  The result is cached so that prompting
+// for credentials happens only once.
+func (c *Client) proxyAuthHeader(ctx context.Context) (http.Header, error) {
+	p := &c.proxy
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.credentials {
+		return p.authHeader, nil
+	}
+
+	auth := c.ProxyAuthenticator()
+	r := (&http.Request{Header: http.Header{}}).WithContext(ctx)
+	if p.user != nil || !auth.RequiresUserInfo() {
+		for _, a := range c.authMiddleware {
+			auth = a(ctx, auth)
+		}
+		if err := auth.Authenticate(r, p.user); err != nil {
+			return nil, err
+		}
+	}
+
+	if user, password, ok := r.BasicAuth(); ok {
+		p.socksUser = url.UserPassword(user, password)
+	}
+	if v, ok := r.Header["Authorization"]; ok {
+		r.Header["Proxy-Authorization"] = v
+		delete(r.Header, "Authorization")
+	}
+	p.authHeader = r.Header
+	p.credentials = true
+	return p.authHeader, nil
+}
+
+func (c *Client) setupProxyTransport(_ context.Context, t http.RoundTripper) http.RoundTripper {
+	if defaultTransport, ok := t.(*http.Transport); ok {
+		defaultTransport.GetProxyConnectHeader = c.proxyConnectHeader
+		defaultTransport.OnProxyConnectResponse = c.onProxyConnectResponse
+	}
+	return &proxyTransport{
+		client:    c,
+		Transport: t,
+	}
+}
+
+// RoundTrip adds proxy headers to plain HTTP requests that are sent to an
+// HTTP proxy.  (Requests which tunnel through the proxy using CONNECT obtain
+// the headers from the transport instead.)
+func (t *proxyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Scheme != "http" {
+		return t.Transport.RoundTrip(req)
+	}
+	proxyURL, err := t.client.proxyFunc(req)
+	if err != nil {
+		return nil, err
+	}
+	if proxyURL == nil || strings.HasPrefix(proxyURL.Scheme, "socks5") {
+		return t.Transport.RoundTrip(req)
+	}
+
+	headers, err := t.client.proxyHeaders(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	if len(headers) > 0 {
+		req = req.Clone(req.Context())
+		for k, v := range headers {
+			req.Header[k] = v
+		}
+	}
+	return t.Transport.RoundTrip(req)
+}
+
+func (t *proxyTransport) CloseIdleConnections() {
+	closeIdleConnections(t.Transport)
+}
+
+func matchNoProxy(list []string, host string) bool {
+	if host == "" {
+		return false
+	}
+	host = strings.ToLower(host)
+	ip := net.ParseIP(host)
+
+	for _, item := range list {
+		item = strings.ToLower(item)
+		if item == "*" {
+			return true
+		}
+		if ip != nil {
+			if _, cidr, err := net.ParseCIDR(item); err == nil {
+				if cidr.Contains(ip) {
+					return true
+				}
+				continue
+			}
+			if other := net.ParseIP(item); other != nil && other.Equal(ip) {
+				return true
+			}
+			continue
+		}
+
+		domain := strings.TrimPrefix(item, ".")
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+func SetProxy(s ...string) cli.Action {
+	return cli.Pipeline(
+		&cli.Prototype{
+			Name:      "proxy",
+			Aliases:   []string{"x"},
+			UsageText: "[scheme://]host[:port]",
+			HelpText:  "Use the specified proxy.  The schemes http, https, socks5, and socks5h are supported",
+			Category:  proxyOptions,
+		},
+		withBinding((*Client).SetProxy, s),
+		tagged,
+	)
+}
+
+func SetNoProxy(s ...string) cli.Action {
+	return cli.Pipeline(
+		&cli.Prototype{
+			Name:      "noproxy",
+			UsageText: "LIST",
+			HelpText:  "Comma-separated list of hosts, domains, IP addresses, or CIDRs which do not use the proxy",
+			Category:  proxyOptions,
+			Options:   cli.EachOccurrence,
+		},
+		withBinding((*Client).SetNoProxy, s),
+		tagged,
+	)
+}
+
+func SetProxyUser(s ...*UserInfo) cli.Action {
+	return cli.Pipeline(
+		&cli.Prototype{
+			Name:     "proxy-user",
+			Aliases:  []string{"U"},
+			HelpText: "Set the user and password for proxy authentication",
+			Category: proxyOptions,
+		},
+		withBinding((*Client).SetProxyUser, s),
+		tagged,
+	)
+}
+
+func SetProxyHeader(s ...*HeaderValue) cli.Action {
+	return cli.Pipeline(
+		&cli.Prototype{
+			Name:     "proxy-header",
+			HelpText: "Sets header to {NAME} and {VALUE} in requests sent to the proxy",
+			Options:  cli.EachOccurrence,
+			Category: proxyOptions,
+		},
+		withBinding((*Client).SetProxyHeader, s),
+		tagged,
+	)
+}
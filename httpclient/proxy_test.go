@@ -0,0 +1,347 @@
+// Copyright 2026 The Joe-cli Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package httpclient_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sync"
+
+	"github.com/Carbonfrost/joe-cli"
+	"github.com/Carbonfrost/joe-cli-http/httpclient"
+	"golang.org/x/net/proxy"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ParseProxyURL", func() {
+
+	DescribeTable("examples", func(text string, expected string) {
+		actual, err := httpclient.ParseProxyURL(text)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(actual.String()).To(Equal(expected))
+	},
+		Entry("implied scheme", "proxy.example:3128", "http://proxy.example:3128"),
+		Entry("https", "https://proxy.example", "https://proxy.example"),
+		Entry("socks5", "SOCKS5://proxy.example:1080", "socks5://proxy.example:1080"),
+		Entry("socks5h", "socks5h://proxy.example:1080", "socks5h://proxy.example:1080"),
+	)
+
+	It("returns an error on unsupported scheme", func() {
+		_, err := httpclient.ParseProxyURL("ftp://proxy.example")
+		Expect(err).To(MatchError(`unsupported proxy scheme "ftp"`))
+	})
+})
+
+var _ = Describe("Proxy", func() {
+
+	// proxyStandIn records requests and acts as a forward proxy
+	type proxyStandIn struct {
+		*httptest.Server
+		mu       sync.Mutex
+		requests []*http.Request
+	}
+
+	newProxyStandIn := func() *proxyStandIn {
+		p := &proxyStandIn{}
+		p.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			p.mu.Lock()
+			p.requests = append(p.requests, r)
+			p.mu.Unlock()
+
+			if r.Method != http.MethodConnect {
+				fmt.Fprintf(w, "proxied %s", r.RequestURI)
+				return
+			}
+
+			upstream, err := net.Dial("tcp", r.Host)
+			if err != nil {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			conn, buf, _ := w.(http.Hijacker).Hijack()
+			go func() {
+				_, _ = io.Copy(upstream, buf)
+				upstream.Close()
+			}()
+			_, _ = io.Copy(conn, upstream)
+			conn.Close()
+		}))
+		DeferCleanup(p.Close)
+		return p
+	}
+
+	It("sends plain HTTP requests to the proxy", func() {
+		proxy := newProxyStandIn()
+		out, err := runClient("--proxy", proxy.URL, "--proxy-user", "bob:secret", "--proxy-header", "X-Proxy-Test:1", "http://example.invalid/path")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(out).To(Equal("proxied http://example.invalid/path"))
+		Expect(proxy.requests).To(HaveLen(1))
+		Expect(proxy.requests[0].Header.Get("Proxy-Authorization")).To(Equal("Basic Ym9iOnNlY3JldA=="))
+		Expect(proxy.requests[0].Header.Get("X-Proxy-Test")).To(Equal("1"))
+	})
+
+	It("tunnels HTTPS requests using CONNECT", func() {
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.Header.Get("Proxy-Authorization")).To(BeEmpty())
+			fmt.Fprint(w, "secure")
+		}))
+		DeferCleanup(server.Close)
+
+		proxy := newProxyStandIn()
+		out, err := runClient("-k", "--proxy", proxy.URL, "--proxy-user", "bob:secret", "--proxy-header", "X-Proxy-Test:1", server.URL)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(out).To(Equal("secure"))
+		Expect(proxy.requests).To(HaveLen(1))
+		Expect(proxy.requests[0].Method).To(Equal(http.MethodConnect))
+		Expect(proxy.requests[0].Header.Get("Proxy-Authorization")).To(Equal("Basic Ym9iOnNlY3JldA=="))
+		Expect(proxy.requests[0].Header.Get("X-Proxy-Test")).To(Equal("1"))
+	})
+
+	It("traces the CONNECT request", func() {
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, "secure")
+		}))
+		DeferCleanup(server.Close)
+
+		var trace bytes.Buffer
+		proxy := newProxyStandIn()
+		client := httpclient.New(httpclient.WithTraceOutput(&trace))
+		_, err := runClientWith(client, "-k", "--trace", "connections", "--proxy", proxy.URL, server.URL)
+		Expect(err).NotTo(HaveOccurred())
+
+		lines := regexp.MustCompile(`\x1b\[[0-9;]*m`).ReplaceAllString(trace.String(), "")
+		Expect(lines).To(ContainSubstring(
+			fmt.Sprintf("* Establishing tunnel to %s via proxy %s...\n", server.Listener.Addr(), proxy.URL),
+		))
+		Expect(lines).To(ContainSubstring("* Proxy replied 200 OK to CONNECT request\n"))
+	})
+
+	DescribeTable("bypasses the proxy for hosts in noproxy", func(noProxy string) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, "direct")
+		}))
+		DeferCleanup(server.Close)
+
+		proxy := newProxyStandIn()
+		out, err := runClient("--proxy", proxy.URL, "--noproxy", noProxy, server.URL)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(out).To(Equal("direct"))
+		Expect(proxy.requests).To(BeEmpty())
+	},
+		Entry("wildcard", "*"),
+		Entry("IP address", "example.com,127.0.0.1"),
+		Entry("CIDR", "10.0.0.0/8, 127.0.0.0/8"),
+	)
+})
+
+var _ = Describe("SOCKS proxy", func() {
+
+	// socksStandIn is a SOCKS5 proxy which requires user and password
+	// authentication (RFC 1928, RFC 1929) and records the credentials
+	// that were used
+	type socksStandIn struct {
+		net.Listener
+		user, password string
+
+		mu          sync.Mutex
+		credentials []string
+	}
+
+	var (
+		server *httptest.Server
+		socks  *socksStandIn
+	)
+
+	handshake := func(s *socksStandIn, conn net.Conn) (string, error) {
+		header := make([]byte, 2)
+		if _, err := io.ReadFull(conn, header); err != nil {
+			return "", err
+		}
+		if _, err := io.ReadFull(conn, make([]byte, header[1])); err != nil {
+			return "", err
+		}
+		if _, err := conn.Write([]byte{5, 2}); err != nil {
+			return "", err
+		}
+
+		// Username/password sub-negotiation
+		readString := func() (string, error) {
+			n := make([]byte, 1)
+			if _, err := io.ReadFull(conn, n); err != nil {
+				return "", err
+			}
+			b := make([]byte, n[0])
+			_, err := io.ReadFull(conn, b)
+			return string(b), err
+		}
+		if _, err := io.ReadFull(conn, make([]byte, 1)); err != nil {
+			return "", err
+		}
+		user, err := readString()
+		if err != nil {
+			return "", err
+		}
+		password, err := readString()
+		if err != nil {
+			return "", err
+		}
+		s.mu.Lock()
+		s.credentials = append(s.credentials, user+":"+password)
+		s.mu.Unlock()
+		if user != s.user || password != s.password {
+			_, _ = conn.Write([]byte{1, 1})
+			return "", fmt.Errorf("invalid credentials")
+		}
+		if _, err := conn.Write([]byte{1, 0}); err != nil {
+			return "", err
+		}
+
+		// CONNECT request
+		request := make([]byte, 4)
+		if _, err := io.ReadFull(conn, request); err != nil {
+			return "", err
+		}
+		var host string
+		switch request[3] {
+		case 1:
+			ip := make([]byte, net.IPv4len)
+			_, err = io.ReadFull(conn, ip)
+			host = net.IP(ip).String()
+		case 3:
+			host, err = readString()
+		case 4:
+			ip := make([]byte, net.IPv6len)
+			_, err = io.ReadFull(conn, ip)
+			host = net.IP(ip).String()
+		}
+		if err != nil {
+			return "", err
+		}
+		port := make([]byte, 2)
+		if _, err := io.ReadFull(conn, port); err != nil {
+			return "", err
+		}
+		return net.JoinHostPort(host, fmt.Sprint(binary.BigEndian.Uint16(port))), nil
+	}
+
+	serve := func(s *socksStandIn, conn net.Conn) {
+		defer conn.Close()
+		target, err := handshake(s, conn)
+		if err != nil {
+			return
+		}
+		upstream, err := net.Dial("tcp", target)
+		if err != nil {
+			_, _ = conn.Write([]byte{5, 5, 0, 1, 0, 0, 0, 0, 0, 0})
+			return
+		}
+		defer upstream.Close()
+		if _, err := conn.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0}); err != nil {
+			return
+		}
+		go func() {
+			_, _ = io.Copy(upstream, conn)
+			upstream.Close()
+		}()
+		_, _ = io.Copy(conn, upstream)
+	}
+
+	newSOCKSStandIn := func(user, password string) *socksStandIn {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+		s := &socksStandIn{Listener: l, user: user, password: password}
+		go func() {
+			for {
+				conn, err := l.Accept()
+				if err != nil {
+					return
+				}
+				go serve(s, conn)
+			}
+		}()
+		DeferCleanup(l.Close)
+		return s
+	}
+
+	BeforeEach(func() {
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, "through socks")
+		}))
+		DeferCleanup(server.Close)
+		socks = newSOCKSStandIn("bob", "secret")
+	})
+
+	It("is a SOCKS5 proxy that x/net/proxy can use", func() {
+		dialer, err := proxy.SOCKS5("tcp", socks.Addr().String(), &proxy.Auth{User: "bob", Password: "secret"}, proxy.Direct)
+		Expect(err).NotTo(HaveOccurred())
+
+		conn, err := dialer.Dial("tcp", server.Listener.Addr().String())
+		Expect(err).NotTo(HaveOccurred())
+		conn.Close()
+	})
+
+	It("uses the credentials from --proxy-user", func() {
+		out, err := runClient("--proxy", "socks5://"+socks.Addr().String(), "--proxy-user", "bob:secret", server.URL)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(out).To(Equal("through socks"))
+		Expect(socks.credentials).To(Equal([]string{"bob:secret"}))
+	})
+
+	It("uses the credentials returned by the authenticator", func() {
+		// Added after the prompt for credentials so that it runs first
+		client := httpclient.New()
+		client.Apply(httpclient.WithAction(cli.Pipeline(
+			client.Action,
+			cli.Before(cli.ActionOf(func() {
+				client.AddAuthenticatorMiddleware(func(_ context.Context, auth httpclient.Authenticator) httpclient.Authenticator {
+					return &promptedPassword{auth, "secret"}
+				})
+			})),
+		)))
+
+		out, err := runClientWith(client, "--proxy", "socks5://"+socks.Addr().String(), "--proxy-user", "bob", server.URL)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(out).To(Equal("through socks"))
+		Expect(socks.credentials).To(Equal([]string{"bob:secret"}))
+	})
+
+	It("returns an error when the credentials are rejected", func() {
+		_, err := runClient("--proxy", "socks5://"+socks.Addr().String(), "--proxy-user", "bob:wrong", server.URL)
+		Expect(err).To(HaveOccurred())
+		Expect(socks.credentials).To(Equal([]string{"bob:wrong"}))
+	})
+})
+
+// promptedPassword stands in for prompting by supplying the password
+// when the user info doesn't have one.  The user info is copied so that
+// the password is only available from the request that was authenticated.
+type promptedPassword struct {
+	httpclient.Authenticator
+	password string
+}
+
+func (p *promptedPassword) Authenticate(r *http.Request, ui *httpclient.UserInfo) error {
+	if ui != nil && !ui.HasPassword {
+		prompted := *ui
+		prompted.Password, prompted.HasPassword = p.password, true
+		ui = &prompted
+	}
+	return p.Authenticator.Authenticate(r, ui)
+}
@@ -0,0 +1,320 @@
+// Copyright 2026 The Joe-cli Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package httpclient
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Carbonfrost/joe-cli/extensions/expr/expander"
+)
+
+// Timings provides the duration of each phase of a request.  Phases are
+// measured from the start of the request or redirect hop that produced
+// the response, and they are zero when the phase didn't occur, such as
+// DNS and connecting when a connection is reused.  Redirect is the time
+// spent in all previous redirect hops, and Total is the time from the
+// start of the first request until the response body was read, which
+// includes Redirect.
+type Timings struct {
+	NameLookup    time.Duration
+	Connect       time.Duration
+	AppConnect    time.Duration
+	PreTransfer   time.Duration
+	StartTransfer time.Duration
+	Redirect      time.Duration
+	Total         time.Duration
+}
+
+// responseStats records the timing and sizes for one request or redirect hop
+type responseStats struct {
+	mu sync.Mutex
+
+	opStart     time.Time
+	start       time.Time
+	dnsDone     time.Time
+	connectDone time.Time
+	tlsDone     time.Time
+	gotConn     time.Time
+	firstByte   time.Time
+	end         time.Time
+	redirect    time.Duration
+	remoteAddr  net.Addr
+	tls         *tls.ConnectionState
+	uploaded    int64
+	downloaded  int64
+}
+
+// statsRecorder tracks the current hop using the client trace.  When a
+// redirect occurs, the current hop is completed and a new one starts.
+type statsRecorder struct {
+	mu      sync.Mutex
+	current *responseStats
+}
+
+// countingBody counts the bytes read from the response body and records
+// when reading finished
+type countingBody struct {
+	io.ReadCloser
+	stats *responseStats
+}
+
+// countingUpload counts the bytes of the request body that were sent
+type countingUpload struct {
+	io.ReadCloser
+	recorder *statsRecorder
+}
+
+type statsRecorderKey struct{}
+
+func newStatsRecorder() *statsRecorder {
+	now := time.Now()
+	return &statsRecorder{
+		current: &responseStats{opStart: now, start: now},
+	}
+}
+
+func statsRecorderFromContext(ctx context.Context) *statsRecorder {
+	r, _ := ctx.Value(statsRecorderKey{}).(*statsRecorder)
+	return r
+}
+
+func (r *statsRecorder) withContext(ctx context.Context) context.Context {
+	ctx = context.WithValue(ctx, statsRecorderKey{}, r)
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		DNSDone: func(info httptrace.DNSDoneInfo) {
+			r.mark(func(s *responseStats) *time.Time { return &s.dnsDone })
+		},
+		ConnectDone: func(_, _ string, err error) {
+			if err == nil {
+				r.mark(func(s *responseStats) *time.Time { return &s.connectDone })
+			}
+		},
+		TLSHandshakeDone: func(_ tls.ConnectionState, err error) {
+			if err == nil {
+				r.mark(func(s *responseStats) *time.Time { return &s.tlsDone })
+			}
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			r.mark(func(s *responseStats) *time.Time { return &s.gotConn })
+			s := r.hop()
+			s.mu.Lock()
+			s.remoteAddr = info.Conn.RemoteAddr()
+			s.mu.Unlock()
+		},
+		GotFirstResponseByte: func() {
+			r.mark(func(s *responseStats) *time.Time { return &s.firstByte })
+		},
+	})
+}
+
+func (r *statsRecorder) hop() *responseStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.current
+}
+
+func (r *statsRecorder) mark(field func(*responseStats) *time.Time) {
+	s := r.hop()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if t := field(s); t.IsZero() {
+		*t = time.Now()
+	}
+}
+
+// redirected completes the current hop, which was the request req, and
+// starts the next one
+func (r *statsRecorder) redirected(req *http.Request, resp *http.Response) *responseStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	s := r.current
+	s.mu.Lock()
+	s.end = now
+	if resp != nil {
+		s.tls = resp.TLS
+	}
+	if s.uploaded == 0 {
+		s.uploaded = max(req.ContentLength, 0)
+	}
+	next := &responseStats{
+		opStart:  s.opStart,
+		start:    now,
+		redirect: s.redirect + now.Sub(s.start),
+	}
+	s.mu.Unlock()
+
+	r.current = next
+	return s
+}
+
+// done associates the current hop with the final response
+func (r *statsRecorder) done(resp *http.Response) {
+	s := r.hop()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tls = resp.TLS
+	if resp.Request != nil && s.uploaded == 0 {
+		s.uploaded = max(resp.Request.ContentLength, 0)
+	}
+	resp.Body = &countingBody{ReadCloser: resp.Body, stats: s}
+}
+
+// countUpload wraps the request body to count the bytes sent
+func (r *statsRecorder) countUpload(req *http.Request) {
+	if req.Body != nil && req.Body != http.NoBody {
+		req.Body = &countingUpload{ReadCloser: req.Body, recorder: r}
+	}
+}
+
+func (s *responseStats) Timings() Timings {
+	if s == nil {
+		return Timings{}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	since := func(t time.Time) time.Duration {
+		if t.IsZero() {
+			return 0
+		}
+		return t.Sub(s.start)
+	}
+	end := s.end
+	if end.IsZero() {
+		end = time.Now()
+	}
+	return Timings{
+		NameLookup:    since(s.dnsDone),
+		Connect:       since(s.connectDone),
+		AppConnect:    since(s.tlsDone),
+		PreTransfer:   since(s.gotConn),
+		StartTransfer: since(s.firstByte),
+		Redirect:      s.redirect,
+		Total:         end.Sub(s.opStart),
+	}
+}
+
+func (s *responseStats) RemoteAddr() net.Addr {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.remoteAddr
+}
+
+func (s *responseStats) tlsState() *tls.ConnectionState {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tls
+}
+
+func (s *responseStats) sizes() (uploaded, downloaded int64) {
+	if s == nil {
+		return 0, 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.uploaded, s.downloaded
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.stats.mu.Lock()
+	b.stats.downloaded += int64(n)
+	if err == io.EOF && b.stats.end.IsZero() {
+		b.stats.end = time.Now()
+	}
+	b.stats.mu.Unlock()
+	return n, err
+}
+
+func (u *countingUpload) Read(p []byte) (int, error) {
+	n, err := u.ReadCloser.Read(p)
+	s := u.recorder.hop()
+	s.mu.Lock()
+	s.uploaded += int64(n)
+	s.mu.Unlock()
+	return n, err
+}
+
+func (b *countingBody) Close() error {
+	b.stats.mu.Lock()
+	if b.stats.end.IsZero() {
+		b.stats.end = time.Now()
+	}
+	b.stats.mu.Unlock()
+	return b.ReadCloser.Close()
+}
+
+// expandStats provides the time, size, and speed variables.  Times are
+// in seconds as in cURL.
+func expandStats(s *responseStats) expander.Interface {
+	return expander.Func(func(name string) any {
+		switch name {
+		case "time.namelookup":
+			return seconds(s.Timings().NameLookup)
+		case "time.connect":
+			return seconds(s.Timings().Connect)
+		case "time.appconnect":
+			return seconds(s.Timings().AppConnect)
+		case "time.pretransfer":
+			return seconds(s.Timings().PreTransfer)
+		case "time.starttransfer":
+			return seconds(s.Timings().StartTransfer)
+		case "time.redirect":
+			return seconds(s.Timings().Redirect)
+		case "time.total":
+			return seconds(s.Timings().Total)
+		case "size.download":
+			_, downloaded := s.sizes()
+			return downloaded
+		case "size.upload":
+			uploaded, _ := s.sizes()
+			return uploaded
+		case "speed.download":
+			_, downloaded := s.sizes()
+			total := s.Timings().Total.Seconds()
+			if total == 0 {
+				return 0
+			}
+			return int64(float64(downloaded) / total)
+		case "remote.ip":
+			ip, _ := splitRemoteAddr(s.RemoteAddr())
+			return ip
+		case "remote.port":
+			_, port := splitRemoteAddr(s.RemoteAddr())
+			return port
+		}
+		return nil
+	})
+}
+
+func splitRemoteAddr(addr net.Addr) (ip, port string) {
+	if addr == nil {
+		return "", ""
+	}
+	ip, port, _ = net.SplitHostPort(addr.String())
+	return
+}
+
+type seconds time.Duration
+
+func (s seconds) String() string {
+	return strconv.FormatFloat(time.Duration(s).Seconds(), 'f', 6, 64)
+}
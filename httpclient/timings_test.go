@@ -0,0 +1,86 @@
+// Copyright 2026 The Joe-cli Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package httpclient_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Timings", func() {
+
+	var server *httptest.Server
+
+	BeforeEach(func() {
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/redirect" {
+				http.Redirect(w, r, "/final", http.StatusFound)
+				return
+			}
+			_, _ = w.Write([]byte("hello"))
+		}))
+		DeferCleanup(server.Close)
+	})
+
+	writeOut := func(expr string, args ...string) []string {
+		out, err := runClient(append([]string{"--output", "/dev/null", "--write-out", expr + "%(newline)"}, args...)...)
+		Expect(err).NotTo(HaveOccurred())
+		return strings.Split(strings.TrimSpace(out), "\n")
+	}
+
+	seconds := func(s string) float64 {
+		f, err := strconv.ParseFloat(s, 64)
+		Expect(err).NotTo(HaveOccurred())
+		return f
+	}
+
+	It("writes out after the response body", func() {
+		out, err := runClient("--write-out", "%(newline)%(size.download)", server.URL)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(Equal("hello\n5"))
+	})
+
+	It("provides sizes of the response", func() {
+		lines := writeOut("%(size.download) %(size.upload) %(speed.download)", "--body", "abc", server.URL)
+
+		Expect(lines).To(HaveLen(1))
+		fields := strings.Fields(lines[0])
+		Expect(fields[0]).To(Equal("5"))
+		Expect(fields[1]).To(Equal("3"))
+		Expect(strconv.Atoi(fields[2])).To(BeNumerically(">", 0))
+	})
+
+	It("provides times in increasing order", func() {
+		lines := writeOut("%(time.namelookup) %(time.connect) %(time.pretransfer) %(time.starttransfer) %(time.total)", server.URL)
+
+		fields := strings.Fields(lines[0])
+		Expect(seconds(fields[0])).To(BeZero(), "no DNS lookup for IP address")
+		Expect(seconds(fields[1])).To(BeNumerically(">", 0))
+		Expect(seconds(fields[2])).To(BeNumerically(">=", seconds(fields[1])))
+		Expect(seconds(fields[3])).To(BeNumerically(">=", seconds(fields[2])))
+		Expect(seconds(fields[4])).To(BeNumerically(">=", seconds(fields[3])))
+	})
+
+	It("provides times for each redirect hop", func() {
+		lines := writeOut("%(redirect.location.path) %(size.upload) %(time.redirect) %(time.total)", "--body", "abc", server.URL+"/redirect")
+
+		Expect(lines).To(HaveLen(2))
+		hop, final := strings.Fields(lines[0]), strings.Fields(lines[1])
+
+		Expect(hop[0]).To(Equal("/final"))
+		Expect(hop[1]).To(Equal("3"))
+		Expect(seconds(hop[2])).To(BeZero())
+
+		Expect(final).To(HaveLen(3), "no redirect location on the final response")
+		Expect(final[0]).To(Equal("0"))
+		Expect(seconds(final[1])).To(BeNumerically(">", 0))
+		Expect(seconds(final[2])).To(BeNumerically(">=", seconds(final[1])))
+	})
+})
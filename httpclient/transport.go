@@ -35,7 +35,7 @@ func WithTransportFactory(fn func(context.Context) (http.RoundTripper, error)) O
 }
 
 // WithDefaultTransportFactory sets up the default transport factory and built-in
-// transport middleware (TLS config and trace level).  This option is applied
+// transport middleware (TLS config, proxy, and trace level).  This option is applied
 // automatically by New.
 func WithDefaultTransportFactory() Option {
 	return func(c *Client) {
@@ -43,6 +43,7 @@ func WithDefaultTransportFactory() Option {
 		c.transport.middleware = append(
 			[]func(context.Context, http.RoundTripper) http.RoundTripper{
 				c.setupTLSConfigTransport,
+				c.setupProxyTransport,
 				c.setupTraceLevelTransport,
 			},
 			c.transport.middleware...,
@@ -52,8 +53,12 @@ func WithDefaultTransportFactory() Option {
 
 func (c *Client) defaultTransportFactory(_ context.Context) (http.RoundTripper, error) {
 	defaultTransport := http.DefaultTransport.(*http.Transport).Clone()
-	defaultTransport.DialContext = c.dialer.DialContext
-	defaultTransport.Proxy = http.ProxyFromEnvironment
+	defaultTransport.DialContext = c.dialContext
+	defaultTransport.Proxy = c.proxyFunc
+	if c.benchmark.enabled {
+		// Allow each benchmark worker to keep its connection alive
+		defaultTransport.MaxIdleConnsPerHost = c.benchmark.withDefaults().Concurrency
+	}
 	return defaultTransport, nil
 }
 
@@ -62,6 +67,15 @@ func (c *Client) NewTransport(ctx context.Context) (http.RoundTripper, error) {
 	return c.transport.New(ctx)
 }
 
+// CloseIdleConnections closes the connections of the transport which are idle.
+// Connections which are in use are not interrupted.
+func (c *Client) CloseIdleConnections() {
+	closeIdleConnections(c.transport.cached)
+	if c.dns.doh != nil {
+		c.dns.doh.CloseIdleConnections()
+	}
+}
+
 func (c *Client) AddTransportMiddleware(m TransportMiddleware) {
 	c.transport.middleware = append(c.transport.middleware, m)
 }
@@ -97,3 +111,9 @@ func (c *Client) setupTraceLevelTransport(ctx context.Context, t http.RoundTripp
 		Transport: t,
 	}
 }
+
+func closeIdleConnections(t http.RoundTripper) {
+	if c, ok := t.(interface{ CloseIdleConnections() }); ok {
+		c.CloseIdleConnections()
+	}
+}
@@ -0,0 +1,357 @@
+// Copyright 2026 The Joe-cli Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package httpserver
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// AuthMode specifies how the server authenticates requests
+type AuthMode int
+
+// Authentication modes
+const (
+	// NoAuth allows any request
+	NoAuth AuthMode = iota
+
+	// BasicAuth requires a user name and password using HTTP Basic authentication
+	BasicAuth
+
+	// BearerAuth requires one of the tokens from the token file using the
+	// Bearer authentication scheme
+	BearerAuth
+	maxAuthMode
+)
+
+// AuthOptions configures authentication
+type AuthOptions struct {
+	// Mode specifies the authentication scheme
+	Mode AuthMode `toml:"mode"          json:"mode"`
+
+	// Realm is reported to clients in the WWW-Authenticate header.  When
+	// empty, a default is used.
+	Realm string `toml:"realm"         json:"realm,omitempty"`
+
+	// Users is a map from user names to passwords which are allowed with
+	// BasicAuth
+	Users map[string]string `toml:"users"         json:"users,omitempty"`
+
+	// HTPasswdFile is the path to an htpasswd file which contains users
+	// allowed with BasicAuth
+	HTPasswdFile string `toml:"htpasswd-file" json:"htpasswdFile,omitempty"`
+
+	// TokenFile is the path to a file which contains the tokens allowed with
+	// BearerAuth, one per line.  A line can contain a name after the token
+	// separated by whitespace, which identifies the user in the access log.
+	// Blank lines and lines that start with # are ignored.
+	TokenFile string `toml:"token-file"    json:"tokenFile,omitempty"`
+}
+
+// requestInfo contains information recorded about the request by handlers
+// so that it can be written to the access log
+type requestInfo struct {
+	user string
+}
+
+type authenticator struct {
+	mode      AuthMode
+	challenge string
+	passwords []PasswordVerifier
+	tokens    []bearerToken
+}
+
+type bearerToken struct {
+	token, name string
+}
+
+const (
+	requestInfoKey contextKey = "httpserver_requestInfo"
+
+	defaultAuthRealm = "weave"
+)
+
+// Names of the virtual path options which apply authentication to a handler
+const (
+	authOption         = "auth"
+	authRealmOption    = "auth_realm"
+	authUserOption     = "auth_user"
+	authHTPasswdOption = "auth_htpasswd"
+	authTokensOption   = "auth_tokens"
+)
+
+var authModeStrings = [...]string{
+	"none",
+	"basic",
+	"bearer",
+}
+
+// NewAuthMiddleware provides handler middleware which requires requests to be
+// authenticated.  The files named in the options are loaded immediately.
+// Requests which fail authentication receive 401 Unauthorized.  The name of the
+// user is available in the access log as %(user).
+func NewAuthMiddleware(opts AuthOptions) (MiddlewareFunc, error) {
+	a, err := newAuthenticator(opts)
+	if err != nil {
+		return nil, err
+	}
+	if a == nil {
+		return func(next http.Handler) http.Handler {
+			return next
+		}, nil
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r = withRequestInfo(r)
+			user, ok := a.authenticate(r)
+			setRequestUser(r, user)
+			if !ok {
+				w.Header().Set("WWW-Authenticate", a.challenge)
+				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}, nil
+}
+
+// RequestUser gets the name of the user which was authenticated for the
+// request, or empty string if none
+func RequestUser(r *http.Request) string {
+	if info, ok := r.Context().Value(requestInfoKey).(*requestInfo); ok {
+		return info.user
+	}
+	return ""
+}
+
+func setRequestUser(r *http.Request, user string) {
+	if info, ok := r.Context().Value(requestInfoKey).(*requestInfo); ok {
+		info.user = user
+	}
+}
+
+func withRequestInfo(r *http.Request) *http.Request {
+	if _, ok := r.Context().Value(requestInfoKey).(*requestInfo); ok {
+		return r
+	}
+	return r.WithContext(context.WithValue(r.Context(), requestInfoKey, new(requestInfo)))
+}
+
+func newAuthenticator(opts AuthOptions) (*authenticator, error) {
+	realm := opts.Realm
+	if realm == "" {
+		realm = defaultAuthRealm
+	}
+
+	switch opts.Mode {
+	case NoAuth:
+		return nil, nil
+
+	case BasicAuth:
+		a := &authenticator{
+			mode:      BasicAuth,
+			challenge: fmt.Sprintf("Basic realm=%q, charset=\"UTF-8\"", realm),
+		}
+		if len(opts.Users) > 0 {
+			a.passwords = append(a.passwords, StaticPasswords(opts.Users))
+		}
+		if opts.HTPasswdFile != "" {
+			htpasswd, err := LoadHTPasswd(opts.HTPasswdFile)
+			if err != nil {
+				return nil, err
+			}
+			a.passwords = append(a.passwords, htpasswd)
+		}
+		if len(a.passwords) == 0 {
+			return nil, errors.New("basic authentication requires users or an htpasswd file")
+		}
+		return a, nil
+
+	case BearerAuth:
+		if opts.TokenFile == "" {
+			return nil, errors.New("bearer authentication requires a token file")
+		}
+		tokens, err := loadTokenFile(opts.TokenFile)
+		if err != nil {
+			return nil, err
+		}
+		return &authenticator{
+			mode:      BearerAuth,
+			challenge: fmt.Sprintf("Bearer realm=%q", realm),
+			tokens:    tokens,
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown authentication mode %v", opts.Mode)
+}
+
+func (a *authenticator) authenticate(r *http.Request) (string, bool) {
+	if a.mode == BasicAuth {
+		user, password, ok := r.BasicAuth()
+		if !ok {
+			return "", false
+		}
+		for _, p := range a.passwords {
+			if p.VerifyPassword(user, password) {
+				return user, true
+			}
+		}
+		return user, false
+	}
+
+	scheme, token, _ := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+
+	var (
+		name  string
+		found bool
+	)
+	// Compare every token so that timing doesn't disclose which one matched
+	for _, t := range a.tokens {
+		if secureEqual(t.token, token) {
+			name, found = t.name, true
+		}
+	}
+	return name, found
+}
+
+func loadTokenFile(filename string) ([]bearerToken, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var res []bearerToken
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		t := bearerToken{token: fields[0]}
+		if len(fields) > 1 {
+			t.name = fields[1]
+		}
+		res = append(res, t)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(res) == 0 {
+		return nil, fmt.Errorf("%s: no tokens", filename)
+	}
+	return res, nil
+}
+
+// pathAuthOptions removes the authentication options from the options of a
+// virtual path.  The options that were removed are returned, or nil if the
+// virtual path doesn't use authentication.
+func pathAuthOptions(opts map[string]string) (map[string]string, *AuthOptions, error) {
+	var (
+		auth   AuthOptions
+		found  bool
+		result = map[string]string{}
+	)
+	for k, v := range opts {
+		switch k {
+		case authOption:
+			if err := auth.Mode.Set(v); err != nil {
+				return nil, nil, err
+			}
+		case authRealmOption:
+			auth.Realm = v
+		case authUserOption:
+			user, password, err := splitUserPassword(v)
+			if err != nil {
+				return nil, nil, err
+			}
+			auth.Users = map[string]string{user: password}
+		case authHTPasswdOption:
+			auth.HTPasswdFile = v
+		case authTokensOption:
+			auth.TokenFile = v
+		default:
+			result[k] = v
+			continue
+		}
+		found = true
+	}
+	if !found {
+		return opts, nil, nil
+	}
+	auth.impliedMode()
+	return result, &auth, nil
+}
+
+// impliedMode sets the mode when it was not set using the settings that
+// are present
+func (o *AuthOptions) impliedMode() {
+	if o.Mode != NoAuth {
+		return
+	}
+	switch {
+	case len(o.Users) > 0, o.HTPasswdFile != "":
+		o.Mode = BasicAuth
+	case o.TokenFile != "":
+		o.Mode = BearerAuth
+	}
+}
+
+func (o *AuthOptions) addUser(user, password string) {
+	users := map[string]string{}
+	maps.Copy(users, o.Users)
+	users[user] = password
+	o.Users = users
+}
+
+func splitUserPassword(s string) (string, string, error) {
+	user, password, ok := strings.Cut(s, ":")
+	if !ok || user == "" {
+		return "", "", fmt.Errorf("expected USER:PASSWORD")
+	}
+	return user, password, nil
+}
+
+func (m AuthMode) String() string {
+	if m >= 0 && m < maxAuthMode {
+		return authModeStrings[int(m)]
+	}
+	return ""
+}
+
+// MarshalText provides the textual representation
+func (m AuthMode) MarshalText() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalText converts the textual representation
+func (m *AuthMode) UnmarshalText(b []byte) error {
+	return m.Set(string(b))
+}
+
+// Set sets the mode from its name
+func (m *AuthMode) Set(arg string) error {
+	for i, s := range authModeStrings {
+		if strings.TrimSpace(arg) == s {
+			*m = AuthMode(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown authentication mode %q", arg)
+}
+
+func (*AuthMode) Synopsis() string {
+	return "{none|basic|bearer}"
+}
@@ -0,0 +1,214 @@
+// Copyright 2026 The Joe-cli Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package httpserver_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/Carbonfrost/joe-cli"
+	"github.com/Carbonfrost/joe-cli-http/httpclient"
+	"github.com/Carbonfrost/joe-cli-http/httpserver"
+	"golang.org/x/crypto/bcrypt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("NewAuthMiddleware", func() {
+
+	var dir string
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+	})
+
+	writeFile := func(name, content string) string {
+		filename := filepath.Join(dir, name)
+		Expect(os.WriteFile(filename, []byte(content), 0600)).To(Succeed())
+		return filename
+	}
+
+	newHandler := func(opts httpserver.AuthOptions) http.Handler {
+		m, err := httpserver.NewAuthMiddleware(opts)
+		Expect(err).NotTo(HaveOccurred())
+		return m(httpserver.NewPingHandler())
+	}
+
+	basicRequest := func(user, password string) *http.Request {
+		req := newRequest("GET", "/")
+		req.SetBasicAuth(user, password)
+		return req
+	}
+
+	bearerRequest := func(token string) *http.Request {
+		return newRequest("GET", "/", "Authorization", "Bearer "+token)
+	}
+
+	Context("with basic authentication", func() {
+
+		opts := httpserver.AuthOptions{
+			Mode:  httpserver.BasicAuth,
+			Users: map[string]string{"alice": "secret"},
+		}
+
+		It("allows the user", func() {
+			recorder := serveRequest(newHandler(opts), basicRequest("alice", "secret"))
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Body.String()).To(Equal("ping\n"))
+		})
+
+		DescribeTable("rejects requests", func(req *http.Request) {
+			recorder := serveRequest(newHandler(opts), req)
+			Expect(recorder.Code).To(Equal(http.StatusUnauthorized))
+			Expect(recorder.Header().Get("WWW-Authenticate")).To(Equal(`Basic realm="weave", charset="UTF-8"`))
+		},
+			Entry("wrong password", basicRequest("alice", "wrong")),
+			Entry("unknown user", basicRequest("bob", "secret")),
+			Entry("no credentials", newRequest("GET", "/")),
+		)
+
+		It("uses the realm", func() {
+			opts := opts
+			opts.Realm = "staging"
+			recorder := serveRequest(newHandler(opts), newRequest("GET", "/"))
+			Expect(recorder.Header().Get("WWW-Authenticate")).To(HavePrefix(`Basic realm="staging"`))
+		})
+
+		It("requires users", func() {
+			_, err := httpserver.NewAuthMiddleware(httpserver.AuthOptions{Mode: httpserver.BasicAuth})
+			Expect(err).To(MatchError("basic authentication requires users or an htpasswd file"))
+		})
+	})
+
+	Context("with an htpasswd file", func() {
+
+		var opts httpserver.AuthOptions
+
+		BeforeEach(func() {
+			hash, _ := bcrypt.GenerateFromPassword([]byte("bcrypt-secret"), bcrypt.MinCost)
+			opts = httpserver.AuthOptions{
+				Mode: httpserver.BasicAuth,
+				HTPasswdFile: writeFile(".htpasswd", "# users\n"+
+					"bcrypt:"+string(hash)+"\n"+
+					"sha:{SHA}5en6G6MezRroT3XKqkdPOmY/BfQ=\n"+
+					"apr1:$apr1$r31.....$G/cElGhD0cboYkZN5h5Ne/\n"+
+					"apr1-long:$apr1$abcdefgh$gu3xk7QF.n.O7A8TnMJgI/\n"),
+			}
+		})
+
+		DescribeTable("verifies hashes", func(user, password string) {
+			Expect(serveRequest(newHandler(opts), basicRequest(user, password)).Code).To(Equal(http.StatusOK))
+			Expect(serveRequest(newHandler(opts), basicRequest(user, password+"x")).Code).To(Equal(http.StatusUnauthorized))
+		},
+			Entry("bcrypt", "bcrypt", "bcrypt-secret"),
+			Entry("SHA", "sha", "secret"),
+			Entry("APR1", "apr1", "secret"),
+			Entry("APR1 long password", "apr1-long", "a-much-longer-password-here"),
+		)
+
+		It("reports unsupported hashes", func() {
+			opts.HTPasswdFile = writeFile("bad", "crypt:rl0uE7q8VqJxY\n")
+			_, err := httpserver.NewAuthMiddleware(opts)
+			Expect(err).To(MatchError(ContainSubstring(`:1: unsupported password hash for user "crypt"`)))
+		})
+	})
+
+	Context("with bearer authentication", func() {
+
+		var opts httpserver.AuthOptions
+
+		BeforeEach(func() {
+			opts = httpserver.AuthOptions{
+				Mode:      httpserver.BearerAuth,
+				TokenFile: writeFile("tokens", "# tokens\n\ntoken-1 ci\ntoken-2\n"),
+			}
+		})
+
+		It("allows the tokens", func() {
+			Expect(serveRequest(newHandler(opts), bearerRequest("token-1")).Code).To(Equal(http.StatusOK))
+			Expect(serveRequest(newHandler(opts), bearerRequest("token-2")).Code).To(Equal(http.StatusOK))
+		})
+
+		It("rejects other tokens", func() {
+			recorder := serveRequest(newHandler(opts), bearerRequest("token-3"))
+			Expect(recorder.Code).To(Equal(http.StatusUnauthorized))
+			Expect(recorder.Header().Get("WWW-Authenticate")).To(Equal(`Bearer realm="weave"`))
+		})
+
+		It("rejects basic credentials", func() {
+			Expect(serveRequest(newHandler(opts), basicRequest("token-1", "")).Code).To(Equal(http.StatusUnauthorized))
+		})
+	})
+
+	Describe("access log", func() {
+
+		var output bytes.Buffer
+
+		log := func(opts httpserver.AuthOptions, req *http.Request) string {
+			output.Reset()
+			serveRequest(httpserver.NewRequestLogger("%(user) %(status)", &output, newHandler(opts)), req)
+			return removeANSICodes(output.String())
+		}
+
+		It("contains the user", func() {
+			opts := httpserver.AuthOptions{Mode: httpserver.BasicAuth, Users: map[string]string{"alice": "secret"}}
+			Expect(log(opts, basicRequest("alice", "secret"))).To(Equal("alice 200 OK"))
+		})
+
+		It("contains the user which failed", func() {
+			opts := httpserver.AuthOptions{Mode: httpserver.BasicAuth, Users: map[string]string{"alice": "secret"}}
+			Expect(log(opts, basicRequest("alice", "wrong"))).To(Equal("alice 401 Unauthorized"))
+		})
+
+		It("contains the name of the token", func() {
+			opts := httpserver.AuthOptions{Mode: httpserver.BearerAuth, TokenFile: writeFile("tokens", "token-1 ci\n")}
+			Expect(log(opts, bearerRequest("token-1"))).To(Equal("ci 200 OK"))
+		})
+
+		It("uses a dash when there is no user", func() {
+			Expect(log(httpserver.AuthOptions{}, newRequest("GET", "/"))).To(Equal("- 200 OK"))
+		})
+	})
+})
+
+var _ = Describe("HandleSpec", func() {
+
+	It("applies authentication from the virtual path options", func() {
+		var (
+			received map[string]string
+			srv      = httpserver.New(httpserver.WithNoAccessLog())
+			vpath    = httpclient.VirtualPath{
+				RequestPath:  "/private/",
+				PhysicalPath: "ping",
+				Options: map[string]string{
+					"auth_user": "alice:secret",
+					"greeting":  "hello",
+				},
+			}
+			spec = func(_ context.Context, vp httpclient.VirtualPath) (http.Handler, error) {
+				received = vp.Options
+				return httpserver.NewPingHandler(), nil
+			}
+		)
+
+		app := &cli.App{
+			Name:   "app",
+			Uses:   httpserver.ContextValue(srv),
+			Action: httpserver.HandleSpec(vpath, spec),
+		}
+		Expect(app.RunContext(context.Background(), []string{"app"})).To(Succeed())
+		Expect(received).To(Equal(map[string]string{"greeting": "hello"}))
+
+		Expect(serveRequest(srv.Handler, newRequest("GET", "/private/")).Code).To(Equal(http.StatusUnauthorized))
+
+		req := newRequest("GET", "/private/")
+		req.SetBasicAuth("alice", "secret")
+		Expect(serveRequest(srv.Handler, req).Code).To(Equal(http.StatusOK))
+	})
+})
@@ -0,0 +1,332 @@
+// Copyright 2026 The Joe-cli Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package httpserver
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Carbonfrost/joe-cli-http/httpclient"
+)
+
+// CaptureOptions configures the capture handler
+type CaptureOptions struct {
+	// Size is the number of requests that are kept.  When zero, a default of
+	// 100 is used.
+	Size int
+
+	// MaxBody is the largest request body that is kept.  Longer bodies are
+	// truncated.  When zero, a default of 1 MiB is used.
+	MaxBody int
+}
+
+// CaptureStore keeps the most recent requests that were captured
+type CaptureStore struct {
+	size    int
+	maxBody int
+
+	mu          sync.Mutex
+	nextID      int
+	requests    []*capturedRequest // ring buffer where head is the oldest when full
+	head        int
+	subscribers map[chan *capturedRequest]struct{}
+	done        chan struct{}
+	stop        sync.Once
+}
+
+type capturedRequest struct {
+	ID        int           `json:"id"`
+	Time      time.Time     `json:"time"`
+	Duration  time.Duration `json:"duration"`
+	Content   string        `json:"content,omitempty"`
+	Encoding  string        `json:"contentEncoding,omitempty"`
+	Truncated bool          `json:"contentTruncated,omitempty"`
+
+	reflectedRequest
+}
+
+const (
+	defaultCaptureSize    = 100
+	defaultCaptureMaxBody = 1 << 20
+
+	captureSubscriberBuffer = 16
+)
+
+var (
+	//go:embed capture.html
+	captureHTML string
+
+	captureTemplate = template.Must(template.New("capture").Funcs(template.FuncMap{
+		"json": func(v any) (string, error) {
+			data, err := json.MarshalIndent(v, "", "  ")
+			return string(data), err
+		},
+	}).Parse(captureHTML))
+)
+
+// CaptureHandlerSpec creates a handler which captures the requests that it
+// receives so that they can be inspected.  The inspector is registered on the
+// server at a sibling path, which by default is the request path with the suffix
+// -inspect, as in /hook-inspect/ for /hook/.  The options size, max_body, and
+// inspect set the number of requests kept, the largest body kept, and the path
+// of the inspector.  When the handler is created again, such as when the
+// configuration is reloaded, the store for the inspector path is reused.
+func CaptureHandlerSpec() HandlerSpec {
+	return func(ctx context.Context, vp httpclient.VirtualPath) (http.Handler, error) {
+		var opts CaptureOptions
+		for k, v := range vp.Options {
+			var err error
+			switch k {
+			case "size":
+				opts.Size, err = strconv.Atoi(v)
+			case "max_body":
+				opts.MaxBody, err = strconv.Atoi(v)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", k, err)
+			}
+		}
+
+		inspect := vp.Options["inspect"]
+		if inspect == "" {
+			inspect = captureInspectPath(vp.RequestPath)
+		}
+
+		srv, ok := ctx.Value(servicesKey).(*Server)
+		if !ok {
+			return nil, fmt.Errorf("capture handler requires a server in the context")
+		}
+		return srv.captureStore(inspect, opts)
+	}
+}
+
+// captureStore gets the store whose inspector is at the path, registering it
+// on the server the first time
+func (s *Server) captureStore(inspect string, opts CaptureOptions) (*CaptureStore, error) {
+	s.captures.mu.Lock()
+	defer s.captures.mu.Unlock()
+
+	if store, ok := s.captures.stores[inspect]; ok {
+		return store, nil
+	}
+
+	store := NewCaptureStore(opts)
+	if err := s.Handle(inspect, http.StripPrefix(strings.TrimSuffix(inspect, "/"), store.Inspector())); err != nil {
+		return nil, err
+	}
+	s.Server.RegisterOnShutdown(store.Close)
+
+	if s.captures.stores == nil {
+		s.captures.stores = map[string]*CaptureStore{}
+	}
+	s.captures.stores[inspect] = store
+	return store, nil
+}
+
+// NewCaptureStore creates a handler which captures the requests that it
+// receives.  It responds to each with the ID of the request that was captured.
+func NewCaptureStore(opts CaptureOptions) *CaptureStore {
+	size := opts.Size
+	if size <= 0 {
+		size = defaultCaptureSize
+	}
+	maxBody := opts.MaxBody
+	if maxBody <= 0 {
+		maxBody = defaultCaptureMaxBody
+	}
+	return &CaptureStore{
+		size:        size,
+		maxBody:     maxBody,
+		nextID:      1,
+		subscribers: map[chan *capturedRequest]struct{}{},
+		done:        make(chan struct{}),
+	}
+}
+
+func (s *CaptureStore) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	body, truncated, err := readCaptureBody(r.Body, s.maxBody)
+
+	r2 := new(http.Request)
+	*r2 = *r
+	r2.Body = io.NopCloser(bytes.NewReader(body))
+
+	c := &capturedRequest{
+		Time:             start,
+		reflectedRequest: newReflectedRequest(r2),
+		Truncated:        truncated,
+	}
+	c.Headers = r.Header.Clone()
+	if err != nil {
+		c.Errors = append(c.Errors, fmt.Sprintf("read body error: %v", err))
+	}
+	if utf8.Valid(body) {
+		c.Content = string(body)
+	} else {
+		c.Content = base64.StdEncoding.EncodeToString(body)
+		c.Encoding = "base64"
+	}
+	c.Duration = time.Since(start)
+
+	id := s.add(c)
+	writeJSON(w, http.StatusOK, map[string]int{"id": id})
+}
+
+// Inspector provides the handler which shows the requests that were captured.
+// Its root is an HTML page, /requests lists the requests as JSON, and /events
+// is a stream of server-sent events for the requests as they arrive.  Sending
+// DELETE to /requests clears them.
+func (s *CaptureStore) Inspector() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", s.servePage)
+	mux.HandleFunc("GET /requests", s.serveRequests)
+	mux.HandleFunc("DELETE /requests", s.clear)
+	mux.HandleFunc("GET /requests/{id}", s.serveRequest)
+	mux.HandleFunc("GET /events", s.serveEvents)
+	return mux
+}
+
+// Close disconnects the clients of the events stream
+func (s *CaptureStore) Close() {
+	s.stop.Do(func() {
+		close(s.done)
+	})
+}
+
+func (s *CaptureStore) add(c *capturedRequest) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c.ID = s.nextID
+	s.nextID++
+	if len(s.requests) < s.size {
+		s.requests = append(s.requests, c)
+	} else {
+		s.requests[s.head] = c
+		s.head = (s.head + 1) % s.size
+	}
+
+	for ch := range s.subscribers {
+		select {
+		case ch <- c:
+		default:
+			// The subscriber isn't keeping up, so the event is dropped
+		}
+	}
+	return c.ID
+}
+
+// snapshot gets the requests, newest first
+func (s *CaptureStore) snapshot() []*capturedRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := len(s.requests)
+	result := make([]*capturedRequest, n)
+	for i := range result {
+		result[i] = s.requests[(s.head-1-i+n)%n]
+	}
+	return result
+}
+
+func (s *CaptureStore) subscribe() chan *capturedRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ch := make(chan *capturedRequest, captureSubscriberBuffer)
+	s.subscribers[ch] = struct{}{}
+	return ch
+}
+
+func (s *CaptureStore) unsubscribe(ch chan *capturedRequest) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.subscribers, ch)
+}
+
+func (s *CaptureStore) servePage(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	captureTemplate.Execute(w, s.snapshot())
+}
+
+func (s *CaptureStore) serveRequests(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, s.snapshot())
+}
+
+func (s *CaptureStore) serveRequest(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(r.PathValue("id"))
+	for _, c := range s.snapshot() {
+		if c.ID == id {
+			writeJSON(w, http.StatusOK, c)
+			return
+		}
+	}
+	http.NotFound(w, r)
+}
+
+func (s *CaptureStore) clear(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	s.requests = nil
+	s.head = 0
+	s.mu.Unlock()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *CaptureStore) serveEvents(w http.ResponseWriter, r *http.Request) {
+	ch := s.subscribe()
+	defer s.unsubscribe(ch)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	rc := http.NewResponseController(w)
+	rc.Flush()
+	for {
+		select {
+		case c := <-ch:
+			data, _ := json.Marshal(c)
+			fmt.Fprintf(w, "id: %d\nevent: request\ndata: %s\n\n", c.ID, data)
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		case <-r.Context().Done():
+			return
+		case <-s.done:
+			return
+		}
+	}
+}
+
+func captureInspectPath(requestPath string) string {
+	base := strings.TrimSuffix(requestPath, "/")
+	if base == "" {
+		return "/inspect/"
+	}
+	return base + "-inspect/"
+}
+
+func readCaptureBody(body io.Reader, limit int) ([]byte, bool, error) {
+	if body == nil {
+		return nil, false, nil
+	}
+	data, err := io.ReadAll(io.LimitReader(body, int64(limit)+1))
+	if len(data) > limit {
+		return data[:limit], true, err
+	}
+	return data, false, err
+}
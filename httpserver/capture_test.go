@@ -0,0 +1,194 @@
+// Copyright 2026 The Joe-cli Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package httpserver_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/Carbonfrost/joe-cli"
+	"github.com/Carbonfrost/joe-cli-http/httpclient"
+	"github.com/Carbonfrost/joe-cli-http/httpserver"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gstruct"
+)
+
+var _ = Describe("CaptureStore", func() {
+
+	var (
+		store     *httpserver.CaptureStore
+		inspector http.Handler
+	)
+
+	BeforeEach(func() {
+		store = httpserver.NewCaptureStore(httpserver.CaptureOptions{Size: 2, MaxBody: 8})
+		inspector = store.Inspector()
+	})
+
+	capture := func(body string) *httptest.ResponseRecorder {
+		return serveRequest(store, newBodyRequest("POST", "/hook?x=1", strings.NewReader(body), "X-Delivery", "abc"))
+	}
+
+	inspect := func(method, path string) *httptest.ResponseRecorder {
+		return serveRequest(inspector, newRequest(method, path))
+	}
+
+	requests := func() []map[string]any {
+		var result []map[string]any
+		Expect(json.Unmarshal(inspect("GET", "/requests").Body.Bytes(), &result)).To(Succeed())
+		return result
+	}
+
+	It("responds with the ID of the request", func() {
+		Expect(capture("hello").Body.String()).To(MatchJSON(`{"id": 1}`))
+		Expect(capture("hello").Body.String()).To(MatchJSON(`{"id": 2}`))
+	})
+
+	It("lists the requests as JSON, newest first", func() {
+		capture("first")
+		capture("second")
+
+		Expect(requests()).To(HaveExactElements(
+			MatchKeys(IgnoreExtras, Keys{
+				"id":      BeEquivalentTo(2),
+				"method":  Equal("POST"),
+				"url":     Equal("/hook?x=1"),
+				"content": Equal("second"),
+				"headers": HaveKeyWithValue("X-Delivery", ConsistOf("abc")),
+			}),
+			MatchKeys(IgnoreExtras, Keys{
+				"id":      BeEquivalentTo(1),
+				"content": Equal("first"),
+			}),
+		))
+	})
+
+	It("keeps only the most recent requests", func() {
+		capture("a")
+		capture("b")
+		capture("c")
+
+		Expect(requests()).To(HaveExactElements(
+			HaveKeyWithValue("content", "c"),
+			HaveKeyWithValue("content", "b"),
+		))
+	})
+
+	It("truncates the body", func() {
+		capture("0123456789")
+		Expect(requests()).To(ConsistOf(MatchKeys(IgnoreExtras, Keys{
+			"content":          Equal("01234567"),
+			"contentTruncated": BeTrue(),
+		})))
+	})
+
+	It("gets a request by ID", func() {
+		capture("a")
+		Expect(inspect("GET", "/requests/1").Body.String()).To(ContainSubstring(`"content": "a"`))
+		Expect(inspect("GET", "/requests/2").Code).To(Equal(http.StatusNotFound))
+	})
+
+	It("clears the requests", func() {
+		capture("a")
+		Expect(inspect("DELETE", "/requests").Code).To(Equal(http.StatusNoContent))
+		Expect(requests()).To(BeEmpty())
+	})
+
+	It("shows the requests on the HTML page", func() {
+		capture("a")
+		recorder := inspect("GET", "/")
+		Expect(recorder.Header().Get("Content-Type")).To(HavePrefix("text/html"))
+		Expect(recorder.Body.String()).To(ContainSubstring("#1"))
+	})
+
+	It("streams new requests as events", func() {
+		server := httptest.NewServer(inspector)
+		defer server.Close()
+
+		resp, err := http.Get(server.URL + "/events")
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.Body.Close()
+		Expect(resp.Header.Get("Content-Type")).To(Equal("text/event-stream"))
+
+		capture("hello")
+
+		scanner := bufio.NewScanner(resp.Body)
+		var lines []string
+		for scanner.Scan() && scanner.Text() != "" {
+			lines = append(lines, scanner.Text())
+		}
+		Expect(lines).To(HaveExactElements(
+			"id: 1",
+			"event: request",
+			And(HavePrefix("data: "), ContainSubstring(`"content":"hello"`)),
+		))
+	})
+
+	It("ends the events stream when closed", func() {
+		server := httptest.NewServer(inspector)
+		defer server.Close()
+
+		resp, err := http.Get(server.URL + "/events")
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.Body.Close()
+
+		store.Close()
+		Expect(io.ReadAll(resp.Body)).To(BeEmpty())
+	})
+})
+
+var _ = Describe("CaptureHandlerSpec", func() {
+
+	It("registers the inspector at the sibling path", func() {
+		srv := httpserver.New(httpserver.WithNoAccessLog())
+		app := &cli.App{
+			Name: "app",
+			Uses: httpserver.ContextValue(srv),
+			Action: httpserver.HandleSpec(
+				httpclient.VirtualPath{RequestPath: "/hook/"},
+				httpserver.CaptureHandlerSpec(),
+			),
+		}
+		Expect(app.RunContext(context.Background(), []string{"app"})).To(Succeed())
+
+		serveRequest(srv.Handler, newRequest("POST", "/hook/github"))
+
+		recorder := serveRequest(srv.Handler, newRequest("GET", "/hook-inspect/requests"))
+		Expect(recorder.Body.String()).To(ContainSubstring(`"url": "/hook/github"`))
+	})
+
+	It("reuses the store when the handler is created again", func() {
+		var handlers []http.Handler
+		srv := httpserver.New(httpserver.WithNoAccessLog())
+		app := &cli.App{
+			Name: "app",
+			Uses: httpserver.ContextValue(srv),
+			Action: func(c *cli.Context) error {
+				for range 2 {
+					h, err := httpserver.CaptureHandlerSpec()(c, httpclient.VirtualPath{RequestPath: "/hook/"})
+					if err != nil {
+						return err
+					}
+					handlers = append(handlers, h)
+				}
+				return nil
+			},
+		}
+		Expect(app.RunContext(context.Background(), []string{"app"})).To(Succeed())
+		Expect(handlers[1]).To(BeIdenticalTo(handlers[0]))
+	})
+
+	It("returns an error when there is no server", func() {
+		_, err := httpserver.CaptureHandlerSpec()(context.Background(), httpclient.VirtualPath{RequestPath: "/hook/"})
+		Expect(err).To(MatchError("capture handler requires a server in the context"))
+	})
+})
@@ -0,0 +1,346 @@
+// Copyright 2026 The Joe-cli Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package httpserver
+
+import (
+	"compress/gzip"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+// CompressionOptions configures response compression
+type CompressionOptions struct {
+	// Encodings lists the content codings to use in order of preference.
+	// The supported codings are zstd, br, and gzip.  When empty, all
+	// of them are used in this order.
+	Encodings []string `toml:"encodings" json:"encodings,omitempty"`
+
+	// MinSize is the minimum size of a response body that is compressed.
+	// When zero, a default of 1 KiB is used.
+	MinSize int `toml:"min-size"  json:"minSize,omitempty"`
+}
+
+const defaultCompressionMinSize = 1024
+
+var (
+	defaultEncodings = []string{"zstd", "br", "gzip"}
+
+	// precompressedExtensions maps content codings to the extension of
+	// precompressed sibling files
+	precompressedExtensions = []struct {
+		encoding, ext string
+	}{
+		{"br", ".br"},
+		{"zstd", ".zst"},
+		{"gzip", ".gz"},
+	}
+
+	incompressibleTypes = []string{
+		"application/gzip",
+		"application/pdf",
+		"application/vnd.rar",
+		"application/x-7z-compressed",
+		"application/x-brotli",
+		"application/x-bzip2",
+		"application/x-gzip",
+		"application/x-rar-compressed",
+		"application/x-xz",
+		"application/zip",
+		"application/zstd",
+		"font/woff",
+		"font/woff2",
+		"text/event-stream",
+	}
+)
+
+// NewCompressionMiddleware provides handler middleware which compresses
+// responses using the content coding negotiated with the Accept-Encoding
+// request header.  Responses are not compressed when they are smaller than
+// the minimum size, already have a content coding, or have a media type which
+// is already compressed such as images, audio, video, and archives.
+func NewCompressionMiddleware(opts CompressionOptions) MiddlewareFunc {
+	encodings := opts.Encodings
+	if len(encodings) == 0 {
+		encodings = defaultEncodings
+	}
+	minSize := opts.MinSize
+	if minSize <= 0 {
+		minSize = defaultCompressionMinSize
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			addVary(w.Header(), "Accept-Encoding")
+
+			encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"), encodings)
+			if encoding == "" || r.Method == http.MethodHead || r.Header.Get("Range") != "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			cw := &compressWriter{
+				ResponseWriter: w,
+				encoding:       encoding,
+				minSize:        minSize,
+			}
+			defer cw.Close()
+			next.ServeHTTP(cw, r)
+		})
+	}
+}
+
+// negotiateEncoding selects the first of the supported encodings that the
+// Accept-Encoding header allows, or empty string if none are allowed
+func negotiateEncoding(accept string, supported []string) string {
+	if accept == "" {
+		return ""
+	}
+
+	weights := map[string]float64{}
+	for part := range strings.SplitSeq(accept, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+		weights[name] = q
+	}
+
+	best, bestQ := "", 0.0
+	for _, enc := range supported {
+		q, ok := weights[enc]
+		if !ok {
+			q, ok = weights["*"]
+		}
+		if ok && q > bestQ {
+			best, bestQ = enc, q
+		}
+	}
+	return best
+}
+
+func newEncoder(encoding string, w io.Writer) io.WriteCloser {
+	switch encoding {
+	case "gzip":
+		return gzip.NewWriter(w)
+	case "br":
+		return brotli.NewWriterLevel(w, brotli.DefaultCompression)
+	case "zstd":
+		enc, _ := zstd.NewWriter(w, zstd.WithEncoderLevel(zstd.SpeedDefault))
+		return enc
+	}
+	return nil
+}
+
+func isCompressible(contentType string) bool {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType == "" {
+		return true
+	}
+	for _, prefix := range []string{"image/", "audio/", "video/"} {
+		if strings.HasPrefix(mediaType, prefix) {
+			return mediaType == "image/svg+xml"
+		}
+	}
+	return !slices.Contains(incompressibleTypes, mediaType)
+}
+
+// compressWriter buffers the start of the response until the minimum size
+// is reached, and then decides whether to compress it
+type compressWriter struct {
+	http.ResponseWriter
+	encoding string
+	minSize  int
+
+	code        int
+	wroteHeader bool
+	decided     bool
+	buf         []byte
+	enc         io.WriteCloser
+}
+
+func (w *compressWriter) WriteHeader(code int) {
+	if code < http.StatusOK {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	w.code = code
+
+	h := w.Header()
+	switch {
+	case code == http.StatusNoContent, code == http.StatusNotModified,
+		code == http.StatusPartialContent,
+		h.Get("Content-Encoding") != "",
+		!isCompressible(h.Get("Content-Type")):
+		w.passThrough()
+		return
+	}
+	if n, err := strconv.Atoi(h.Get("Content-Length")); err == nil && n < w.minSize {
+		w.passThrough()
+	}
+}
+
+func (w *compressWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		if w.Header().Get("Content-Type") == "" {
+			w.Header().Set("Content-Type", http.DetectContentType(b))
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.decided {
+		if w.enc != nil {
+			return w.enc.Write(b)
+		}
+		return w.ResponseWriter.Write(b)
+	}
+
+	w.buf = append(w.buf, b...)
+	if len(w.buf) >= w.minSize {
+		if err := w.startCompression(); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+// Flush sends what has been buffered, compressing it if the response is
+// being compressed.  When nothing has been buffered yet, the response isn't
+// compressed because the headers must be sent before the body is known.
+func (w *compressWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if !w.decided {
+		if len(w.buf) > 0 {
+			_ = w.startCompression()
+		} else {
+			w.passThrough()
+		}
+	}
+	if f, ok := w.enc.(interface{ Flush() error }); ok {
+		_ = f.Flush()
+	}
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Close finishes the response, which writes a buffered body that was too
+// small to compress
+func (w *compressWriter) Close() error {
+	if !w.wroteHeader {
+		return nil
+	}
+	if !w.decided {
+		w.passThrough()
+		return nil
+	}
+	if w.enc != nil {
+		return w.enc.Close()
+	}
+	return nil
+}
+
+func (w *compressWriter) passThrough() {
+	w.decided = true
+	w.ResponseWriter.WriteHeader(w.code)
+	if len(w.buf) > 0 {
+		w.ResponseWriter.Write(w.buf)
+		w.buf = nil
+	}
+}
+
+func (w *compressWriter) startCompression() error {
+	w.decided = true
+
+	h := w.Header()
+	h.Set("Content-Encoding", w.encoding)
+	h.Del("Content-Length")
+	h.Del("Accept-Ranges")
+	if etag := h.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		h.Set("ETag", "W/"+etag)
+	}
+	w.ResponseWriter.WriteHeader(w.code)
+
+	w.enc = newEncoder(w.encoding, w.ResponseWriter)
+	_, err := w.enc.Write(w.buf)
+	w.buf = nil
+	return err
+}
+
+// withPrecompressed provides a handler that serves precompressed siblings of
+// files when they exist, and otherwise uses the next handler
+func withPrecompressed(fsys http.FileSystem, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !servePrecompressed(fsys, w, r) {
+			next.ServeHTTP(w, r)
+		}
+	})
+}
+
+// servePrecompressed serves a precompressed sibling of the requested file,
+// such as app.js.br for app.js, when the client accepts its content coding.
+// It returns false if there is no such sibling.
+func servePrecompressed(fsys http.FileSystem, w http.ResponseWriter, r *http.Request) bool {
+	if (r.Method != http.MethodGet && r.Method != http.MethodHead) || strings.HasSuffix(r.URL.Path, "/") {
+		return false
+	}
+
+	name := path.Clean("/" + r.URL.Path)
+	accept := r.Header.Get("Accept-Encoding")
+	for _, p := range precompressedExtensions {
+		if negotiateEncoding(accept, []string{p.encoding}) == "" {
+			continue
+		}
+		f, err := fsys.Open(name + p.ext)
+		if err != nil {
+			continue
+		}
+		defer f.Close()
+
+		info, err := f.Stat()
+		if err != nil || info.IsDir() {
+			continue
+		}
+
+		h := w.Header()
+		addVary(h, "Accept-Encoding")
+		h.Set("Content-Encoding", p.encoding)
+		if ctype := mime.TypeByExtension(path.Ext(name)); ctype != "" {
+			h.Set("Content-Type", ctype)
+		} else {
+			h.Set("Content-Type", "application/octet-stream")
+		}
+		http.ServeContent(w, r, name, info.ModTime(), f)
+		return true
+	}
+	return false
+}
+
+func addVary(h http.Header, name string) {
+	if !slices.Contains(h.Values("Vary"), name) {
+		h.Add("Vary", name)
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright 2026 The Joe-cli Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package httpserver_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/Carbonfrost/joe-cli-http/httpclient"
+	"github.com/Carbonfrost/joe-cli-http/httpserver"
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("NewCompressionMiddleware", func() {
+
+	body := strings.Repeat("Hello, compression. ", 200)
+
+	handler := func(contentType, content string) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", contentType)
+			w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+			io.WriteString(w, content)
+		})
+	}
+
+	compressed := func(h http.Handler) http.Handler {
+		return httpserver.NewCompressionMiddleware(httpserver.CompressionOptions{})(h)
+	}
+
+	decode := func(encoding string, r io.Reader) string {
+		var (
+			dec io.Reader
+			err error
+		)
+		switch encoding {
+		case "gzip":
+			dec, err = gzip.NewReader(r)
+		case "br":
+			dec = brotli.NewReader(r)
+		case "zstd":
+			dec, err = zstd.NewReader(r)
+		default:
+			dec = r
+		}
+		Expect(err).NotTo(HaveOccurred())
+		data, err := io.ReadAll(dec)
+		Expect(err).NotTo(HaveOccurred())
+		return string(data)
+	}
+
+	DescribeTable("negotiates the encoding", func(acceptEncoding string, expected string) {
+		recorder := serveRequest(compressed(handler("text/plain", body)), newRequest("GET", "/", "Accept-Encoding", acceptEncoding))
+
+		Expect(recorder.Header().Get("Content-Encoding")).To(Equal(expected))
+		Expect(recorder.Header().Values("Vary")).To(ContainElement("Accept-Encoding"))
+		Expect(decode(expected, recorder.Body)).To(Equal(body))
+	},
+		Entry("gzip", "gzip", "gzip"),
+		Entry("brotli", "br", "br"),
+		Entry("zstd", "zstd", "zstd"),
+		Entry("preference of server", "gzip, br, zstd", "zstd"),
+		Entry("quality", "br;q=0.5, gzip", "gzip"),
+		Entry("wildcard", "*", "zstd"),
+		Entry("excluded", "gzip;q=0", ""),
+		Entry("identity", "identity", ""),
+		Entry("none", "", ""),
+	)
+
+	DescribeTable("skips responses", func(h http.Handler) {
+		recorder := serveRequest(compressed(h), newRequest("GET", "/", "Accept-Encoding", "gzip"))
+
+		Expect(recorder.Header().Get("Content-Encoding")).To(BeEmpty())
+		Expect(recorder.Header().Get("Content-Length")).NotTo(BeEmpty())
+	},
+		Entry("small body", handler("text/plain", "small")),
+		Entry("compressed media type", handler("image/png", body)),
+		Entry("archive", handler("application/zip", body)),
+	)
+
+	It("removes the content length when compressing", func() {
+		recorder := serveRequest(compressed(handler("text/plain", body)), newRequest("GET", "/", "Accept-Encoding", "gzip"))
+		Expect(recorder.Header()).NotTo(HaveKey("Content-Length"))
+	})
+
+	It("doesn't compress when flushed before the body", func() {
+		h := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "text/plain")
+			w.WriteHeader(http.StatusOK)
+			http.NewResponseController(w).Flush()
+			io.WriteString(w, body)
+		})
+		resp := serveRequest(compressed(h), newRequest("GET", "/", "Accept-Encoding", "gzip")).Result()
+
+		Expect(resp.Header.Get("Content-Encoding")).To(BeEmpty())
+		Expect(decode("", resp.Body)).To(Equal(body))
+	})
+
+	It("counts the compressed bytes in the access log", func() {
+		var log bytes.Buffer
+		h := httpserver.NewRequestLogger("%(bytesWritten)", &log, compressed(handler("text/plain", body)))
+		recorder := serveRequest(h, newRequest("GET", "/", "Accept-Encoding", "gzip"))
+
+		Expect(log.String()).To(Equal(strconv.Itoa(recorder.Body.Len())))
+		Expect(recorder.Body.Len()).To(BeNumerically("<", len(body)))
+	})
+
+	Describe("precompressed files", func() {
+
+		var dir string
+
+		BeforeEach(func() {
+			dir = GinkgoT().TempDir()
+			Expect(os.WriteFile(filepath.Join(dir, "app.js"), []byte("// app"), 0o644)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(dir, "app.js.gz"), []byte("gzip data"), 0o644)).To(Succeed())
+		})
+
+		DescribeTable("examples", func(acceptEncoding string, expectedEncoding, expectedBody string) {
+			h, err := httpserver.FileServerHandlerSpec()(context.Background(), httpclient.VirtualPath{
+				RequestPath:  "/",
+				PhysicalPath: dir,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			recorder := serveRequest(h, newRequest("GET", "/app.js", "Accept-Encoding", acceptEncoding))
+
+			Expect(recorder.Header().Get("Content-Encoding")).To(Equal(expectedEncoding))
+			Expect(recorder.Header().Get("Content-Type")).To(HavePrefix("text/javascript"))
+			Expect(recorder.Body.String()).To(Equal(expectedBody))
+		},
+			Entry("sibling exists", "gzip, br", "gzip", "gzip data"),
+			Entry("encoding not accepted", "br", "", "// app"),
+		)
+	})
+})
@@ -0,0 +1,363 @@
+// Copyright 2026 The Joe-cli Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package httpserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/Carbonfrost/joe-cli-http/httpclient"
+	"go.yaml.in/yaml/v3"
+)
+
+// Config describes routes that the server handles.  It is typically loaded
+// from a YAML, JSON, or TOML file using LoadConfig.
+type Config struct {
+	Routes []Route `toml:"routes" yaml:"routes" json:"routes"`
+
+	dir string
+}
+
+// Route describes how to respond to requests matching a path.  A route either
+// provides a static response or refers to a provider in the handler registry.
+type Route struct {
+	// Path is the pattern which matches the request path, using the syntax
+	// of http.ServeMux, as in /users/{id}
+	Path string `toml:"path"      yaml:"path"      json:"path"`
+
+	// Method is the method which the route matches.  When both Method and
+	// Methods are empty, any method matches.
+	Method string `toml:"method"    yaml:"method"    json:"method,omitempty"`
+
+	// Methods are the methods which the route matches
+	Methods []string `toml:"methods"   yaml:"methods"   json:"methods,omitempty"`
+
+	// Status is the status code of the response.  The default is 200 OK.
+	Status int `toml:"status"    yaml:"status"    json:"status,omitempty"`
+
+	// Headers are set on the response
+	Headers map[string]string `toml:"headers"   yaml:"headers"   json:"headers,omitempty"`
+
+	// Body is the body of the response
+	Body string `toml:"body"      yaml:"body"      json:"body,omitempty"`
+
+	// BodyFile is a file containing the body of the response.  A relative
+	// path is relative to the configuration file.
+	BodyFile string `toml:"body-file" yaml:"body-file" json:"bodyFile,omitempty"`
+
+	// JSON is a value which is written as the body of the response in JSON.
+	JSON any `toml:"json"      yaml:"json"      json:"json,omitempty"`
+
+	// Delay is how long to wait before responding, as in 250ms
+	Delay string `toml:"delay"     yaml:"delay"     json:"delay,omitempty"`
+
+	// Handler names the provider in the handler registry which handles the
+	// request instead of a static response
+	Handler string `toml:"handler"   yaml:"handler"   json:"handler,omitempty"`
+
+	// Options are passed to the handler provider.  The authentication
+	// options such as auth_user and the fault injection options such as
+	// fault_latency are also supported, including for static responses.
+	Options map[string]any `toml:"options"   yaml:"options"   json:"options,omitempty"`
+}
+
+// routeGroup contains the routes which have the same path and selects the
+// route using the method
+type routeGroup struct {
+	routes []*compiledRoute
+}
+
+type compiledRoute struct {
+	methods []string
+	handler http.Handler
+}
+
+// LoadConfig loads the configuration from a file.  The format is determined
+// from the extension, which can be .yaml, .yml, .json, or .toml.
+func LoadConfig(filename string) (*Config, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg Config
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".json":
+		err = decodeJSONConfig(data, &cfg)
+	case ".yaml", ".yml":
+		err = decodeYAMLConfig(data, &cfg)
+	case ".toml":
+		err = decodeTOMLConfig(data, &cfg)
+	default:
+		return nil, fmt.Errorf("%s: unknown configuration file format", filename)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+
+	cfg.dir = filepath.Dir(filename)
+	return &cfg, nil
+}
+
+// NewConfigHandler provides a handler which serves the routes from the
+// configuration file.  The file is loaded again when the handler is reloaded.
+// If the file can't be loaded when reloading, a warning is reported and the
+// previous routes continue to be used.
+func NewConfigHandler(ctx context.Context, filename string) (ReloadableHandler, error) {
+	current, err := newConfigFileHandler(ctx, filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewReloadableHandler(func(context.Context) (http.Handler, error) {
+		h, err := newConfigFileHandler(ctx, filename)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: unable to reload configuration: %s\n", err)
+			return current, nil
+		}
+		current = h
+		return h, nil
+	}), nil
+}
+
+// NewHandler creates the handler which serves the routes.  The context is used
+// to look up providers in the handler registry.
+func (c *Config) NewHandler(ctx context.Context) (http.Handler, error) {
+	var (
+		mux    = http.NewServeMux()
+		groups = map[string]*routeGroup{}
+		paths  []string
+	)
+	for i := range c.Routes {
+		r := &c.Routes[i]
+		h, err := c.compileRoute(ctx, r)
+		if err != nil {
+			return nil, fmt.Errorf("route %d (%s): %w", i+1, r.Path, err)
+		}
+
+		g, ok := groups[r.Path]
+		if !ok {
+			g = new(routeGroup)
+			groups[r.Path] = g
+			paths = append(paths, r.Path)
+		}
+		g.routes = append(g.routes, h)
+	}
+
+	for _, p := range paths {
+		if err := handleSafely(mux, p, groups[p]); err != nil {
+			return nil, err
+		}
+	}
+	return mux, nil
+}
+
+func (c *Config) compileRoute(ctx context.Context, r *Route) (*compiledRoute, error) {
+	if !strings.HasPrefix(r.Path, "/") {
+		return nil, fmt.Errorf("path must start with /")
+	}
+	if r.Status != 0 && (r.Status < 100 || r.Status > 999) {
+		return nil, fmt.Errorf("invalid status code %d", r.Status)
+	}
+
+	var delay time.Duration
+	if r.Delay != "" {
+		var err error
+		delay, err = time.ParseDuration(r.Delay)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var (
+		h   http.Handler
+		err error
+	)
+	if r.Handler != "" {
+		if r.Body != "" || r.BodyFile != "" || r.JSON != nil {
+			return nil, fmt.Errorf("handler can't be used with a body")
+		}
+		h, err = newSpecHandler(ctx, r.virtualPath(), RegistryHandlerSpec("handlers"))
+	} else {
+		h, err = newSpecHandler(ctx, r.virtualPath(), func(context.Context, httpclient.VirtualPath) (http.Handler, error) {
+			return c.staticHandler(r)
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if delay > 0 {
+		h = withDelay(delay, h)
+	}
+
+	methods := slices.Clone(r.Methods)
+	if r.Method != "" {
+		methods = append([]string{r.Method}, methods...)
+	}
+	for i, m := range methods {
+		methods[i] = strings.ToUpper(m)
+	}
+	return &compiledRoute{methods: methods, handler: h}, nil
+}
+
+func (c *Config) staticHandler(r *Route) (http.Handler, error) {
+	var (
+		body        []byte
+		contentType string
+	)
+	switch {
+	case r.BodyFile != "":
+		name := r.BodyFile
+		if !filepath.IsAbs(name) {
+			name = filepath.Join(c.dir, name)
+		}
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		body = data
+	case r.JSON != nil:
+		data, err := json.MarshalIndent(r.JSON, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		body = append(data, '\n')
+		contentType = "application/json"
+	default:
+		body = []byte(r.Body)
+	}
+
+	status := r.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	headers := http.Header{}
+	for k, v := range r.Headers {
+		headers.Set(k, v)
+	}
+	if contentType != "" && headers.Get("Content-Type") == "" {
+		headers.Set("Content-Type", contentType)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		for k, v := range headers {
+			w.Header()[k] = v
+		}
+		w.WriteHeader(status)
+		if req.Method != http.MethodHead {
+			w.Write(body)
+		}
+	}), nil
+}
+
+func (r *Route) virtualPath() httpclient.VirtualPath {
+	opts := map[string]string{}
+	for k, v := range r.Options {
+		opts[k] = fmt.Sprint(v)
+	}
+
+	// Handlers are mounted with the literal prefix of the path stripped
+	prefix := r.Path
+	if i := strings.Index(prefix, "{"); i >= 0 {
+		prefix = prefix[:strings.LastIndex(prefix[:i], "/")+1]
+	}
+	return httpclient.VirtualPath{
+		RequestPath:  prefix,
+		PhysicalPath: r.Handler,
+		Options:      opts,
+	}
+}
+
+func (g *routeGroup) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var allow []string
+	for _, route := range g.routes {
+		if route.matches(r.Method) {
+			route.handler.ServeHTTP(w, r)
+			return
+		}
+		allow = append(allow, route.methods...)
+	}
+
+	w.Header().Set("Allow", strings.Join(allow, ", "))
+	http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+}
+
+func (r *compiledRoute) matches(method string) bool {
+	if len(r.methods) == 0 || slices.Contains(r.methods, method) {
+		return true
+	}
+	return method == http.MethodHead && slices.Contains(r.methods, http.MethodGet)
+}
+
+func withDelay(d time.Duration, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(d):
+			next.ServeHTTP(w, r)
+		case <-r.Context().Done():
+		}
+	})
+}
+
+func newConfigFileHandler(ctx context.Context, filename string) (http.Handler, error) {
+	cfg, err := LoadConfig(filename)
+	if err != nil {
+		return nil, err
+	}
+	h, err := cfg.NewHandler(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	return h, nil
+}
+
+func handleSafely(m mux, pattern string, h http.Handler) (err error) {
+	defer func() {
+		if rvr := recover(); rvr != nil {
+			err = fmt.Errorf("%s", fmt.Sprint(rvr))
+		}
+	}()
+	m.Handle(pattern, h)
+	return
+}
+
+func decodeJSONConfig(data []byte, cfg *Config) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	return dec.Decode(cfg)
+}
+
+func decodeYAMLConfig(data []byte, cfg *Config) error {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	err := dec.Decode(cfg)
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+	return err
+}
+
+func decodeTOMLConfig(data []byte, cfg *Config) error {
+	md, err := toml.Decode(string(data), cfg)
+	if err != nil {
+		return err
+	}
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		return fmt.Errorf("unknown field %q", undecoded[0].String())
+	}
+	return nil
+}
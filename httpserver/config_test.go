@@ -0,0 +1,194 @@
+// Copyright 2026 The Joe-cli Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package httpserver_test
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/Carbonfrost/joe-cli"
+	"github.com/Carbonfrost/joe-cli-http/httpserver"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gstruct"
+)
+
+var _ = Describe("LoadConfig", func() {
+
+	var dir string
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+	})
+
+	writeFile := func(name, content string) string {
+		filename := filepath.Join(dir, name)
+		Expect(os.WriteFile(filename, []byte(content), 0600)).To(Succeed())
+		return filename
+	}
+
+	DescribeTable("formats", func(name, content string) {
+		cfg, err := httpserver.LoadConfig(writeFile(name, content))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cfg.Routes).To(ConsistOf(MatchFields(IgnoreExtras, Fields{
+			"Path":    Equal("/users/{id}"),
+			"Methods": Equal([]string{"GET", "HEAD"}),
+			"Status":  Equal(201),
+			"Headers": Equal(map[string]string{"X-Mock": "yes"}),
+			"Delay":   Equal("10ms"),
+			"Options": HaveKeyWithValue("failsafe", true),
+		})))
+	},
+		Entry("YAML", "weave.yaml", `
+routes:
+  - path: /users/{id}
+    methods: [GET, HEAD]
+    status: 201
+    headers:
+      X-Mock: "yes"
+    delay: 10ms
+    options:
+      failsafe: true
+`),
+		Entry("JSON", "weave.json", `{
+  "routes": [{
+    "path": "/users/{id}",
+    "methods": ["GET", "HEAD"],
+    "status": 201,
+    "headers": {"X-Mock": "yes"},
+    "delay": "10ms",
+    "options": {"failsafe": true}
+  }]
+}`),
+		Entry("TOML", "weave.toml", `
+[[routes]]
+path = "/users/{id}"
+methods = ["GET", "HEAD"]
+status = 201
+delay = "10ms"
+headers = { X-Mock = "yes" }
+options = { failsafe = true }
+`),
+	)
+
+	DescribeTable("errors", func(name, content string, expected string) {
+		_, err := httpserver.LoadConfig(writeFile(name, content))
+		Expect(err).To(MatchError(ContainSubstring(expected)))
+	},
+		Entry("unknown format", "weave.ini", "", "unknown configuration file format"),
+		Entry("unknown JSON field", "weave.json", `{"routes": [{"pat": "/"}]}`, `unknown field "pat"`),
+		Entry("unknown YAML field", "weave.yaml", "routes:\n  - pat: /\n", `field pat not found`),
+		Entry("unknown TOML field", "weave.toml", "[[routes]]\npat = \"/\"\n", `unknown field "routes.pat"`),
+	)
+})
+
+var _ = Describe("Config", func() {
+
+	Describe("NewHandler", func() {
+
+		newHandler := func(cfg *httpserver.Config) http.Handler {
+			h, err := cfg.NewHandler(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			return h
+		}
+
+		It("serves the static response", func() {
+			cfg := &httpserver.Config{Routes: []httpserver.Route{
+				{Path: "/hello", Status: 202, Body: "hi", Headers: map[string]string{"X-Mock": "yes"}},
+			}}
+
+			recorder := serveRequest(newHandler(cfg), newRequest("GET", "/hello"))
+			Expect(recorder.Code).To(Equal(http.StatusAccepted))
+			Expect(recorder.Body.String()).To(Equal("hi"))
+			Expect(recorder.Header().Get("X-Mock")).To(Equal("yes"))
+		})
+
+		It("serves the JSON value", func() {
+			cfg := &httpserver.Config{Routes: []httpserver.Route{
+				{Path: "/users/{id}", JSON: map[string]any{"id": 1}},
+			}}
+
+			recorder := serveRequest(newHandler(cfg), newRequest("GET", "/users/1"))
+			Expect(recorder.Header().Get("Content-Type")).To(Equal("application/json"))
+			Expect(recorder.Body.String()).To(MatchJSON(`{"id": 1}`))
+		})
+
+		It("selects the route by method", func() {
+			cfg := &httpserver.Config{Routes: []httpserver.Route{
+				{Path: "/users", Method: "get", Body: "list"},
+				{Path: "/users", Methods: []string{"POST", "PUT"}, Status: 201},
+			}}
+			h := newHandler(cfg)
+
+			Expect(serveRequest(h, newRequest("GET", "/users")).Body.String()).To(Equal("list"))
+			Expect(serveRequest(h, newRequest("HEAD", "/users")).Code).To(Equal(http.StatusOK))
+			Expect(serveRequest(h, newRequest("PUT", "/users")).Code).To(Equal(http.StatusCreated))
+
+			recorder := serveRequest(h, newRequest("DELETE", "/users"))
+			Expect(recorder.Code).To(Equal(http.StatusMethodNotAllowed))
+			Expect(recorder.Header().Get("Allow")).To(Equal("GET, POST, PUT"))
+		})
+
+		DescribeTable("errors", func(route httpserver.Route, expected string) {
+			cfg := &httpserver.Config{Routes: []httpserver.Route{route}}
+			_, err := cfg.NewHandler(context.Background())
+			Expect(err).To(MatchError(expected))
+		},
+			Entry("relative path", httpserver.Route{Path: "users"}, "route 1 (users): path must start with /"),
+			Entry("invalid status", httpserver.Route{Path: "/", Status: 42}, "route 1 (/): invalid status code 42"),
+			Entry("invalid delay", httpserver.Route{Path: "/", Delay: "soon"}, `route 1 (/): time: invalid duration "soon"`),
+			Entry("handler with body",
+				httpserver.Route{Path: "/", Handler: "ping", Body: "x"},
+				"route 1 (/): handler can't be used with a body",
+			),
+		)
+	})
+})
+
+var _ = Describe("HandleConfig", func() {
+
+	var (
+		filename string
+		srv      *httpserver.Server
+	)
+
+	BeforeEach(func() {
+		dir := GinkgoT().TempDir()
+		filename = filepath.Join(dir, "weave.yaml")
+		Expect(os.WriteFile(filepath.Join(dir, "body.txt"), []byte("from file"), 0600)).To(Succeed())
+		Expect(os.WriteFile(filename, []byte(`
+routes:
+  - path: /file
+    body-file: body.txt
+  - path: /ping/
+    handler: ping
+`), 0600)).To(Succeed())
+
+		srv = httpserver.New(httpserver.WithNoAccessLog())
+		app := &cli.App{
+			Name:   "app",
+			Uses:   cli.Pipeline(httpserver.ContextValue(srv), httpserver.HandlerRegistry),
+			Action: httpserver.HandleConfig(filename),
+		}
+		Expect(app.RunContext(context.Background(), []string{"app"})).To(Succeed())
+	})
+
+	It("serves the routes", func() {
+		Expect(serveRequest(srv.Handler, newRequest("GET", "/file")).Body.String()).To(Equal("from file"))
+		Expect(serveRequest(srv.Handler, newRequest("GET", "/ping/")).Body.String()).To(Equal("ping\n"))
+	})
+
+	It("reloads the routes", func() {
+		Expect(serveRequest(srv.Handler, newRequest("GET", "/file")).Code).To(Equal(http.StatusOK))
+		Expect(os.WriteFile(filename, []byte("routes:\n  - path: /new\n    body: new\n"), 0600)).To(Succeed())
+
+		srv.ReloadAll()
+		Expect(serveRequest(srv.Handler, newRequest("GET", "/file")).Code).To(Equal(http.StatusNotFound))
+		Expect(serveRequest(srv.Handler, newRequest("GET", "/new")).Body.String()).To(Equal("new"))
+	})
+})
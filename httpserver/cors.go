@@ -0,0 +1,182 @@
+// Copyright 2026 The Joe-cli Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package httpserver
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORSOptions configures cross-origin resource sharing (CORS)
+type CORSOptions struct {
+	// AllowedOrigins lists the origins which are allowed.  When empty or
+	// when it contains "*", any origin is allowed.  An origin can contain
+	// a wildcard for subdomains, as in https://*.example.com
+	AllowedOrigins []string `toml:"origins"     json:"origins,omitempty"`
+
+	// AllowedMethods lists the methods allowed in preflight requests.  When
+	// empty, the common methods are allowed.
+	AllowedMethods []string `toml:"methods"     json:"methods,omitempty"`
+
+	// AllowedHeaders lists the request headers allowed in preflight requests.
+	// When empty or when it contains "*", the headers requested are allowed.
+	AllowedHeaders []string `toml:"headers"     json:"headers,omitempty"`
+
+	// AllowCredentials indicates whether credentials such as cookies are
+	// allowed.  Credentials require AllowedOrigins to list the origins
+	// explicitly, so that other sites can't read authenticated responses.
+	AllowCredentials bool `toml:"credentials" json:"credentials,omitempty"`
+
+	// MaxAge specifies how long the results of a preflight request can be
+	// cached
+	MaxAge time.Duration `toml:"max-age"     json:"maxAge,omitempty"`
+}
+
+var errCORSCredentialsAnyOrigin = errors.New("CORS credentials require the allowed origins to be specified with --cors-origin")
+
+var defaultCORSMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+}
+
+// NewCORSMiddleware provides handler middleware which implements
+// cross-origin resource sharing (CORS).  Preflight requests are answered
+// directly and are not passed to the next handler.
+func NewCORSMiddleware(opts CORSOptions) MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+
+			if preflight {
+				opts.preflight(w, r, origin)
+				return
+			}
+
+			w.Header().Add("Vary", "Origin")
+			if origin != "" && opts.allowsOrigin(origin) {
+				opts.setAllowOrigin(w, origin)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func (o CORSOptions) preflight(w http.ResponseWriter, r *http.Request, origin string) {
+	h := w.Header()
+	h.Add("Vary", "Origin")
+	h.Add("Vary", "Access-Control-Request-Method")
+	h.Add("Vary", "Access-Control-Request-Headers")
+
+	method := r.Header.Get("Access-Control-Request-Method")
+	headers := requestedHeaders(r)
+	if origin == "" || !o.allowsOrigin(origin) || !o.allowsMethod(method) || !o.allowsHeaders(headers) {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	o.setAllowOrigin(w, origin)
+	h.Set("Access-Control-Allow-Methods", strings.Join(o.methods(), ", "))
+	if len(headers) > 0 {
+		h.Set("Access-Control-Allow-Headers", strings.Join(headers, ", "))
+	}
+	if o.MaxAge > 0 {
+		h.Set("Access-Control-Max-Age", strconv.Itoa(int(o.MaxAge.Seconds())))
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (o CORSOptions) validate() error {
+	if o.AllowCredentials && o.anyOrigin() {
+		return errCORSCredentialsAnyOrigin
+	}
+	return nil
+}
+
+// setAllowOrigin sets the origin which is allowed.  Credentials are never
+// allowed together with any origin.
+func (o CORSOptions) setAllowOrigin(w http.ResponseWriter, origin string) {
+	h := w.Header()
+	if o.anyOrigin() {
+		h.Set("Access-Control-Allow-Origin", "*")
+		return
+	}
+	h.Set("Access-Control-Allow-Origin", origin)
+	if o.AllowCredentials {
+		h.Set("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+func (o CORSOptions) anyOrigin() bool {
+	return len(o.AllowedOrigins) == 0 || slices.Contains(o.AllowedOrigins, "*")
+}
+
+func (o CORSOptions) allowsOrigin(origin string) bool {
+	if o.anyOrigin() {
+		return true
+	}
+	origin = strings.ToLower(origin)
+	for _, allowed := range o.AllowedOrigins {
+		allowed = strings.ToLower(allowed)
+		if prefix, suffix, ok := strings.Cut(allowed, "*"); ok {
+			if len(origin) > len(prefix)+len(suffix) &&
+				strings.HasPrefix(origin, prefix) && strings.HasSuffix(origin, suffix) {
+				return true
+			}
+			continue
+		}
+		if origin == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+func (o CORSOptions) methods() []string {
+	if len(o.AllowedMethods) == 0 {
+		return defaultCORSMethods
+	}
+	return o.AllowedMethods
+}
+
+func (o CORSOptions) allowsMethod(method string) bool {
+	return slices.ContainsFunc(o.methods(), func(m string) bool {
+		return strings.EqualFold(m, method)
+	})
+}
+
+func (o CORSOptions) allowsHeaders(headers []string) bool {
+	if len(o.AllowedHeaders) == 0 || slices.Contains(o.AllowedHeaders, "*") {
+		return true
+	}
+	for _, h := range headers {
+		if !slices.ContainsFunc(o.AllowedHeaders, func(a string) bool {
+			return strings.EqualFold(a, h)
+		}) {
+			return false
+		}
+	}
+	return true
+}
+
+func requestedHeaders(r *http.Request) []string {
+	var res []string
+	for _, v := range r.Header.Values("Access-Control-Request-Headers") {
+		for h := range strings.SplitSeq(v, ",") {
+			if h = strings.TrimSpace(h); h != "" {
+				res = append(res, strings.ToLower(h))
+			}
+		}
+	}
+	return res
+}
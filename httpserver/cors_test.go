@@ -0,0 +1,113 @@
+// Copyright 2026 The Joe-cli Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package httpserver_test
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Carbonfrost/joe-cli-http/httpserver"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gstruct"
+	"github.com/onsi/gomega/types"
+)
+
+var _ = Describe("NewCORSMiddleware", func() {
+
+	newHandler := func(opts httpserver.CORSOptions) http.Handler {
+		return httpserver.NewCORSMiddleware(opts)(httpserver.NewPingHandler())
+	}
+
+	DescribeTable("simple requests", func(opts httpserver.CORSOptions, origin string, expected types.GomegaMatcher) {
+		recorder := serveRequest(newHandler(opts), newRequest("GET", "/", "Origin", origin))
+
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(recorder.Body.String()).To(Equal("ping\n"))
+		Expect(recorder.Header()).To(expected)
+	},
+		Entry("any origin",
+			httpserver.CORSOptions{},
+			"https://app.example",
+			HaveKeyWithValue("Access-Control-Allow-Origin", []string{"*"}),
+		),
+		Entry("allowed origin is echoed",
+			httpserver.CORSOptions{AllowedOrigins: []string{"https://app.example"}},
+			"https://app.example",
+			HaveKeyWithValue("Access-Control-Allow-Origin", []string{"https://app.example"}),
+		),
+		Entry("wildcard subdomain",
+			httpserver.CORSOptions{AllowedOrigins: []string{"https://*.example"}},
+			"https://app.example",
+			HaveKeyWithValue("Access-Control-Allow-Origin", []string{"https://app.example"}),
+		),
+		Entry("origin not allowed",
+			httpserver.CORSOptions{AllowedOrigins: []string{"https://app.example"}},
+			"https://evil.example",
+			Not(HaveKey("Access-Control-Allow-Origin")),
+		),
+		Entry("credentials with allowed origin",
+			httpserver.CORSOptions{AllowedOrigins: []string{"https://app.example"}, AllowCredentials: true},
+			"https://app.example",
+			MatchKeys(IgnoreExtras, Keys{
+				"Access-Control-Allow-Origin":      Equal([]string{"https://app.example"}),
+				"Access-Control-Allow-Credentials": Equal([]string{"true"}),
+				"Vary":                             ContainElement("Origin"),
+			}),
+		),
+		Entry("credentials not allowed with any origin",
+			httpserver.CORSOptions{AllowedOrigins: []string{"*"}, AllowCredentials: true},
+			"https://app.example",
+			And(
+				HaveKeyWithValue("Access-Control-Allow-Origin", []string{"*"}),
+				Not(HaveKey("Access-Control-Allow-Credentials")),
+			),
+		),
+	)
+
+	DescribeTable("preflight requests", func(opts httpserver.CORSOptions, req *http.Request, expectedCode int, expected types.GomegaMatcher) {
+		recorder := serveRequest(newHandler(opts), req)
+
+		Expect(recorder.Code).To(Equal(expectedCode))
+		Expect(recorder.Body.String()).To(BeEmpty())
+		Expect(recorder.Header()).To(expected)
+	},
+		Entry("allowed",
+			httpserver.CORSOptions{MaxAge: 10 * time.Minute},
+			newRequest("OPTIONS", "/",
+				"Origin", "https://app.example",
+				"Access-Control-Request-Method", "PUT",
+				"Access-Control-Request-Headers", "Content-Type, X-Token",
+			),
+			http.StatusNoContent,
+			MatchKeys(IgnoreExtras, Keys{
+				"Access-Control-Allow-Origin":  Equal([]string{"*"}),
+				"Access-Control-Allow-Methods": Equal([]string{"GET, HEAD, POST, PUT, PATCH, DELETE"}),
+				"Access-Control-Allow-Headers": Equal([]string{"content-type, x-token"}),
+				"Access-Control-Max-Age":       Equal([]string{"600"}),
+			}),
+		),
+		Entry("method not allowed",
+			httpserver.CORSOptions{AllowedMethods: []string{"GET"}},
+			newRequest("OPTIONS", "/",
+				"Origin", "https://app.example",
+				"Access-Control-Request-Method", "DELETE",
+			),
+			http.StatusForbidden,
+			Not(HaveKey("Access-Control-Allow-Origin")),
+		),
+		Entry("header not allowed",
+			httpserver.CORSOptions{AllowedHeaders: []string{"Content-Type"}},
+			newRequest("OPTIONS", "/",
+				"Origin", "https://app.example",
+				"Access-Control-Request-Method", "GET",
+				"Access-Control-Request-Headers", "X-Token",
+			),
+			http.StatusForbidden,
+			Not(HaveKey("Access-Control-Allow-Origin")),
+		),
+	)
+})
@@ -83,6 +83,23 @@ func tryTLSState(r *http.Request) *tlsConnectionState {
 func (e *echoHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
+	resp := newReflectedRequest(r)
+
+	// If errors have occurred, only write out the errors unless we are in failsafe mode.
+	var output any = resp
+
+	if len(resp.Errors) > 0 && !e.failsafe {
+		w.WriteHeader(http.StatusBadRequest)
+		output = resp.Errors
+	}
+	if err := json.NewEncoder(w).Encode(output); err != nil {
+		log.Printf("echo handler: error writing response: %v", err)
+	}
+}
+
+// newReflectedRequest reads the request, including its body, form, and files.
+// Problems with the request are recorded in Errors.
+func newReflectedRequest(r *http.Request) reflectedRequest {
 	resp := reflectedRequest{
 		Method:  r.Method,
 		URL:     r.URL.String(),
@@ -93,16 +110,9 @@ func (e *echoHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 
 	handleError := func(e string) {
-		w.WriteHeader(http.StatusBadRequest)
 		resp.Errors = append(resp.Errors, e)
 	}
 
-	if e.failsafe {
-		handleError = func(e string) {
-			resp.Errors = append(resp.Errors, e)
-		}
-	}
-
 	// Parse cookies
 	if len(r.Cookies()) > 0 {
 		result := make(map[string]string)
@@ -166,16 +176,7 @@ func (e *echoHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			}
 		}
 	}
-
-	// If errors have occurred, only write out the errors unless we are in failsafe mode.
-	var output any = resp
-
-	if len(resp.Errors) > 0 && !e.failsafe {
-		output = resp.Errors
-	}
-	if err := json.NewEncoder(w).Encode(output); err != nil {
-		log.Printf("echo handler: error writing response: %v", err)
-	}
+	return resp
 }
 
 func sniffAndSum(filename string, f io.Reader) (fi fileInfo, err error) {
@@ -0,0 +1,489 @@
+// Copyright 2026 The Joe-cli Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package httpserver
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FaultOptions configures fault injection, which makes a handler behave like
+// a slow or unreliable service.  Each rate is the probability from 0 to 1 that
+// the fault occurs for a request.
+type FaultOptions struct {
+	// Latency is the delay added before the request is handled
+	Latency Latency `toml:"latency"       json:"latency,omitzero"`
+
+	// ErrorRate is the rate of requests which receive an error status code
+	// instead of being handled
+	ErrorRate float64 `toml:"error-rate"    json:"errorRate,omitempty"`
+
+	// ErrorCodes are the status codes chosen randomly for errors.  When empty,
+	// 500, 502, and 503 are used.
+	ErrorCodes []int `toml:"error-codes"   json:"errorCodes,omitempty"`
+
+	// ResetRate is the rate of requests where the connection is reset without
+	// a response
+	ResetRate float64 `toml:"reset-rate"    json:"resetRate,omitempty"`
+
+	// TruncateRate is the rate of requests where the connection is closed
+	// after only half of the response body is written
+	TruncateRate float64 `toml:"truncate-rate" json:"truncateRate,omitempty"`
+
+	// TrickleRate is the rate of requests where the response body is written
+	// slowly
+	TrickleRate float64 `toml:"trickle-rate"  json:"trickleRate,omitempty"`
+
+	// TrickleSpeed is the number of bytes per second written when the response
+	// trickles.  When zero, a default of 1 KiB per second is used.
+	TrickleSpeed int `toml:"trickle-speed" json:"trickleSpeed,omitempty"`
+
+	// Seed initializes the random numbers so that the faults occur in the same
+	// sequence each time.  When zero, a random seed is used.
+	Seed uint64 `toml:"seed"          json:"seed,omitempty"`
+}
+
+// Latency is a delay which is either fixed or varies randomly.  When it
+// varies, Duration is the mean and Jitter is the half-width of the range
+// for a uniform distribution or the standard deviation for a normal
+// distribution.
+type Latency struct {
+	Duration     time.Duration
+	Jitter       time.Duration
+	Distribution LatencyDistribution
+}
+
+// LatencyDistribution specifies how latency varies
+type LatencyDistribution int
+
+// Latency distributions
+const (
+	UniformLatency LatencyDistribution = iota
+	NormalLatency
+	maxLatencyDistribution
+)
+
+type faultInjector struct {
+	opts FaultOptions
+
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+// faults are the faults chosen for a request
+type faults struct {
+	delay    time.Duration
+	reset    bool
+	status   int
+	truncate bool
+	trickle  bool
+}
+
+type faultResponseWriter struct {
+	http.ResponseWriter
+	truncate bool
+	head     bool
+	trickle  int
+	status   int
+	buf      bytes.Buffer
+}
+
+// Names of the virtual path options which apply fault injection to a handler
+const (
+	faultLatencyOption      = "fault_latency"
+	faultErrorRateOption    = "fault_error_rate"
+	faultErrorCodesOption   = "fault_error_codes"
+	faultResetRateOption    = "fault_reset_rate"
+	faultTruncateRateOption = "fault_truncate_rate"
+	faultTrickleRateOption  = "fault_trickle_rate"
+	faultTrickleSpeedOption = "fault_trickle_speed"
+	faultSeedOption         = "fault_seed"
+)
+
+const (
+	defaultTrickleSpeed = 1024
+	trickleInterval     = 100 * time.Millisecond
+
+	// maxTruncateBuffer is the most of the body that is buffered in order to
+	// truncate it
+	maxTruncateBuffer = 1 << 20
+)
+
+var (
+	defaultFaultErrorCodes = []int{
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+	}
+
+	latencyDistributionStrings = [...]string{
+		"uniform",
+		"normal",
+	}
+)
+
+// NewFaultMiddleware provides handler middleware which injects faults into
+// the requests that are handled.  When faults are chosen for a request, they
+// are applied in order: the latency, then the connection reset, then the error
+// status code, and finally truncating or trickling the response body.
+func NewFaultMiddleware(opts FaultOptions) (MiddlewareFunc, error) {
+	f, err := newFaultInjector(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			faults := f.choose()
+			if !sleepContext(r, faults.delay) {
+				return
+			}
+			if faults.reset {
+				resetConnection(w)
+				return
+			}
+			if faults.status != 0 {
+				http.Error(w, http.StatusText(faults.status), faults.status)
+				return
+			}
+			if !faults.truncate && !faults.trickle {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			fw := &faultResponseWriter{
+				ResponseWriter: w,
+				truncate:       faults.truncate,
+				head:           r.Method == http.MethodHead,
+			}
+			if faults.trickle {
+				fw.trickle = f.trickleSpeed()
+			}
+			next.ServeHTTP(fw, r)
+			fw.finish()
+		})
+	}, nil
+}
+
+func newFaultInjector(opts FaultOptions) (*faultInjector, error) {
+	for _, rate := range []float64{opts.ErrorRate, opts.ResetRate, opts.TruncateRate, opts.TrickleRate} {
+		if rate < 0 || rate > 1 {
+			return nil, fmt.Errorf("fault rate must be between 0 and 1")
+		}
+	}
+	for _, code := range opts.ErrorCodes {
+		if code < 100 || code > 999 {
+			return nil, fmt.Errorf("invalid status code %d", code)
+		}
+	}
+	if opts.Latency.Duration < 0 || opts.Latency.Jitter < 0 {
+		return nil, fmt.Errorf("latency must not be negative")
+	}
+
+	seed := opts.Seed
+	if seed == 0 {
+		seed = rand.Uint64()
+	}
+	return &faultInjector{
+		opts: opts,
+		rng:  rand.New(rand.NewPCG(seed, seed)),
+	}, nil
+}
+
+// choose chooses the faults for a request.  The same number of random values
+// is used for each request so that the sequence is reproducible from the seed.
+func (f *faultInjector) choose() faults {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var (
+		delay    = f.opts.Latency.sample(f.rng)
+		reset    = f.rng.Float64() < f.opts.ResetRate
+		isError  = f.rng.Float64() < f.opts.ErrorRate
+		codes    = f.opts.ErrorCodes
+		code     int
+		index    = f.rng.IntN(1 << 16)
+		truncate = f.rng.Float64() < f.opts.TruncateRate
+		trickle  = f.rng.Float64() < f.opts.TrickleRate
+	)
+	if len(codes) == 0 {
+		codes = defaultFaultErrorCodes
+	}
+	if isError {
+		code = codes[index%len(codes)]
+	}
+	return faults{
+		delay:    delay,
+		reset:    reset,
+		status:   code,
+		truncate: truncate,
+		trickle:  trickle,
+	}
+}
+
+func (f *faultInjector) trickleSpeed() int {
+	if f.opts.TrickleSpeed > 0 {
+		return f.opts.TrickleSpeed
+	}
+	return defaultTrickleSpeed
+}
+
+// resetConnection closes the connection so that the client receives a reset
+// instead of a response.  If the connection can't be hijacked, as in HTTP/2,
+// the handler is aborted, which resets the stream.
+func resetConnection(w http.ResponseWriter) {
+	conn, _, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		panic(http.ErrAbortHandler)
+	}
+	if tc, ok := conn.(*tls.Conn); ok {
+		conn = tc.NetConn()
+	}
+	if tcp, ok := conn.(*net.TCPConn); ok {
+		tcp.SetLinger(0)
+	}
+	conn.Close()
+}
+
+func (w *faultResponseWriter) WriteHeader(status int) {
+	if w.truncate {
+		if w.status == 0 {
+			w.status = status
+		}
+		return
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *faultResponseWriter) Write(b []byte) (int, error) {
+	if w.truncate {
+		n, err := w.buf.Write(b)
+		if w.buf.Len() > maxTruncateBuffer {
+			w.truncateBody(false)
+		}
+		return n, err
+	}
+	return w.writeSlowly(b)
+}
+
+func (w *faultResponseWriter) Flush() {
+	if !w.truncate {
+		http.NewResponseController(w.ResponseWriter).Flush()
+	}
+}
+
+func (w *faultResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// finish writes the body that was buffered when truncating
+func (w *faultResponseWriter) finish() {
+	if w.truncate {
+		w.truncateBody(true)
+	}
+}
+
+// truncateBody writes half of the body that was buffered and then aborts the
+// handler, so the client receives an unexpected end of the body.  When the
+// whole body was buffered, the Content-Length is its length.  Otherwise, the
+// body was too long to buffer and is truncated before the handler finishes.
+func (w *faultResponseWriter) truncateBody(whole bool) {
+	w.truncate = false
+
+	body := w.buf.Bytes()
+	if whole {
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		w.Header().Del("Transfer-Encoding")
+	}
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+	if !w.head {
+		w.writeSlowly(body[:len(body)/2])
+	}
+	w.Flush()
+	panic(http.ErrAbortHandler)
+}
+
+func (w *faultResponseWriter) writeSlowly(b []byte) (int, error) {
+	if w.trickle <= 0 {
+		return w.ResponseWriter.Write(b)
+	}
+
+	chunk := max(1, w.trickle*int(trickleInterval)/int(time.Second))
+	var written int
+	for len(b) > 0 {
+		if written > 0 {
+			time.Sleep(trickleInterval)
+		}
+		n, err := w.ResponseWriter.Write(b[:min(chunk, len(b))])
+		written += n
+		if err != nil {
+			return written, err
+		}
+		b = b[n:]
+		w.Flush()
+	}
+	return written, nil
+}
+
+// pathFaultOptions removes the fault injection options from the options of a
+// virtual path.  The options that were removed are returned, or nil if the
+// virtual path doesn't use fault injection.
+func pathFaultOptions(opts map[string]string) (map[string]string, *FaultOptions, error) {
+	var (
+		fault  FaultOptions
+		found  bool
+		result = map[string]string{}
+		err    error
+	)
+	for k, v := range opts {
+		switch k {
+		case faultLatencyOption:
+			err = fault.Latency.Set(v)
+		case faultErrorRateOption:
+			fault.ErrorRate, err = strconv.ParseFloat(v, 64)
+		case faultErrorCodesOption:
+			fault.ErrorCodes, err = parseStatusCodes(v)
+		case faultResetRateOption:
+			fault.ResetRate, err = strconv.ParseFloat(v, 64)
+		case faultTruncateRateOption:
+			fault.TruncateRate, err = strconv.ParseFloat(v, 64)
+		case faultTrickleRateOption:
+			fault.TrickleRate, err = strconv.ParseFloat(v, 64)
+		case faultTrickleSpeedOption:
+			fault.TrickleSpeed, err = strconv.Atoi(v)
+		case faultSeedOption:
+			fault.Seed, err = strconv.ParseUint(v, 10, 64)
+		default:
+			result[k] = v
+			continue
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", k, err)
+		}
+		found = true
+	}
+	if !found {
+		return opts, nil, nil
+	}
+	return result, &fault, nil
+}
+
+// parseStatusCodes parses a list of status codes.  Because commas separate
+// the options of a virtual path, the codes can be separated with |, as in
+// 500|503.
+func parseStatusCodes(s string) ([]int, error) {
+	var codes []int
+	for _, f := range strings.FieldsFunc(s, func(r rune) bool {
+		return r == '|' || r == ',' || r == ' ' || r == '[' || r == ']'
+	}) {
+		code, err := strconv.Atoi(f)
+		if err != nil {
+			return nil, fmt.Errorf("invalid status code %q", f)
+		}
+		codes = append(codes, code)
+	}
+	return codes, nil
+}
+
+// ParseLatency parses a latency.  A duration such as 250ms is fixed.  A range
+// such as 100ms-500ms is uniformly distributed.  A mean and standard
+// deviation such as 200ms~50ms is normally distributed.
+func ParseLatency(s string) (Latency, error) {
+	s = strings.TrimSpace(s)
+	if lo, hi, ok := strings.Cut(s, "-"); ok && lo != "" {
+		from, err1 := time.ParseDuration(lo)
+		to, err2 := time.ParseDuration(hi)
+		if err1 != nil || err2 != nil || from < 0 || to < from {
+			return Latency{}, fmt.Errorf("invalid latency %q", s)
+		}
+		return Latency{
+			Duration:     (from + to) / 2,
+			Jitter:       (to - from) / 2,
+			Distribution: UniformLatency,
+		}, nil
+	}
+	if mean, stdDev, ok := strings.Cut(s, "~"); ok {
+		d, err1 := time.ParseDuration(mean)
+		j, err2 := time.ParseDuration(stdDev)
+		if err1 != nil || err2 != nil || d < 0 || j < 0 {
+			return Latency{}, fmt.Errorf("invalid latency %q", s)
+		}
+		return Latency{Duration: d, Jitter: j, Distribution: NormalLatency}, nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return Latency{}, fmt.Errorf("invalid latency %q", s)
+	}
+	return Latency{Duration: d}, nil
+}
+
+// sample chooses a delay from the distribution.  A random value is always
+// used so that the sequence of faults doesn't depend on the latency.
+func (l Latency) sample(rng *rand.Rand) time.Duration {
+	var d time.Duration
+	switch l.Distribution {
+	case NormalLatency:
+		d = l.Duration + time.Duration(rng.NormFloat64()*float64(l.Jitter))
+	default:
+		d = l.Duration - l.Jitter + time.Duration(rng.Float64()*float64(2*l.Jitter))
+	}
+	return max(0, d)
+}
+
+func (l Latency) String() string {
+	switch {
+	case l.Jitter == 0:
+		if l.Duration == 0 {
+			return ""
+		}
+		return l.Duration.String()
+	case l.Distribution == NormalLatency:
+		return fmt.Sprintf("%s~%s", l.Duration, l.Jitter)
+	}
+	return fmt.Sprintf("%s-%s", l.Duration-l.Jitter, l.Duration+l.Jitter)
+}
+
+// MarshalText provides the textual representation
+func (l Latency) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+// UnmarshalText converts the textual representation
+func (l *Latency) UnmarshalText(b []byte) error {
+	return l.Set(string(b))
+}
+
+// Set sets the latency from its textual representation
+func (l *Latency) Set(arg string) error {
+	v, err := ParseLatency(arg)
+	if err != nil {
+		return err
+	}
+	*l = v
+	return nil
+}
+
+func (*Latency) Synopsis() string {
+	return "{DURATION|MIN-MAX|MEAN~STDDEV}"
+}
+
+func (d LatencyDistribution) String() string {
+	if d >= 0 && d < maxLatencyDistribution {
+		return latencyDistributionStrings[int(d)]
+	}
+	return ""
+}
@@ -0,0 +1,196 @@
+// Copyright 2026 The Joe-cli Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package httpserver_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/Carbonfrost/joe-cli"
+	"github.com/Carbonfrost/joe-cli-http/httpclient"
+	"github.com/Carbonfrost/joe-cli-http/httpserver"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("NewFaultMiddleware", func() {
+
+	body := strings.Repeat("0123456789", 10)
+	bodyHandler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		io.WriteString(w, body)
+	})
+
+	newHandler := func(opts httpserver.FaultOptions) http.Handler {
+		m, err := httpserver.NewFaultMiddleware(opts)
+		Expect(err).NotTo(HaveOccurred())
+		return m(bodyHandler)
+	}
+
+	statuses := func(h http.Handler, n int) []int {
+		var result []int
+		for range n {
+			result = append(result, serveRequest(h, newRequest("GET", "/")).Code)
+		}
+		return result
+	}
+
+	It("responds with the error status codes at the rate", func() {
+		h := newHandler(httpserver.FaultOptions{
+			ErrorRate:  0.5,
+			ErrorCodes: []int{502},
+			Seed:       1,
+		})
+
+		codes := statuses(h, 200)
+		Expect(codes).To(ContainElement(http.StatusOK))
+		Expect(codes).To(ContainElement(http.StatusBadGateway))
+		Expect(codes).To(HaveEach(BeElementOf(http.StatusOK, http.StatusBadGateway)))
+	})
+
+	It("produces the same faults from the same seed", func() {
+		opts := httpserver.FaultOptions{ErrorRate: 0.3, Seed: 42}
+		Expect(statuses(newHandler(opts), 50)).To(Equal(statuses(newHandler(opts), 50)))
+	})
+
+	It("adds the latency", func() {
+		h := newHandler(httpserver.FaultOptions{
+			Latency: httpserver.Latency{Duration: 50 * time.Millisecond},
+		})
+
+		start := time.Now()
+		statuses(h, 1)
+		Expect(time.Since(start)).To(BeNumerically(">=", 50*time.Millisecond))
+	})
+
+	Context("with a connection", func() {
+
+		get := func(opts httpserver.FaultOptions) (*http.Response, error) {
+			server := httptest.NewServer(newHandler(opts))
+			DeferCleanup(server.Close)
+			return http.Get(server.URL)
+		}
+
+		It("resets the connection", func() {
+			_, err := get(httpserver.FaultOptions{ResetRate: 1})
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("truncates the body", func() {
+			resp, err := get(httpserver.FaultOptions{TruncateRate: 1})
+			Expect(err).NotTo(HaveOccurred())
+			defer resp.Body.Close()
+
+			Expect(resp.ContentLength).To(Equal(int64(len(body))))
+			data, err := io.ReadAll(resp.Body)
+			Expect(err).To(MatchError(io.ErrUnexpectedEOF))
+			Expect(string(data)).To(Equal(body[:len(body)/2]))
+		})
+
+		It("truncates a body which is too long to buffer", func() {
+			long := strings.Repeat("0123456789", 200_000)
+			m, err := httpserver.NewFaultMiddleware(httpserver.FaultOptions{TruncateRate: 1})
+			Expect(err).NotTo(HaveOccurred())
+			server := httptest.NewServer(m(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				io.WriteString(w, long)
+			})))
+			DeferCleanup(server.Close)
+
+			resp, err := http.Get(server.URL)
+			Expect(err).NotTo(HaveOccurred())
+			defer resp.Body.Close()
+
+			data, err := io.ReadAll(resp.Body)
+			Expect(err).To(HaveOccurred())
+			Expect(len(data)).To(BeNumerically("<", len(long)))
+		})
+
+		It("trickles the body", func() {
+			start := time.Now()
+			resp, err := get(httpserver.FaultOptions{TrickleRate: 1, TrickleSpeed: 500})
+			Expect(err).NotTo(HaveOccurred())
+			defer resp.Body.Close()
+
+			data, err := io.ReadAll(resp.Body)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(data)).To(Equal(body))
+			Expect(time.Since(start)).To(BeNumerically(">=", 100*time.Millisecond))
+		})
+	})
+
+	DescribeTable("errors", func(opts httpserver.FaultOptions, expected string) {
+		_, err := httpserver.NewFaultMiddleware(opts)
+		Expect(err).To(MatchError(expected))
+	},
+		Entry("rate out of range", httpserver.FaultOptions{ErrorRate: 2}, "fault rate must be between 0 and 1"),
+		Entry("invalid status code", httpserver.FaultOptions{ErrorCodes: []int{42}}, "invalid status code 42"),
+	)
+})
+
+var _ = Describe("ParseLatency", func() {
+
+	DescribeTable("examples", func(text string, expected httpserver.Latency) {
+		actual, err := httpserver.ParseLatency(text)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(actual).To(Equal(expected))
+		Expect(actual.String()).To(Equal(text))
+	},
+		Entry("fixed", "250ms", httpserver.Latency{Duration: 250 * time.Millisecond}),
+		Entry("uniform", "100ms-500ms", httpserver.Latency{
+			Duration: 300 * time.Millisecond,
+			Jitter:   200 * time.Millisecond,
+		}),
+		Entry("normal", "200ms~50ms", httpserver.Latency{
+			Duration:     200 * time.Millisecond,
+			Jitter:       50 * time.Millisecond,
+			Distribution: httpserver.NormalLatency,
+		}),
+	)
+
+	DescribeTable("errors", func(text string) {
+		_, err := httpserver.ParseLatency(text)
+		Expect(err).To(MatchError(ContainSubstring("invalid latency")))
+	},
+		Entry("empty", ""),
+		Entry("negative", "-5ms"),
+		Entry("reversed range", "500ms-100ms"),
+		Entry("no unit", "200~50"),
+	)
+})
+
+var _ = Describe("HandleSpec", func() {
+
+	Context("with fault injection options", func() {
+
+		It("injects the faults", func() {
+			vpath, _ := httpclient.ParseVirtualPath("/:ping,fault_error_rate=1,fault_error_codes=418|429,fault_seed=3")
+
+			srv := httpserver.New(httpserver.WithNoAccessLog())
+			app := &cli.App{
+				Name:   "app",
+				Uses:   cli.Pipeline(httpserver.ContextValue(srv), httpserver.HandlerRegistry),
+				Action: httpserver.HandleSpec(vpath, httpserver.RegistryHandlerSpec("handlers")),
+			}
+			Expect(app.RunContext(context.Background(), []string{"app"})).To(Succeed())
+
+			Expect(serveRequest(srv.Handler, newRequest("GET", "/")).Code).To(BeElementOf(http.StatusTeapot, http.StatusTooManyRequests))
+		})
+
+		It("returns an error for an invalid option", func() {
+			vpath, _ := httpclient.ParseVirtualPath("/:ping,fault_latency=soon")
+			app := &cli.App{
+				Name:   "app",
+				Uses:   cli.Pipeline(httpserver.ContextValue(httpserver.New()), httpserver.HandlerRegistry),
+				Action: httpserver.HandleSpec(vpath, httpserver.RegistryHandlerSpec("handlers")),
+			}
+			err := app.RunContext(context.Background(), []string{"app"})
+			Expect(err).To(MatchError(`/: fault_latency: invalid latency "soon"`))
+		})
+	})
+})
@@ -0,0 +1,246 @@
+// Copyright 2026 The Joe-cli Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package httpserver
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// FileServerOptions configures how static files are served
+type FileServerOptions struct {
+	// HideDirectoryListing prevents directories from being listed
+	HideDirectoryListing bool
+
+	// SPA is the file, such as index.html, that is served for requests
+	// that don't match a file so that a single-page application can handle
+	// its own routes.  Requests for names with an extension other than .html
+	// are not served the file so that missing assets are still reported.
+	SPA string
+
+	// NotFound is the file, such as 404.html, that is served with the status
+	// 404 Not Found for requests that don't match a file
+	NotFound string
+
+	// CacheControl are the rules that set the Cache-Control header of files.
+	// The first rule that matches is used.
+	CacheControl []CacheControlRule
+}
+
+// CacheControlRule sets the Cache-Control header for files that match a glob
+// pattern.  A pattern which doesn't contain a slash matches the base name of
+// the file, as in *.js.  Otherwise, it matches the path, as in assets/*.
+type CacheControlRule struct {
+	Pattern string
+	Value   string
+}
+
+type fileServer struct {
+	fsys  http.Dir
+	files http.Handler
+	opts  FileServerOptions
+}
+
+// cacheControlImmutable is the value used for the keyword immutable, suitable
+// for assets that have a hash in their name
+const cacheControlImmutable = "public, max-age=31536000, immutable"
+
+// Names of the virtual path options which configure the file server
+const (
+	fileSPAOption          = "spa"
+	fileNotFoundOption     = "not_found"
+	fileCacheControlOption = "cache_control"
+)
+
+func newFileServerHandler(staticDir string, opts FileServerOptions) http.Handler {
+	fsys := http.Dir(staticDir)
+	return &fileServer{
+		fsys:  fsys,
+		files: withPrecompressed(fsys, http.FileServer(fsys)),
+		opts:  opts,
+	}
+}
+
+func (f *fileServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name := path.Clean("/" + r.URL.Path)
+	if f.opts.HideDirectoryListing && strings.HasSuffix(r.URL.Path, "/") {
+		if !f.serveNotFound(w, r) {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		}
+		return
+	}
+
+	if !f.exists(name) {
+		if f.useSPA(r, name) && f.serveFile(w, r, f.opts.SPA) {
+			return
+		}
+		if f.serveNotFound(w, r) {
+			return
+		}
+	}
+
+	f.setCacheControl(w, name)
+	f.files.ServeHTTP(w, r)
+}
+
+func (f *fileServer) exists(name string) bool {
+	file, err := f.fsys.Open(name)
+	if err != nil {
+		return false
+	}
+	file.Close()
+	return true
+}
+
+func (f *fileServer) useSPA(r *http.Request, name string) bool {
+	if f.opts.SPA == "" || (r.Method != http.MethodGet && r.Method != http.MethodHead) {
+		return false
+	}
+	ext := path.Ext(name)
+	return ext == "" || ext == ".html"
+}
+
+// serveFile serves the file instead of the one requested.  It returns false
+// if the file doesn't exist.
+func (f *fileServer) serveFile(w http.ResponseWriter, r *http.Request, name string) bool {
+	name = path.Clean("/" + name)
+	file, err := f.fsys.Open(name)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil || info.IsDir() {
+		return false
+	}
+	f.setCacheControl(w, name)
+	http.ServeContent(w, r, info.Name(), info.ModTime(), file)
+	return true
+}
+
+// serveNotFound serves the not found page.  It returns false if there is
+// no not found page.
+func (f *fileServer) serveNotFound(w http.ResponseWriter, r *http.Request) bool {
+	if f.opts.NotFound == "" {
+		return false
+	}
+	file, err := f.fsys.Open(path.Clean("/" + f.opts.NotFound))
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil || info.IsDir() {
+		return false
+	}
+
+	contentType := mime.TypeByExtension(path.Ext(info.Name()))
+	if contentType == "" {
+		contentType = "text/html; charset=utf-8"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(info.Size(), 10))
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusNotFound)
+	if r.Method != http.MethodHead {
+		io.Copy(w, file)
+	}
+	return true
+}
+
+func (f *fileServer) setCacheControl(w http.ResponseWriter, name string) {
+	for _, rule := range f.opts.CacheControl {
+		if rule.Matches(name) {
+			w.Header().Set("Cache-Control", rule.Value)
+			return
+		}
+	}
+}
+
+// pathFileServerOptions applies the options of a virtual path to the file server
+// options
+func pathFileServerOptions(opts map[string]string, result *FileServerOptions) error {
+	for k, v := range opts {
+		switch k {
+		case "hide_directory_listing":
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return fmt.Errorf("%s: %w", k, err)
+			}
+			result.HideDirectoryListing = b
+		case fileSPAOption:
+			result.SPA = v
+		case fileNotFoundOption:
+			result.NotFound = v
+		case fileCacheControlOption:
+			rules, err := parseCacheControlRules(v)
+			if err != nil {
+				return fmt.Errorf("%s: %w", k, err)
+			}
+			result.CacheControl = append(rules, result.CacheControl...)
+		}
+	}
+	return nil
+}
+
+// parseCacheControlRules parses rules separated by semicolons, because commas
+// separate the options of a virtual path, as in *.js=immutable;*.html=no-cache
+func parseCacheControlRules(s string) ([]CacheControlRule, error) {
+	var rules []CacheControlRule
+	for part := range strings.SplitSeq(s, ";") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		rule, err := ParseCacheControlRule(part)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// ParseCacheControlRule parses a rule from a glob pattern and the value of the
+// Cache-Control header, as in *.js=max-age=3600.  The value immutable is
+// short for public, max-age=31536000, immutable.
+func ParseCacheControlRule(s string) (CacheControlRule, error) {
+	pattern, value, ok := strings.Cut(strings.TrimSpace(s), "=")
+	pattern, value = strings.TrimSpace(pattern), strings.TrimSpace(value)
+	if !ok || pattern == "" || value == "" {
+		return CacheControlRule{}, fmt.Errorf("invalid cache control rule %q; expected GLOB=VALUE", s)
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return CacheControlRule{}, fmt.Errorf("invalid cache control rule %q: %w", s, err)
+	}
+	if value == "immutable" {
+		value = cacheControlImmutable
+	}
+	return CacheControlRule{Pattern: pattern, Value: value}, nil
+}
+
+// Matches determines whether the rule applies to the file name, which is a
+// path that starts with a slash
+func (r CacheControlRule) Matches(name string) bool {
+	subject := path.Base(name)
+	if strings.Contains(r.Pattern, "/") {
+		subject = strings.TrimPrefix(name, "/")
+	}
+	ok, err := path.Match(strings.TrimPrefix(r.Pattern, "/"), subject)
+	return ok && err == nil
+}
+
+func (r CacheControlRule) String() string {
+	if r.Pattern == "" {
+		return ""
+	}
+	return r.Pattern + "=" + r.Value
+}
@@ -0,0 +1,165 @@
+// Copyright 2026 The Joe-cli Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package httpserver_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+
+	"github.com/Carbonfrost/joe-cli"
+	"github.com/Carbonfrost/joe-cli-http/httpclient"
+	"github.com/Carbonfrost/joe-cli-http/httpserver"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FileServerHandlerSpec", func() {
+
+	var dir string
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+		writeFile := func(name, content string) {
+			name = filepath.Join(dir, name)
+			Expect(os.MkdirAll(filepath.Dir(name), 0o755)).To(Succeed())
+			Expect(os.WriteFile(name, []byte(content), 0o644)).To(Succeed())
+		}
+		writeFile("index.html", "<app>")
+		writeFile("404.html", "<missing>")
+		writeFile("assets/app.1234.js", "// app")
+		writeFile("docs/readme.txt", "readme")
+	})
+
+	serve := func(options map[string]string, method, path string) *httptest.ResponseRecorder {
+		h, err := httpserver.FileServerHandlerSpec()(context.Background(), httpclient.VirtualPath{
+			RequestPath:  "/",
+			PhysicalPath: dir,
+			Options:      options,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		return serveRequest(h, newRequest(method, path))
+	}
+
+	Describe("spa", func() {
+
+		options := map[string]string{"spa": "index.html"}
+
+		DescribeTable("examples", func(method, path string, expectedStatus int, expectedBody string) {
+			recorder := serve(options, method, path)
+			Expect(recorder.Code).To(Equal(expectedStatus))
+			Expect(recorder.Body.String()).To(ContainSubstring(expectedBody))
+		},
+			Entry("client-side route", "GET", "/users/42", http.StatusOK, "<app>"),
+			Entry("HTML page", "GET", "/about.html", http.StatusOK, "<app>"),
+			Entry("file that exists", "GET", "/docs/readme.txt", http.StatusOK, "readme"),
+			Entry("missing asset", "GET", "/assets/missing.js", http.StatusNotFound, "404 page not found"),
+			Entry("other method", "POST", "/users/42", http.StatusNotFound, "404 page not found"),
+		)
+	})
+
+	Describe("not_found", func() {
+
+		It("serves the page with 404", func() {
+			recorder := serve(map[string]string{"not_found": "404.html"}, "GET", "/missing.js")
+			Expect(recorder.Code).To(Equal(http.StatusNotFound))
+			Expect(recorder.Header().Get("Content-Type")).To(HavePrefix("text/html"))
+			Expect(recorder.Body.String()).To(Equal("<missing>"))
+		})
+
+		It("serves the page for hidden directory listings", func() {
+			recorder := serve(map[string]string{
+				"not_found":              "404.html",
+				"hide_directory_listing": "true",
+			}, "GET", "/docs/")
+			Expect(recorder.Code).To(Equal(http.StatusNotFound))
+			Expect(recorder.Body.String()).To(Equal("<missing>"))
+		})
+
+		It("is used for assets that are missing with spa", func() {
+			recorder := serve(map[string]string{"not_found": "404.html", "spa": "index.html"}, "GET", "/assets/missing.js")
+			Expect(recorder.Code).To(Equal(http.StatusNotFound))
+			Expect(recorder.Body.String()).To(Equal("<missing>"))
+		})
+	})
+
+	Describe("cache_control", func() {
+
+		options := map[string]string{
+			"spa":           "index.html",
+			"cache_control": "assets/*=immutable;*.html=no-cache",
+		}
+
+		DescribeTable("examples", func(path string, expected string) {
+			Expect(serve(options, "GET", path).Header().Get("Cache-Control")).To(Equal(expected))
+		},
+			Entry("glob with path", "/assets/app.1234.js", "public, max-age=31536000, immutable"),
+			Entry("glob with base name", "/index.html", "no-cache"),
+			Entry("spa file", "/users/42", "no-cache"),
+			Entry("no match", "/docs/readme.txt", ""),
+		)
+
+		It("returns an error for an invalid rule", func() {
+			_, err := httpserver.FileServerHandlerSpec()(context.Background(), httpclient.VirtualPath{
+				RequestPath:  "/",
+				PhysicalPath: dir,
+				Options:      map[string]string{"cache_control": "*.js"},
+			})
+			Expect(err).To(MatchError(`cache_control: invalid cache control rule "*.js"; expected GLOB=VALUE`))
+		})
+	})
+
+	It("uses the options of the server as defaults", func() {
+		srv := httpserver.New(
+			httpserver.WithNoAccessLog(),
+			httpserver.WithSPA("index.html"),
+			httpserver.AddCacheControl("*.html=no-store"),
+		)
+		app := &cli.App{
+			Name: "app",
+			Uses: httpserver.ContextValue(srv),
+			Action: httpserver.HandleSpec(
+				httpclient.VirtualPath{RequestPath: "/", PhysicalPath: dir},
+				httpserver.FileServerHandlerSpec(),
+			),
+		}
+		Expect(app.RunContext(context.Background(), []string{"app"})).To(Succeed())
+
+		recorder := serveRequest(srv.Handler, newRequest("GET", "/users/42"))
+		Expect(recorder.Body.String()).To(Equal("<app>"))
+		Expect(recorder.Header().Get("Cache-Control")).To(Equal("no-store"))
+	})
+})
+
+var _ = Describe("ParseCacheControlRule", func() {
+
+	DescribeTable("examples", func(text string, expected httpserver.CacheControlRule) {
+		Expect(httpserver.ParseCacheControlRule(text)).To(Equal(expected))
+	},
+		Entry("value", "*.css=max-age=3600", httpserver.CacheControlRule{Pattern: "*.css", Value: "max-age=3600"}),
+		Entry("immutable", "*.js=immutable", httpserver.CacheControlRule{Pattern: "*.js", Value: "public, max-age=31536000, immutable"}),
+	)
+
+	DescribeTable("errors", func(text string) {
+		_, err := httpserver.ParseCacheControlRule(text)
+		Expect(err).To(HaveOccurred())
+	},
+		Entry("missing value", "*.js"),
+		Entry("invalid glob", "[=no-cache"),
+	)
+
+	DescribeTable("Matches", func(pattern, name string, expected bool) {
+		rule := httpserver.CacheControlRule{Pattern: pattern}
+		Expect(rule.Matches(name)).To(Equal(expected))
+	},
+		Entry("base name", "*.js", "/assets/app.js", true),
+		Entry("path", "assets/*.js", "/assets/app.js", true),
+		Entry("path with leading slash", "/assets/*.js", "/assets/app.js", true),
+		Entry("different directory", "assets/*.js", "/vendor/app.js", false),
+	)
+})
@@ -12,7 +12,6 @@ import (
 	"maps"
 	"net/http"
 	"strconv"
-	"strings"
 	"sync"
 	"time"
 
@@ -49,7 +48,10 @@ var HandlerRegistry = &provider.Registry{
 			HelpText: "Serve a particular directory as static files",
 			Defaults: map[string]string{
 				"directory":              ".",
-				"hide_directory_listing": "false",
+				"hide_directory_listing": "",
+				"spa":                    "",
+				"not_found":              "",
+				"cache_control":          "",
 			},
 		},
 		"reload": {
@@ -74,6 +76,42 @@ var HandlerRegistry = &provider.Registry{
 			},
 			Aliases: []string{"reflect"},
 		},
+		"httpbin": {
+			Factory:  provider.FactoryOf(newHTTPBinHandlerWithOpts),
+			HelpText: "Diagnostic endpoints such as /status/{code} and /delay/{seconds} in the style of httpbin",
+		},
+		"upload": {
+			Factory:  provider.FactoryOf(newUploadHandlerWithOpts),
+			HelpText: "Saves files uploaded using PUT or a multipart POST to a directory",
+			Defaults: map[string]string{
+				"directory": ".",
+				"max_size":  "100M",
+				"overwrite": "deny",
+			},
+		},
+		"webdav": {
+			Factory:  provider.FactoryOf(newWebDAVHandlerWithOpts),
+			HelpText: "Serve a particular directory using WebDAV so that it can be mounted",
+			Defaults: map[string]string{
+				"directory": ".",
+			},
+		},
+		"capture": {
+			Factory:  provider.FactoryOf(newCaptureHandlerWithOpts),
+			HelpText: "Captures requests so that they can be inspected at a sibling path such as /hook-inspect/",
+			Defaults: map[string]string{
+				"size":     strconv.Itoa(defaultCaptureSize),
+				"max_body": strconv.Itoa(defaultCaptureMaxBody),
+			},
+		},
+		"openapi": {
+			Factory:  provider.FactoryOf(newOpenAPIHandlerWithOpts),
+			HelpText: "Mock server for an OpenAPI 3 document which validates requests",
+			Defaults: map[string]string{
+				"spec":     "openapi.yaml",
+				"validate": "true",
+			},
+		},
 	},
 }
 
@@ -126,18 +164,21 @@ func NewHeaderMiddleware(name, value string) func(http.Handler) http.Handler {
 // FileServerHandlerSpec creates a file server.  The physical path in the virtual path
 // specifies the base directory for the file server.  An option named
 // hide_directory_listing controls whether the directory listing response is served.
-// The handler also consults the server for whether directory listings can be served.
+// The option spa names the file served for client-side routes of a single-page
+// application, and not_found names the file served with 404 Not Found.  The option
+// cache_control sets Cache-Control by glob, with rules separated by semicolons, as in
+// *.js=immutable;*.html=no-cache.  The handler also consults the server for the
+// defaults of these options.
 func FileServerHandlerSpec() HandlerSpec {
-	return func(_ context.Context, vp httpclient.VirtualPath) (http.Handler, error) {
-		dict := map[string]string{
-			"directory": vp.PhysicalPath,
+	return func(ctx context.Context, vp httpclient.VirtualPath) (http.Handler, error) {
+		var opts FileServerOptions
+		if s, ok := ctx.Value(servicesKey).(*Server); ok {
+			opts = s.FileServerOptions()
 		}
-		update(dict, vp.Options)
-		h, err := provider.FactoryOf(newFileServerHandlerWithOpts).New(dict)
-		if err != nil {
+		if err := pathFileServerOptions(vp.Options, &opts); err != nil {
 			return nil, err
 		}
-		return http.StripPrefix(vp.RequestPath, h.(http.Handler)), err
+		return http.StripPrefix(vp.RequestPath, newFileServerHandler(vp.PhysicalPath, opts)), nil
 	}
 }
 
@@ -165,19 +206,62 @@ func RegistryHandlerSpec(name string) HandlerSpec {
 	}
 }
 
-func newFileServerHandlerWithOpts(opts struct {
-	Directory            string `mapstructure:"directory"`
-	HideDirectoryListing bool   `mapstructure:"hide_directory_listing"`
-}) (http.Handler, error) {
-	return newFileServerHandler(opts.Directory, opts.HideDirectoryListing), nil
-}
+// newSpecHandler creates the handler from the spec and applies the
+// authentication and fault injection options of the virtual path
+func newSpecHandler(ctx context.Context, vpath httpclient.VirtualPath, spec HandlerSpec) (http.Handler, error) {
+	opts, auth, err := pathAuthOptions(vpath.Options)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", vpath.RequestPath, err)
+	}
+	opts, fault, err := pathFaultOptions(opts)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", vpath.RequestPath, err)
+	}
+	vpath.Options = opts
 
-func newFileServerHandler(staticDir string, hideDirListing bool) http.Handler {
-	result := http.FileServer(http.Dir(staticDir))
-	if hideDirListing {
-		result = hideListing(result)
+	handler, err := spec(ctx, vpath)
+	if err != nil {
+		return nil, err
+	}
+	if auth != nil {
+		m, err := NewAuthMiddleware(*auth)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", vpath.RequestPath, err)
+		}
+		handler = m(handler)
+	}
+	if fault != nil {
+		m, err := NewFaultMiddleware(*fault)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", vpath.RequestPath, err)
+		}
+		handler = m(handler)
 	}
-	return result
+	return handler, nil
+}
+
+func newFileServerHandlerWithOpts(opts struct {
+	Directory            string `mapstructure:"directory"`
+	HideDirectoryListing string `mapstructure:"hide_directory_listing"`
+	SPA                  string `mapstructure:"spa"`
+	NotFound             string `mapstructure:"not_found"`
+	CacheControl         string `mapstructure:"cache_control"`
+}) (HandlerSpec, error) {
+	return func(ctx context.Context, vp httpclient.VirtualPath) (http.Handler, error) {
+		vp.PhysicalPath = opts.Directory
+		vp.Options = map[string]string{}
+		for k, v := range map[string]string{
+			"hide_directory_listing": opts.HideDirectoryListing,
+			fileSPAOption:            opts.SPA,
+			fileNotFoundOption:       opts.NotFound,
+			fileCacheControlOption:   opts.CacheControl,
+		} {
+			if v != "" {
+				vp.Options[k] = v
+			}
+		}
+		return FileServerHandlerSpec()(ctx, vp)
+	}, nil
 }
 
 func newPingHandlerWithOpts(_ any) (http.Handler, error) {
@@ -190,6 +274,62 @@ func newEchoHandlerWithOpts(opts struct {
 	return NewEchoHandler(opts.Failsafe), nil
 }
 
+func newHTTPBinHandlerWithOpts(_ any) (http.Handler, error) {
+	return NewHTTPBinHandler(), nil
+}
+
+func newUploadHandlerWithOpts(opts struct {
+	Directory string `mapstructure:"directory"`
+	MaxSize   string `mapstructure:"max_size"`
+	Overwrite string `mapstructure:"overwrite"`
+}) (HandlerSpec, error) {
+	return func(ctx context.Context, vp httpclient.VirtualPath) (http.Handler, error) {
+		vp.PhysicalPath = opts.Directory
+		vp.Options = map[string]string{
+			"max_size":  opts.MaxSize,
+			"overwrite": opts.Overwrite,
+		}
+		return UploadHandlerSpec()(ctx, vp)
+	}, nil
+}
+
+func newWebDAVHandlerWithOpts(opts struct {
+	Directory string `mapstructure:"directory"`
+}) (HandlerSpec, error) {
+	return func(ctx context.Context, vp httpclient.VirtualPath) (http.Handler, error) {
+		vp.PhysicalPath = opts.Directory
+		return WebDAVHandlerSpec()(ctx, vp)
+	}, nil
+}
+
+func newCaptureHandlerWithOpts(opts struct {
+	Size    int    `mapstructure:"size"`
+	MaxBody int    `mapstructure:"max_body"`
+	Inspect string `mapstructure:"inspect"`
+}) (HandlerSpec, error) {
+	return func(ctx context.Context, vp httpclient.VirtualPath) (http.Handler, error) {
+		vp.Options = map[string]string{
+			"size":     strconv.Itoa(opts.Size),
+			"max_body": strconv.Itoa(opts.MaxBody),
+			"inspect":  opts.Inspect,
+		}
+		return CaptureHandlerSpec()(ctx, vp)
+	}, nil
+}
+
+func newOpenAPIHandlerWithOpts(opts struct {
+	Spec     string `mapstructure:"spec"`
+	Validate bool   `mapstructure:"validate"`
+}) (HandlerSpec, error) {
+	return func(ctx context.Context, vp httpclient.VirtualPath) (http.Handler, error) {
+		vp.PhysicalPath = opts.Spec
+		vp.Options = map[string]string{
+			"validate": strconv.FormatBool(opts.Validate),
+		}
+		return OpenAPIHandlerSpec()(ctx, vp)
+	}, nil
+}
+
 func newRedirectServerHandlerWithOpts(opts struct {
 	To   string `mapstructure:"to"`
 	Code int    `mapstructure:"code"`
@@ -213,6 +353,7 @@ func newRequestLoggerHandler(out io.Writer, next http.Handler, format *expander.
 func (h *requestLoggerHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	ww := newWrapResponseWriter(w, r.ProtoMajor)
 	t1 := time.Now()
+	r = withRequestInfo(r)
 
 	h.next.ServeHTTP(ww, r)
 
@@ -243,6 +384,11 @@ func ExpandRequest(r *http.Request, ww wrapResponseWriter) expander.Interface {
 			return fmt.Sprint(ww.Status(), " ", http.StatusText(ww.Status()))
 		case "urlPath":
 			return r.URL.Path
+		case "user":
+			if user := RequestUser(r); user != "" {
+				return user
+			}
+			return "-"
 		case "header":
 			var buf bytes.Buffer
 			ww.Header().Write(&buf)
@@ -260,17 +406,6 @@ func expandTiming(start, end time.Time) expander.Interface {
 	})
 }
 
-func hideListing(next http.Handler) http.HandlerFunc {
-	return func(w http.ResponseWriter, req *http.Request) {
-		if strings.HasSuffix(req.URL.Path, "/") {
-			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
-			return
-		}
-
-		next.ServeHTTP(w, req)
-	}
-}
-
 func update(dst, src map[string]string) {
 	maps.Copy(dst, src)
 }
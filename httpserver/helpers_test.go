@@ -0,0 +1,34 @@
+// Copyright 2026 The Joe-cli Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package httpserver_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+)
+
+// serveRequest serves the request using the handler and returns what was
+// recorded
+func serveRequest(h http.Handler, req *http.Request) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	h.ServeHTTP(recorder, req)
+	return recorder
+}
+
+// newRequest creates a request with the headers, which are specified as
+// pairs of names and values
+func newRequest(method, target string, headers ...string) *http.Request {
+	return newBodyRequest(method, target, nil, headers...)
+}
+
+// newBodyRequest creates a request with the body and headers
+func newBodyRequest(method, target string, body io.Reader, headers ...string) *http.Request {
+	req := httptest.NewRequest(method, target, body)
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	return req
+}
@@ -0,0 +1,161 @@
+// Copyright 2026 The Joe-cli Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package httpserver
+
+import (
+	"bufio"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// PasswordVerifier checks the password of a user
+type PasswordVerifier interface {
+	VerifyPassword(user, password string) bool
+}
+
+// HTPasswd contains the users and password hashes from an htpasswd file.
+// The bcrypt ($2y$), SHA-1 ({SHA}), and Apache MD5 ($apr1$) hash formats
+// are supported.
+type HTPasswd map[string]string
+
+// StaticPasswords is a map from user names to passwords in plain text
+type StaticPasswords map[string]string
+
+const apr1Alphabet = "./0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// LoadHTPasswd reads an htpasswd file
+func LoadHTPasswd(filename string) (HTPasswd, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	res := HTPasswd{}
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		user, hash, ok := strings.Cut(text, ":")
+		if !ok || user == "" {
+			return nil, fmt.Errorf("%s:%d: expected user:hash", filename, line)
+		}
+		if !isSupportedHash(hash) {
+			return nil, fmt.Errorf("%s:%d: unsupported password hash for user %q", filename, line, user)
+		}
+		res[user] = hash
+	}
+	return res, scanner.Err()
+}
+
+// VerifyPassword checks the password against the hash for the user
+func (h HTPasswd) VerifyPassword(user, password string) bool {
+	hash, ok := h[user]
+	if !ok {
+		return false
+	}
+
+	switch {
+	case strings.HasPrefix(hash, "$2"):
+		return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+	case strings.HasPrefix(hash, "{SHA}"):
+		sum := sha1.Sum([]byte(password))
+		return secureEqual(hash[len("{SHA}"):], base64.StdEncoding.EncodeToString(sum[:]))
+	case strings.HasPrefix(hash, "$apr1$"):
+		salt, _, _ := strings.Cut(hash[len("$apr1$"):], "$")
+		return secureEqual(hash, apr1(password, salt))
+	}
+	return false
+}
+
+// VerifyPassword checks the password of the user
+func (p StaticPasswords) VerifyPassword(user, password string) bool {
+	expected, ok := p[user]
+	return ok && secureEqual(expected, password)
+}
+
+func isSupportedHash(hash string) bool {
+	for _, prefix := range []string{"$2a$", "$2b$", "$2y$", "{SHA}", "$apr1$"} {
+		if strings.HasPrefix(hash, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func secureEqual(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+// apr1 computes the Apache variant of the MD5-based crypt(3) hash
+func apr1(password, salt string) string {
+	const magic = "$apr1$"
+	if len(salt) > 8 {
+		salt = salt[:8]
+	}
+
+	alt := md5.Sum([]byte(password + salt + password))
+
+	ctx := md5.New()
+	ctx.Write([]byte(password + magic + salt))
+	for i := len(password); i > 0; i -= 16 {
+		ctx.Write(alt[:min(i, 16)])
+	}
+	for i := len(password); i > 0; i >>= 1 {
+		if i&1 == 1 {
+			ctx.Write([]byte{0})
+		} else {
+			ctx.Write([]byte{password[0]})
+		}
+	}
+	sum := ctx.Sum(nil)
+
+	for i := range 1000 {
+		ctx := md5.New()
+		if i&1 == 1 {
+			ctx.Write([]byte(password))
+		} else {
+			ctx.Write(sum)
+		}
+		if i%3 != 0 {
+			ctx.Write([]byte(salt))
+		}
+		if i%7 != 0 {
+			ctx.Write([]byte(password))
+		}
+		if i&1 == 1 {
+			ctx.Write(sum)
+		} else {
+			ctx.Write([]byte(password))
+		}
+		sum = ctx.Sum(nil)
+	}
+
+	var b strings.Builder
+	b.WriteString(magic + salt + "$")
+	encode := func(a, c, d byte, n int) {
+		v := uint(a)<<16 | uint(c)<<8 | uint(d)
+		for range n {
+			b.WriteByte(apr1Alphabet[v&0x3f])
+			v >>= 6
+		}
+	}
+	encode(sum[0], sum[6], sum[12], 4)
+	encode(sum[1], sum[7], sum[13], 4)
+	encode(sum[2], sum[8], sum[14], 4)
+	encode(sum[3], sum[9], sum[15], 4)
+	encode(sum[4], sum[10], sum[5], 4)
+	encode(0, 0, sum[11], 2)
+	return b.String()
+}
@@ -0,0 +1,395 @@
+// Copyright 2026 The Joe-cli Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package httpserver
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Limits on the httpbin endpoints so that a request can't tie up the server
+const (
+	httpbinMaxBytes    = 10 << 20
+	httpbinMaxDelay    = 10 * time.Second
+	httpbinMaxDuration = time.Minute
+	httpbinMaxStream   = 100
+	httpbinMaxRedirect = 100
+)
+
+type httpbinHandler struct {
+	mux  *http.ServeMux
+	echo http.Handler
+}
+
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	w io.Writer
+}
+
+// NewHTTPBinHandler provides a handler with diagnostic endpoints in the style
+// of httpbin.  The endpoints are relative to where the handler is mounted:
+//
+//	/get                  reflects the request
+//	/status/{code}        responds with the status code, or one chosen randomly
+//	                      from a list such as 200,500
+//	/delay/{seconds}      reflects the request after a delay
+//	/bytes/{n}            responds with n random bytes; seed=N is supported
+//	/stream/{n}           streams n lines of JSON
+//	/drip                 drips bytes over time; duration, numbytes, code,
+//	                      and delay are supported
+//	/redirect/{n}         redirects n times before responding with /get
+//	/cookies              reflects the cookies
+//	/cookies/set          sets the cookies in the query, then redirects to /cookies
+//	/basic-auth/{u}/{p}   requires basic authentication with the user and password
+//	/gzip                 reflects the request using gzip encoding
+//	/etag/{etag}          responds using the ETag, If-None-Match, and If-Match
+//	/range/{n}            responds with n bytes, supporting range requests
+func NewHTTPBinHandler() http.Handler {
+	h := &httpbinHandler{
+		mux:  http.NewServeMux(),
+		echo: NewEchoHandler(true),
+	}
+
+	h.mux.Handle("/get", h.echo)
+	h.mux.HandleFunc("/status/{code}", h.status)
+	h.mux.HandleFunc("/delay/{seconds}", h.delay)
+	h.mux.HandleFunc("/bytes/{n}", h.bytes)
+	h.mux.HandleFunc("/stream/{n}", h.stream)
+	h.mux.HandleFunc("/drip", h.drip)
+	h.mux.HandleFunc("/redirect/{n}", h.redirect)
+	h.mux.HandleFunc("/cookies", h.cookies)
+	h.mux.HandleFunc("/cookies/set", h.setCookies)
+	h.mux.HandleFunc("/basic-auth/{user}/{password}", h.basicAuth)
+	h.mux.HandleFunc("/gzip", h.gzip)
+	h.mux.HandleFunc("/etag/{etag}", h.etag)
+	h.mux.HandleFunc("/range/{n}", h.byteRange)
+	return h
+}
+
+func (h *httpbinHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// When mounted under a path like /bin/, the stripped path is relative
+	if !strings.HasPrefix(r.URL.Path, "/") {
+		r2 := new(http.Request)
+		*r2 = *r
+		u := *r.URL
+		u.Path = "/" + u.Path
+		u.RawPath = ""
+		r2.URL = &u
+		r = r2
+	}
+	h.mux.ServeHTTP(w, r)
+}
+
+func (h *httpbinHandler) status(w http.ResponseWriter, r *http.Request) {
+	codes := strings.Split(r.PathValue("code"), ",")
+	code, err := strconv.Atoi(codes[rand.IntN(len(codes))])
+	if err != nil || code < 100 || code > 999 {
+		http.Error(w, "invalid status code", http.StatusBadRequest)
+		return
+	}
+
+	switch code {
+	case http.StatusUnauthorized:
+		w.Header().Set("WWW-Authenticate", `Basic realm="httpbin"`)
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		w.Header().Set("Retry-After", "1")
+	}
+	if code >= 300 && code < 400 && code != http.StatusNotModified {
+		w.Header().Set("Location", redirectTarget(1))
+	}
+	w.WriteHeader(code)
+}
+
+func (h *httpbinHandler) delay(w http.ResponseWriter, r *http.Request) {
+	seconds, err := strconv.ParseFloat(r.PathValue("seconds"), 64)
+	if err != nil || seconds < 0 {
+		http.Error(w, "invalid delay", http.StatusBadRequest)
+		return
+	}
+
+	d := min(time.Duration(seconds*float64(time.Second)), httpbinMaxDelay)
+	if !sleepContext(r, d) {
+		return
+	}
+	h.echo.ServeHTTP(w, r)
+}
+
+func (h *httpbinHandler) bytes(w http.ResponseWriter, r *http.Request) {
+	n, ok := pathCount(w, r, httpbinMaxBytes)
+	if !ok {
+		return
+	}
+
+	rng := rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64()))
+	if s := r.URL.Query().Get("seed"); s != "" {
+		seed, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			http.Error(w, "invalid seed", http.StatusBadRequest)
+			return
+		}
+		rng = rand.New(rand.NewPCG(seed, seed))
+	}
+
+	data := make([]byte, n)
+	for i := range data {
+		data[i] = byte(rng.UintN(256))
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Length", strconv.Itoa(n))
+	w.Write(data)
+}
+
+func (h *httpbinHandler) stream(w http.ResponseWriter, r *http.Request) {
+	n, ok := pathCount(w, r, httpbinMaxStream)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	rc := http.NewResponseController(w)
+	enc := json.NewEncoder(w)
+	for i := range n {
+		err := enc.Encode(map[string]any{
+			"id":      i,
+			"url":     r.URL.String(),
+			"headers": r.Header,
+		})
+		if err != nil {
+			return
+		}
+		rc.Flush()
+	}
+}
+
+func (h *httpbinHandler) drip(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	duration, err1 := queryDuration(q.Get("duration"), 2*time.Second)
+	delay, err2 := queryDuration(q.Get("delay"), 0)
+	numBytes, err3 := queryInt(q.Get("numbytes"), 10)
+	code, err4 := queryInt(q.Get("code"), http.StatusOK)
+	if err := firstError(err1, err2, err3, err4); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if numBytes < 0 || numBytes > httpbinMaxBytes || code < 100 || code > 999 {
+		http.Error(w, "invalid drip", http.StatusBadRequest)
+		return
+	}
+	duration = min(duration, httpbinMaxDuration)
+
+	if !sleepContext(r, min(delay, httpbinMaxDelay)) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Length", strconv.Itoa(numBytes))
+	w.WriteHeader(code)
+
+	rc := http.NewResponseController(w)
+	var interval time.Duration
+	if numBytes > 0 {
+		interval = duration / time.Duration(numBytes)
+	}
+	for i := range numBytes {
+		if i > 0 && !sleepContext(r, interval) {
+			return
+		}
+		if _, err := w.Write([]byte{'*'}); err != nil {
+			return
+		}
+		rc.Flush()
+	}
+}
+
+func (h *httpbinHandler) redirect(w http.ResponseWriter, r *http.Request) {
+	n, ok := pathCount(w, r, httpbinMaxRedirect)
+	if !ok {
+		return
+	}
+	if n == 0 {
+		http.Error(w, "invalid redirect count", http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Location", redirectTarget(n))
+	w.WriteHeader(http.StatusFound)
+}
+
+func (h *httpbinHandler) cookies(w http.ResponseWriter, r *http.Request) {
+	result := map[string]string{}
+	for _, c := range r.Cookies() {
+		result[c.Name] = c.Value
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"cookies": result})
+}
+
+func (h *httpbinHandler) setCookies(w http.ResponseWriter, r *http.Request) {
+	for name, values := range r.URL.Query() {
+		http.SetCookie(w, &http.Cookie{
+			Name:  name,
+			Value: values[0],
+			Path:  "/",
+		})
+	}
+
+	// The location is relative so that it works wherever the handler is mounted
+	w.Header().Set("Location", "../cookies")
+	w.WriteHeader(http.StatusFound)
+}
+
+func (h *httpbinHandler) basicAuth(w http.ResponseWriter, r *http.Request) {
+	wantUser, wantPassword := r.PathValue("user"), r.PathValue("password")
+	user, password, ok := r.BasicAuth()
+	if !ok || !secureEqual(user, wantUser) || !secureEqual(password, wantPassword) {
+		w.Header().Set("WWW-Authenticate", `Basic realm="httpbin"`)
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"authenticated": true,
+		"user":          user,
+	})
+}
+
+func (h *httpbinHandler) gzip(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Encoding", "gzip")
+	w.Header().Add("Vary", "Accept-Encoding")
+
+	gz := gzip.NewWriter(w)
+	defer gz.Close()
+	h.echo.ServeHTTP(&gzipResponseWriter{ResponseWriter: w, w: gz}, r)
+}
+
+func (h *httpbinHandler) etag(w http.ResponseWriter, r *http.Request) {
+	etag := r.PathValue("etag")
+	quoted := strconv.Quote(etag)
+	w.Header().Set("ETag", quoted)
+
+	if match := r.Header.Get("If-None-Match"); match != "" {
+		if etagMatches(match, etag) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	} else if match := r.Header.Get("If-Match"); match != "" && !etagMatches(match, etag) {
+		w.WriteHeader(http.StatusPreconditionFailed)
+		return
+	}
+	h.echo.ServeHTTP(w, r)
+}
+
+func (h *httpbinHandler) byteRange(w http.ResponseWriter, r *http.Request) {
+	n, ok := pathCount(w, r, httpbinMaxBytes)
+	if !ok {
+		return
+	}
+
+	data := make([]byte, n)
+	for i := range data {
+		data[i] = 'a' + byte(i%26)
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("ETag", fmt.Sprintf(`"range%d"`, n))
+	http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(data))
+}
+
+func (w *gzipResponseWriter) Write(b []byte) (int, error) {
+	return w.w.Write(b)
+}
+
+func (w *gzipResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// redirectTarget gets the relative location of the next redirect so that it
+// works wherever the handler is mounted
+func redirectTarget(n int) string {
+	if n <= 1 {
+		return "../get"
+	}
+	return strconv.Itoa(n - 1)
+}
+
+func etagMatches(header, etag string) bool {
+	for v := range strings.SplitSeq(header, ",") {
+		v = strings.TrimPrefix(strings.TrimSpace(v), "W/")
+		if v == "*" || strings.Trim(v, `"`) == etag {
+			return true
+		}
+	}
+	return false
+}
+
+func pathCount(w http.ResponseWriter, r *http.Request, limit int) (int, bool) {
+	n, err := strconv.Atoi(r.PathValue("n"))
+	if err != nil || n < 0 || n > limit {
+		http.Error(w, fmt.Sprintf("count must be between 0 and %d", limit), http.StatusBadRequest)
+		return 0, false
+	}
+	return n, true
+}
+
+// sleepContext waits for the duration unless the request is canceled first
+func sleepContext(r *http.Request, d time.Duration) bool {
+	if d <= 0 {
+		return true
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-r.Context().Done():
+		return false
+	}
+}
+
+// queryDuration parses a duration in seconds, as in 1.5, or with a unit, as in 250ms
+func queryDuration(s string, def time.Duration) (time.Duration, error) {
+	if s == "" {
+		return def, nil
+	}
+	if seconds, err := strconv.ParseFloat(s, 64); err == nil && seconds >= 0 {
+		return time.Duration(seconds * float64(time.Second)), nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	return d, nil
+}
+
+func queryInt(s string, def int) (int, error) {
+	if s == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %q", s)
+	}
+	return n, nil
+}
+
+func firstError(errs ...error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	data, _ := json.MarshalIndent(v, "", "  ")
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(append(data, '\n'))
+}
@@ -0,0 +1,125 @@
+// Copyright 2026 The Joe-cli Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package httpserver_test
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/Carbonfrost/joe-cli-http/httpserver"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("NewHTTPBinHandler", func() {
+
+	serve := func(method, path string, headers ...string) *httptest.ResponseRecorder {
+		h := http.StripPrefix("/bin/", httpserver.NewHTTPBinHandler())
+		return serveRequest(h, newRequest(method, path, headers...))
+	}
+
+	DescribeTable("status", func(path string, expected int, location string) {
+		recorder := serve("GET", path)
+		Expect(recorder.Code).To(Equal(expected))
+		Expect(recorder.Header().Get("Location")).To(Equal(location))
+	},
+		Entry("success", "/bin/status/204", http.StatusNoContent, ""),
+		Entry("error", "/bin/status/503", http.StatusServiceUnavailable, ""),
+		Entry("redirect", "/bin/status/302", http.StatusFound, "../get"),
+		Entry("invalid", "/bin/status/abc", http.StatusBadRequest, ""),
+	)
+
+	DescribeTable("redirect", func(path string, location string) {
+		recorder := serve("GET", path)
+		Expect(recorder.Code).To(Equal(http.StatusFound))
+		Expect(recorder.Header().Get("Location")).To(Equal(location))
+	},
+		Entry("next", "/bin/redirect/3", "2"),
+		Entry("last", "/bin/redirect/1", "../get"),
+	)
+
+	It("responds with the random bytes for the seed", func() {
+		first := serve("GET", "/bin/bytes/64?seed=7")
+		Expect(first.Body.Len()).To(Equal(64))
+		Expect(serve("GET", "/bin/bytes/64?seed=7").Body.Bytes()).To(Equal(first.Body.Bytes()))
+	})
+
+	It("responds with 400 when the count is over the limit", func() {
+		Expect(serve("GET", "/bin/bytes/999999999").Code).To(Equal(http.StatusBadRequest))
+	})
+
+	It("streams lines of JSON", func() {
+		lines := strings.Split(strings.TrimSpace(serve("GET", "/bin/stream/3").Body.String()), "\n")
+		Expect(lines).To(HaveLen(3))
+		Expect(lines[2]).To(ContainSubstring(`"id":2`))
+	})
+
+	It("drips the bytes", func() {
+		recorder := serve("GET", "/bin/drip?numbytes=5&duration=0&code=201")
+		Expect(recorder.Code).To(Equal(http.StatusCreated))
+		Expect(recorder.Body.String()).To(Equal("*****"))
+	})
+
+	It("sets the cookies and redirects", func() {
+		recorder := serve("GET", "/bin/cookies/set?flavor=oatmeal")
+		Expect(recorder.Code).To(Equal(http.StatusFound))
+		Expect(recorder.Header().Get("Location")).To(Equal("../cookies"))
+		Expect(recorder.Header().Get("Set-Cookie")).To(HavePrefix("flavor=oatmeal"))
+
+		recorder = serve("GET", "/bin/cookies", "Cookie", "flavor=oatmeal")
+		Expect(recorder.Body.String()).To(MatchJSON(`{"cookies": {"flavor": "oatmeal"}}`))
+	})
+
+	Describe("basic-auth", func() {
+
+		It("requires the credentials", func() {
+			recorder := serve("GET", "/bin/basic-auth/alice/secret")
+			Expect(recorder.Code).To(Equal(http.StatusUnauthorized))
+			Expect(recorder.Header().Get("WWW-Authenticate")).To(Equal(`Basic realm="httpbin"`))
+		})
+
+		It("accepts the credentials", func() {
+			recorder := serve("GET", "/bin/basic-auth/alice/secret", "Authorization", "Basic YWxpY2U6c2VjcmV0")
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Body.String()).To(MatchJSON(`{"authenticated": true, "user": "alice"}`))
+		})
+	})
+
+	It("reflects the request using gzip", func() {
+		recorder := serve("GET", "/bin/gzip")
+		Expect(recorder.Header().Get("Content-Encoding")).To(Equal("gzip"))
+
+		gz, err := gzip.NewReader(recorder.Body)
+		Expect(err).NotTo(HaveOccurred())
+		data, _ := io.ReadAll(gz)
+
+		var reflected map[string]any
+		Expect(json.Unmarshal(data, &reflected)).To(Succeed())
+		Expect(reflected).To(HaveKeyWithValue("method", "GET"))
+	})
+
+	DescribeTable("etag", func(header, value string, expected int) {
+		recorder := serve("GET", "/bin/etag/abc", header, value)
+		Expect(recorder.Code).To(Equal(expected))
+		Expect(recorder.Header().Get("ETag")).To(Equal(`"abc"`))
+	},
+		Entry("no conditions", "Accept", "*/*", http.StatusOK),
+		Entry("If-None-Match matches", "If-None-Match", `"abc"`, http.StatusNotModified),
+		Entry("If-None-Match doesn't match", "If-None-Match", `"xyz"`, http.StatusOK),
+		Entry("If-Match doesn't match", "If-Match", `"xyz"`, http.StatusPreconditionFailed),
+	)
+
+	It("responds with the range", func() {
+		recorder := serve("GET", "/bin/range/26", "Range", "bytes=2-4")
+		Expect(recorder.Code).To(Equal(http.StatusPartialContent))
+		Expect(recorder.Header().Get("Content-Range")).To(Equal("bytes 2-4/26"))
+		Expect(recorder.Body.String()).To(Equal("cde"))
+	})
+})
@@ -0,0 +1,291 @@
+// Copyright 2026 The Joe-cli Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package httpserver
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// LiveReloadPath is the request path of the server-sent events endpoint
+// which notifies browsers that files have changed
+const LiveReloadPath = "/_weave/live-reload"
+
+// LiveReloadScript is the client script injected into HTML pages, which
+// reloads the page when files change
+const LiveReloadScript = `<script>new EventSource("` + LiveReloadPath + `").addEventListener("change", function() { location.reload(); });</script>`
+
+const liveReloadDebounce = 100 * time.Millisecond
+
+// LiveReload watches directories for changes and notifies browsers using
+// server-sent events (SSE) so that they can reload.  It provides middleware
+// which serves the events endpoint at LiveReloadPath and optionally injects
+// LiveReloadScript into HTML pages.
+type LiveReload struct {
+	dirs []string
+
+	mu      sync.Mutex
+	clients map[chan string]struct{}
+	done    chan struct{}
+	stop    sync.Once
+}
+
+// NewLiveReload creates live reload support for the given directories
+func NewLiveReload(dirs ...string) *LiveReload {
+	return &LiveReload{
+		dirs:    dirs,
+		clients: map[chan string]struct{}{},
+		done:    make(chan struct{}),
+	}
+}
+
+// Watch watches the directories and their subdirectories until the context
+// is done.  Changes that occur close together are combined into one
+// notification.
+func (l *LiveReload) Watch(ctx context.Context) error {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+
+	for _, dir := range l.dirs {
+		if err := addRecursive(w, dir); err != nil {
+			return err
+		}
+	}
+
+	var (
+		pending string
+		timer   = time.NewTimer(liveReloadDebounce)
+	)
+	timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case e, ok := <-w.Events:
+			if !ok {
+				return nil
+			}
+			if e.Has(fsnotify.Create) {
+				if info, err := os.Stat(e.Name); err == nil && info.IsDir() {
+					_ = addRecursive(w, e.Name)
+				}
+			}
+			if e.Has(fsnotify.Chmod) || isHidden(filepath.Base(e.Name)) {
+				continue
+			}
+			pending = e.Name
+			timer.Reset(liveReloadDebounce)
+
+		case <-timer.C:
+			l.Notify(l.relativeName(pending))
+
+		case err, ok := <-w.Errors:
+			if !ok {
+				return nil
+			}
+			fmt.Fprintf(os.Stderr, "warning: watching files: %s\n", err)
+		}
+	}
+}
+
+// Notify sends a change event for the given file to all connected clients
+func (l *LiveReload) Notify(name string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for c := range l.clients {
+		select {
+		case c <- name:
+		default:
+			// The client already has a pending change
+		}
+	}
+}
+
+// Close disconnects the clients of the events endpoint
+func (l *LiveReload) Close() {
+	l.stop.Do(func() {
+		close(l.done)
+	})
+}
+
+// ServeHTTP serves the server-sent events endpoint
+func (l *LiveReload) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, ": connected\n\n")
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	c := l.subscribe()
+	defer l.unsubscribe(c)
+
+	for {
+		select {
+		case name := <-c:
+			fmt.Fprintf(w, "event: change\ndata: %s\n\n", filepath.ToSlash(name))
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		case <-r.Context().Done():
+			return
+		case <-l.done:
+			return
+		}
+	}
+}
+
+// Middleware provides middleware which serves the events endpoint and, if
+// inject is set, adds LiveReloadScript to HTML pages
+func (l *LiveReload) Middleware(inject bool) MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == LiveReloadPath {
+				l.ServeHTTP(w, r)
+				return
+			}
+			if !inject || r.Method != http.MethodGet {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			iw := &injectScriptWriter{ResponseWriter: w}
+			next.ServeHTTP(iw, r)
+			iw.finish()
+		})
+	}
+}
+
+// relativeName gets the name of the file relative to the directory that
+// contains it so that physical paths are not disclosed to clients
+func (l *LiveReload) relativeName(name string) string {
+	for _, dir := range l.dirs {
+		if rel, err := filepath.Rel(dir, name); err == nil && !strings.HasPrefix(rel, "..") {
+			return rel
+		}
+	}
+	return filepath.Base(name)
+}
+
+func (l *LiveReload) subscribe() chan string {
+	c := make(chan string, 1)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.clients[c] = struct{}{}
+	return c
+}
+
+func (l *LiveReload) unsubscribe(c chan string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.clients, c)
+}
+
+// injectScriptWriter buffers HTML responses so that the live reload
+// script can be added before the closing body tag
+type injectScriptWriter struct {
+	http.ResponseWriter
+	buf         *bytes.Buffer
+	code        int
+	wroteHeader bool
+}
+
+func (w *injectScriptWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	if code == http.StatusOK && isHTML(w.Header().Get("Content-Type")) && w.Header().Get("Content-Encoding") == "" {
+		w.buf = new(bytes.Buffer)
+		w.code = code
+		return
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *injectScriptWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		if w.Header().Get("Content-Type") == "" {
+			w.Header().Set("Content-Type", http.DetectContentType(b))
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.buf != nil {
+		return w.buf.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *injectScriptWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *injectScriptWriter) finish() {
+	if w.buf == nil {
+		return
+	}
+
+	body := w.buf.Bytes()
+	script := []byte(LiveReloadScript)
+	if i := bytes.LastIndex(bytes.ToLower(body), []byte("</body>")); i >= 0 {
+		body = append(body[:i:i], append(script, body[i:]...)...)
+	} else {
+		body = append(body, script...)
+	}
+
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.Header().Del("ETag")
+	w.ResponseWriter.WriteHeader(w.code)
+	w.ResponseWriter.Write(body)
+}
+
+func isHTML(contentType string) bool {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	return mediaType == "text/html"
+}
+
+func isHidden(name string) bool {
+	return len(name) > 1 && strings.HasPrefix(name, ".")
+}
+
+func addRecursive(w *fsnotify.Watcher, root string) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != root && isHidden(d.Name()) {
+			return filepath.SkipDir
+		}
+		return w.Add(path)
+	})
+}
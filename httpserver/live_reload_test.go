@@ -0,0 +1,89 @@
+// Copyright 2026 The Joe-cli Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package httpserver_test
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/Carbonfrost/joe-cli-http/httpserver"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("LiveReload", func() {
+
+	var dir string
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+		Expect(os.WriteFile(filepath.Join(dir, "index.html"), []byte("<html><body>hi</body></html>"), 0o644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(dir, "app.js"), []byte("// app"), 0o644)).To(Succeed())
+	})
+
+	Describe("Middleware", func() {
+
+		DescribeTable("examples", func(inject bool, path string, expected string) {
+			live := httpserver.NewLiveReload(dir)
+			h := live.Middleware(inject)(http.FileServer(http.Dir(dir)))
+
+			recorder := serveRequest(h, newRequest("GET", path))
+
+			Expect(recorder.Body.String()).To(Equal(expected))
+			Expect(recorder.Header().Get("Content-Length")).To(Equal(strconv.Itoa(len(expected))))
+		},
+			Entry("injects script into HTML", true, "/",
+				"<html><body>hi"+httpserver.LiveReloadScript+"</body></html>"),
+			Entry("leaves other files", true, "/app.js", "// app"),
+			Entry("no injection", false, "/", "<html><body>hi</body></html>"),
+		)
+	})
+
+	It("sends change events when files change", func() {
+		live := httpserver.NewLiveReload(dir)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() {
+			defer GinkgoRecover()
+			Expect(live.Watch(ctx)).To(Succeed())
+		}()
+
+		server := httptest.NewServer(live.Middleware(true)(http.NotFoundHandler()))
+		defer server.Close()
+		defer live.Close()
+
+		resp, err := http.Get(server.URL + httpserver.LiveReloadPath)
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.Body.Close()
+		Expect(resp.Header.Get("Content-Type")).To(Equal("text/event-stream"))
+
+		lines := make(chan string)
+		go func() {
+			scanner := bufio.NewScanner(resp.Body)
+			for scanner.Scan() {
+				lines <- scanner.Text()
+			}
+			close(lines)
+		}()
+		Eventually(lines).Should(Receive(Equal(": connected")))
+
+		// Keep writing in case the watcher has not started yet
+		Eventually(func(g Gomega) {
+			Expect(os.WriteFile(filepath.Join(dir, "app.js"), []byte("// changed"), 0o644)).To(Succeed())
+			g.Eventually(lines).Should(Receive(Equal("event: change")))
+		}).Should(Succeed())
+
+		var data string
+		Eventually(lines).Should(Receive(&data))
+		Expect(strings.TrimPrefix(data, "data: ")).To(HaveSuffix("app.js"))
+	})
+})
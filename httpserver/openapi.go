@@ -0,0 +1,477 @@
+// Copyright 2026 The Joe-cli Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package httpserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Carbonfrost/joe-cli-http/httpclient"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/legacy"
+)
+
+// OpenAPIOptions configures the OpenAPI mock server
+type OpenAPIOptions struct {
+	// BasePath is the request path where the paths of the document are
+	// served.  The servers listed in the document are not used.
+	BasePath string
+
+	// SkipValidation disables validating requests against the document
+	SkipValidation bool
+}
+
+type openAPIHandler struct {
+	doc      *openapi3.T
+	router   routers.Router
+	validate bool
+}
+
+// validationProblem is the response to a request that doesn't match the
+// OpenAPI document
+type validationProblem struct {
+	Status int               `json:"status"`
+	Title  string            `json:"title"`
+	Errors []validationError `json:"errors"`
+}
+
+type validationError struct {
+	Location string `json:"location"`
+	Message  string `json:"message"`
+}
+
+// maxExampleDepth limits how deeply examples are generated from schemas,
+// which can be recursive
+const maxExampleDepth = 8
+
+var openAPIMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+	http.MethodOptions,
+	http.MethodTrace,
+}
+
+// OpenAPIHandlerSpec creates a mock server for the OpenAPI 3 document in the
+// physical path of the virtual path.  Each operation responds with the example
+// from the document or a value generated from the schema.  Requests are
+// validated against the document unless the option validate is false.
+func OpenAPIHandlerSpec() HandlerSpec {
+	return func(_ context.Context, vp httpclient.VirtualPath) (http.Handler, error) {
+		validate := true
+		if v, ok := vp.Options["validate"]; ok {
+			var err error
+			if validate, err = strconv.ParseBool(v); err != nil {
+				return nil, fmt.Errorf("validate: %w", err)
+			}
+		}
+		return NewOpenAPIHandler(vp.PhysicalPath, OpenAPIOptions{
+			BasePath:       vp.RequestPath,
+			SkipValidation: !validate,
+		})
+	}
+}
+
+// NewOpenAPIHandler provides a mock server for the OpenAPI 3 document in the
+// file.  The Prefer request header can select the status code and the named
+// example of the response, as in Prefer: code=404, example=missing.
+func NewOpenAPIHandler(filename string, opts OpenAPIOptions) (http.Handler, error) {
+	loader := openapi3.NewLoader()
+	loader.IsExternalRefsAllowed = true
+
+	doc, err := loader.LoadFromFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	if err := doc.Validate(loader.Context); err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+
+	base := strings.TrimSuffix(opts.BasePath, "/")
+	if base == "" {
+		base = "/"
+	}
+	doc.Servers = openapi3.Servers{{URL: base}}
+
+	router, err := legacy.NewRouter(doc)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	return &openAPIHandler{
+		doc:      doc,
+		router:   router,
+		validate: !opts.SkipValidation,
+	}, nil
+}
+
+func (h *openAPIHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	route, params, err := h.router.FindRoute(r)
+	if err != nil {
+		if allow := h.allowedMethods(r); len(allow) > 0 {
+			w.Header().Set("Allow", strings.Join(allow, ", "))
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		http.NotFound(w, r)
+		return
+	}
+
+	if h.validate {
+		err := openapi3filter.ValidateRequest(r.Context(), &openapi3filter.RequestValidationInput{
+			Request:    r,
+			PathParams: params,
+			Route:      route,
+			Options: &openapi3filter.Options{
+				MultiError:         true,
+				AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+			},
+		})
+		if err != nil {
+			writeValidationProblem(w, err)
+			return
+		}
+	}
+
+	h.respond(w, r, route.Operation)
+}
+
+func (h *openAPIHandler) allowedMethods(r *http.Request) []string {
+	var allow []string
+	for _, m := range openAPIMethods {
+		req := r.Clone(r.Context())
+		req.Method = m
+		if _, _, err := h.router.FindRoute(req); err == nil {
+			allow = append(allow, m)
+		}
+	}
+	return allow
+}
+
+func (h *openAPIHandler) respond(w http.ResponseWriter, r *http.Request, op *openapi3.Operation) {
+	prefer := parsePrefer(r.Header.Get("Prefer"))
+	status, resp := selectResponse(op.Responses, prefer["code"])
+	if resp == nil {
+		w.WriteHeader(status)
+		return
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(resp.Headers)) {
+		hdr := resp.Headers[name].Value
+		if hdr == nil {
+			continue
+		}
+		v := hdr.Example
+		if v == nil {
+			v = exampleFromSchema(hdr.Schema, 0)
+		}
+		if v != nil {
+			w.Header().Set(name, fmt.Sprint(v))
+		}
+	}
+
+	contentType, media := selectContent(resp.Content, r.Header.Get("Accept"))
+	if media == nil {
+		w.WriteHeader(status)
+		return
+	}
+
+	body, err := encodeExample(contentType, exampleOf(media, prefer["example"]))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.WriteHeader(status)
+	if r.Method != http.MethodHead {
+		w.Write(body)
+	}
+}
+
+// selectResponse selects the response with the preferred status code, or
+// otherwise the first success response
+func selectResponse(responses *openapi3.Responses, preferCode string) (int, *openapi3.Response) {
+	if responses == nil {
+		return http.StatusOK, nil
+	}
+
+	m := responses.Map()
+	if ref, ok := m[preferCode]; ok {
+		code, _ := strconv.Atoi(preferCode)
+		return code, ref.Value
+	}
+
+	var codes []int
+	for k := range m {
+		if code, err := strconv.Atoi(k); err == nil {
+			codes = append(codes, code)
+		}
+	}
+	slices.Sort(codes)
+	for _, code := range codes {
+		if code >= 200 && code < 300 {
+			return code, m[strconv.Itoa(code)].Value
+		}
+	}
+	if ref, ok := m["2XX"]; ok {
+		return http.StatusOK, ref.Value
+	}
+	if ref := responses.Default(); ref != nil {
+		return http.StatusOK, ref.Value
+	}
+	if len(codes) > 0 {
+		return codes[0], m[strconv.Itoa(codes[0])].Value
+	}
+	return http.StatusOK, nil
+}
+
+// selectContent selects the media type that the Accept header allows,
+// preferring JSON
+func selectContent(content openapi3.Content, accept string) (string, *openapi3.MediaType) {
+	if len(content) == 0 {
+		return "", nil
+	}
+
+	types := slices.Sorted(maps.Keys(content))
+	if i := slices.IndexFunc(types, isJSONMediaType); i > 0 {
+		types[0], types[i] = types[i], types[0]
+	}
+
+	for part := range strings.SplitSeq(accept, ",") {
+		want, _, _ := mime.ParseMediaType(strings.TrimSpace(part))
+		for _, t := range types {
+			if mediaTypeMatches(want, t) {
+				return t, content[t]
+			}
+		}
+	}
+	return types[0], content[types[0]]
+}
+
+func mediaTypeMatches(pattern, mediaType string) bool {
+	if pattern == "" || pattern == "*/*" {
+		return pattern != ""
+	}
+	if prefix, ok := strings.CutSuffix(pattern, "/*"); ok {
+		return strings.HasPrefix(mediaType, prefix+"/")
+	}
+	return pattern == mediaType
+}
+
+func isJSONMediaType(mediaType string) bool {
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// exampleOf gets the named example, the example, or the first of the
+// examples, or otherwise generates an example from the schema
+func exampleOf(media *openapi3.MediaType, name string) any {
+	if ex, ok := media.Examples[name]; ok && ex.Value != nil {
+		return ex.Value.Value
+	}
+	if media.Example != nil {
+		return media.Example
+	}
+	for _, k := range slices.Sorted(maps.Keys(media.Examples)) {
+		if ex := media.Examples[k]; ex.Value != nil {
+			return ex.Value.Value
+		}
+	}
+	return exampleFromSchema(media.Schema, 0)
+}
+
+func encodeExample(contentType string, v any) ([]byte, error) {
+	if s, ok := v.(string); ok && !isJSONMediaType(contentType) {
+		return []byte(s), nil
+	}
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// exampleFromSchema generates a value that conforms to the schema
+func exampleFromSchema(ref *openapi3.SchemaRef, depth int) any {
+	if ref == nil || ref.Value == nil || depth > maxExampleDepth {
+		return nil
+	}
+
+	s := ref.Value
+	switch {
+	case s.Example != nil:
+		return s.Example
+	case s.Default != nil:
+		return s.Default
+	case len(s.Enum) > 0:
+		return s.Enum[0]
+	case len(s.AllOf) > 0:
+		result := map[string]any{}
+		for _, sub := range s.AllOf {
+			if m, ok := exampleFromSchema(sub, depth+1).(map[string]any); ok {
+				maps.Copy(result, m)
+			}
+		}
+		return result
+	case len(s.OneOf) > 0:
+		return exampleFromSchema(s.OneOf[0], depth+1)
+	case len(s.AnyOf) > 0:
+		return exampleFromSchema(s.AnyOf[0], depth+1)
+	}
+
+	switch {
+	case s.Type.Is(openapi3.TypeObject) || (s.Type.IsEmpty() && len(s.Properties) > 0):
+		result := map[string]any{}
+		for _, name := range slices.Sorted(maps.Keys(s.Properties)) {
+			if v := exampleFromSchema(s.Properties[name], depth+1); v != nil {
+				result[name] = v
+			}
+		}
+		return result
+
+	case s.Type.Is(openapi3.TypeArray):
+		item := exampleFromSchema(s.Items, depth+1)
+		if item == nil {
+			return []any{}
+		}
+		return slices.Repeat([]any{item}, max(1, int(s.MinItems)))
+
+	case s.Type.Is(openapi3.TypeString):
+		return exampleString(s)
+
+	case s.Type.Is(openapi3.TypeInteger):
+		if s.Min != nil {
+			return int64(math.Ceil(*s.Min))
+		}
+		return 0
+
+	case s.Type.Is(openapi3.TypeNumber):
+		if s.Min != nil {
+			return *s.Min
+		}
+		return 0.0
+
+	case s.Type.Is(openapi3.TypeBoolean):
+		return true
+	}
+	return nil
+}
+
+func exampleString(s *openapi3.Schema) string {
+	var v string
+	switch s.Format {
+	case "date-time":
+		v = "2006-01-02T15:04:05Z"
+	case "date":
+		v = "2006-01-02"
+	case "time":
+		v = "15:04:05"
+	case "uuid":
+		v = "00000000-0000-4000-8000-000000000000"
+	case "email":
+		v = "user@example.com"
+	case "uri", "url":
+		v = "https://example.com"
+	case "hostname":
+		v = "example.com"
+	case "ipv4":
+		v = "192.0.2.1"
+	case "ipv6":
+		v = "2001:db8::1"
+	case "byte":
+		v = "c3RyaW5n"
+	default:
+		v = "string"
+	}
+	for uint64(len(v)) < s.MinLength {
+		v += v
+	}
+	if s.MaxLength != nil && uint64(len(v)) > *s.MaxLength {
+		v = v[:*s.MaxLength]
+	}
+	return v
+}
+
+// parsePrefer parses the preferences in the Prefer header, as in
+// Prefer: code=404, example=missing
+func parsePrefer(header string) map[string]string {
+	result := map[string]string{}
+	for part := range strings.SplitSeq(header, ",") {
+		k, v, _ := strings.Cut(strings.TrimSpace(part), "=")
+		result[strings.ToLower(k)] = strings.Trim(v, `"`)
+	}
+	return result
+}
+
+func writeValidationProblem(w http.ResponseWriter, err error) {
+	problem := validationProblem{
+		Status: http.StatusBadRequest,
+		Title:  http.StatusText(http.StatusBadRequest),
+	}
+
+	var errs openapi3.MultiError
+	if !errors.As(err, &errs) {
+		errs = openapi3.MultiError{err}
+	}
+	for _, e := range errs {
+		problem.Errors = append(problem.Errors, newValidationErrors(e)...)
+	}
+
+	data, _ := json.MarshalIndent(problem, "", "  ")
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(http.StatusBadRequest)
+	w.Write(append(data, '\n'))
+}
+
+func newValidationErrors(err error) []validationError {
+	var reqErr *openapi3filter.RequestError
+	if !errors.As(err, &reqErr) {
+		return []validationError{{Message: err.Error()}}
+	}
+
+	var location string
+	switch {
+	case reqErr.Parameter != nil:
+		location = reqErr.Parameter.In + "." + reqErr.Parameter.Name
+	case reqErr.RequestBody != nil:
+		location = "body"
+	}
+
+	var causes openapi3.MultiError
+	if !errors.As(reqErr.Err, &causes) {
+		causes = openapi3.MultiError{reqErr.Err}
+	}
+
+	var result []validationError
+	for _, cause := range causes {
+		var schemaErr *openapi3.SchemaError
+		switch {
+		case errors.As(cause, &schemaErr):
+			loc := location
+			if ptr := schemaErr.JSONPointer(); len(ptr) > 0 {
+				loc += "/" + strings.Join(ptr, "/")
+			}
+			result = append(result, validationError{Location: loc, Message: schemaErr.Reason})
+		case cause != nil:
+			result = append(result, validationError{Location: location, Message: cause.Error()})
+		default:
+			result = append(result, validationError{Location: location, Message: reqErr.Reason})
+		}
+	}
+	return result
+}
@@ -0,0 +1,210 @@
+// Copyright 2026 The Joe-cli Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package httpserver_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Carbonfrost/joe-cli-http/httpclient"
+	"github.com/Carbonfrost/joe-cli-http/httpserver"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+const petsDocument = `
+openapi: 3.0.3
+info:
+  title: Pets
+  version: "1"
+paths:
+  /pets:
+    get:
+      parameters:
+        - name: limit
+          in: query
+          schema:
+            type: integer
+            maximum: 100
+      responses:
+        "200":
+          description: The pets
+          headers:
+            X-Total:
+              schema:
+                type: integer
+                minimum: 3
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  $ref: "#/components/schemas/Pet"
+    post:
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              $ref: "#/components/schemas/Pet"
+      responses:
+        "201":
+          description: Created
+          content:
+            application/json:
+              examples:
+                rex:
+                  value: {id: 1, name: Rex}
+  /pets/{id}:
+    get:
+      parameters:
+        - name: id
+          in: path
+          required: true
+          schema:
+            type: integer
+      responses:
+        "200":
+          description: The pet
+          content:
+            application/json:
+              example: {id: 7, name: Fido}
+        "404":
+          description: Not found
+          content:
+            application/json:
+              examples:
+                missing:
+                  value: {error: no such pet}
+components:
+  schemas:
+    Pet:
+      type: object
+      required: [id, name]
+      properties:
+        id:
+          type: integer
+        name:
+          type: string
+          format: email
+`
+
+var _ = Describe("NewOpenAPIHandler", func() {
+
+	var (
+		filename string
+		opts     httpserver.OpenAPIOptions
+	)
+
+	BeforeEach(func() {
+		filename = filepath.Join(GinkgoT().TempDir(), "api.yaml")
+		Expect(os.WriteFile(filename, []byte(petsDocument), 0600)).To(Succeed())
+		opts = httpserver.OpenAPIOptions{BasePath: "/api/"}
+	})
+
+	serve := func(method, path, body string, headers ...string) *httptest.ResponseRecorder {
+		h, err := httpserver.NewOpenAPIHandler(filename, opts)
+		Expect(err).NotTo(HaveOccurred())
+
+		if body != "" {
+			headers = append(headers, "Content-Type", "application/json")
+		}
+		return serveRequest(h, newBodyRequest(method, path, strings.NewReader(body), headers...))
+	}
+
+	It("generates the response from the schema", func() {
+		recorder := serve("GET", "/api/pets", "")
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(recorder.Header().Get("Content-Type")).To(Equal("application/json"))
+		Expect(recorder.Header().Get("X-Total")).To(Equal("3"))
+		Expect(recorder.Body.String()).To(MatchJSON(`[{"id": 0, "name": "user@example.com"}]`))
+	})
+
+	It("responds with the example", func() {
+		recorder := serve("GET", "/api/pets/7", "")
+		Expect(recorder.Body.String()).To(MatchJSON(`{"id": 7, "name": "Fido"}`))
+	})
+
+	It("responds with the first of the examples", func() {
+		recorder := serve("POST", "/api/pets", `{"id": 2, "name": "Spot"}`)
+		Expect(recorder.Code).To(Equal(http.StatusCreated))
+		Expect(recorder.Body.String()).To(MatchJSON(`{"id": 1, "name": "Rex"}`))
+	})
+
+	It("selects the response using the Prefer header", func() {
+		recorder := serve("GET", "/api/pets/7", "", "Prefer", "code=404, example=missing")
+		Expect(recorder.Code).To(Equal(http.StatusNotFound))
+		Expect(recorder.Body.String()).To(MatchJSON(`{"error": "no such pet"}`))
+	})
+
+	DescribeTable("validation errors", func(method, path, body string, expected string) {
+		recorder := serve(method, path, body)
+		Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+		Expect(recorder.Header().Get("Content-Type")).To(Equal("application/problem+json"))
+		Expect(recorder.Body.String()).To(MatchJSON(expected))
+	},
+		Entry("query parameter", "GET", "/api/pets?limit=500", "", `{
+			"status": 400,
+			"title": "Bad Request",
+			"errors": [{"location": "query.limit", "message": "number must be at most 100"}]
+		}`),
+		Entry("path parameter", "GET", "/api/pets/rex", "", `{
+			"status": 400,
+			"title": "Bad Request",
+			"errors": [{"location": "path.id", "message": "value rex: an invalid integer: invalid syntax"}]
+		}`),
+		Entry("body", "POST", "/api/pets", `{"id": "x"}`, `{
+			"status": 400,
+			"title": "Bad Request",
+			"errors": [
+				{"location": "body/id", "message": "value must be an integer"},
+				{"location": "body/name", "message": "property \"name\" is missing"}
+			]
+		}`),
+	)
+
+	It("doesn't validate when validation is skipped", func() {
+		opts.SkipValidation = true
+		Expect(serve("GET", "/api/pets?limit=500", "").Code).To(Equal(http.StatusOK))
+	})
+
+	It("responds with 405 when the method isn't an operation", func() {
+		recorder := serve("DELETE", "/api/pets", "")
+		Expect(recorder.Code).To(Equal(http.StatusMethodNotAllowed))
+		Expect(recorder.Header().Get("Allow")).To(Equal("GET, POST"))
+	})
+
+	It("responds with 404 when the path isn't in the document", func() {
+		Expect(serve("GET", "/api/owners", "").Code).To(Equal(http.StatusNotFound))
+	})
+
+	It("returns an error when the document isn't valid", func() {
+		Expect(os.WriteFile(filename, []byte("openapi: 3.0.3\npaths: []\n"), 0600)).To(Succeed())
+		_, err := httpserver.NewOpenAPIHandler(filename, opts)
+		Expect(err).To(MatchError(HavePrefix(filename + ": ")))
+	})
+})
+
+var _ = Describe("OpenAPIHandlerSpec", func() {
+
+	It("uses the physical path and options of the virtual path", func() {
+		filename := filepath.Join(GinkgoT().TempDir(), "api.yaml")
+		Expect(os.WriteFile(filename, []byte(petsDocument), 0600)).To(Succeed())
+
+		h, err := httpserver.OpenAPIHandlerSpec()(context.Background(), httpclient.VirtualPath{
+			RequestPath:  "/v1",
+			PhysicalPath: filename,
+			Options:      map[string]string{"validate": "false"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(serveRequest(h, newRequest("GET", "/v1/pets?limit=500")).Code).To(Equal(http.StatusOK))
+	})
+})
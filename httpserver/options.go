@@ -7,26 +7,42 @@ package httpserver
 import (
 	"context"
 	"time"
+
+	joetls "github.com/Carbonfrost/joe-cli-http/tls"
 )
 
 // Options contains settings for the server which have data representations.
 // Each non-nil field is applied when Options is used as an Option.
 type Options struct {
-	Addr                  *string        `toml:"addr"                    json:"addr,omitempty"`
-	Hostname              *string        `toml:"hostname"                json:"hostname,omitempty"`
-	Port                  *int           `toml:"port"                    json:"port,omitempty"`
-	TLSCertFile           *string        `toml:"tls-cert-file"           json:"tlsCertFile,omitempty"`
-	TLSKeyFile            *string        `toml:"tls-key-file"            json:"tlsKeyFile,omitempty"`
-	ServerHeader          *string        `toml:"server-header"           json:"serverHeader,omitempty"`
-	AccessLog             *string        `toml:"access-log"              json:"accessLog,omitempty"`
-	StaticDirectory       *string        `toml:"static-directory"        json:"staticDirectory,omitempty"`
-	HideDirectoryListings *bool          `toml:"hide-directory-listings" json:"hideDirectoryListings,omitempty"`
-	ShutdownTimeout       *time.Duration `toml:"shutdown-timeout"        json:"shutdownTimeout,omitempty"`
-	ReadTimeout           *time.Duration `toml:"read-timeout"            json:"readTimeout,omitempty"`
-	ReadHeaderTimeout     *time.Duration `toml:"read-header-timeout"     json:"readHeaderTimeout,omitempty"`
-	WriteTimeout          *time.Duration `toml:"write-timeout"           json:"writeTimeout,omitempty"`
-	IdleTimeout           *time.Duration `toml:"idle-timeout"            json:"idleTimeout,omitempty"`
-	MaxHeaderBytes        *int           `toml:"max-header-bytes"        json:"maxHeaderBytes,omitempty"`
+	Addr                  *string             `toml:"addr"                    json:"addr,omitempty"`
+	Hostname              *string             `toml:"hostname"                json:"hostname,omitempty"`
+	Port                  *int                `toml:"port"                    json:"port,omitempty"`
+	TLSCertFile           *string             `toml:"tls-cert-file"           json:"tlsCertFile,omitempty"`
+	TLSKeyFile            *string             `toml:"tls-key-file"            json:"tlsKeyFile,omitempty"`
+	TLSCertType           *joetls.CertType    `toml:"tls-cert-type"           json:"tlsCertType,omitempty"`
+	TLSPassword           *string             `toml:"tls-password"            json:"tlsPassword,omitempty"`
+	TLSMode               *TLSMode            `toml:"tls"                     json:"tls,omitempty"`
+	TLSCertDirectory      *string             `toml:"tls-cert-directory"      json:"tlsCertDirectory,omitempty"`
+	ServerHeader          *string             `toml:"server-header"           json:"serverHeader,omitempty"`
+	AccessLog             *string             `toml:"access-log"              json:"accessLog,omitempty"`
+	StaticDirectory       *string             `toml:"static-directory"        json:"staticDirectory,omitempty"`
+	HideDirectoryListings *bool               `toml:"hide-directory-listings" json:"hideDirectoryListings,omitempty"`
+	SPA                   *string             `toml:"spa" json:"spa,omitempty"`
+	NotFoundPage          *string             `toml:"not-found" json:"notFound,omitempty"`
+	CacheControl          []string            `toml:"cache-control" json:"cacheControl,omitempty"`
+	Watch                 *bool               `toml:"watch"                   json:"watch,omitempty"`
+	WatchScript           *bool               `toml:"watch-script"            json:"watchScript,omitempty"`
+	Compression           *CompressionOptions `toml:"compression" json:"compression,omitempty"`
+	CORS                  *CORSOptions        `toml:"cors"                    json:"cors,omitempty"`
+	Auth                  *AuthOptions        `toml:"auth"                    json:"auth,omitempty"`
+	RateLimit             *RateLimitOptions   `toml:"rate-limit"              json:"rateLimit,omitempty"`
+	MaxConcurrent         *int                `toml:"max-concurrent"          json:"maxConcurrent,omitempty"`
+	ShutdownTimeout       *time.Duration      `toml:"shutdown-timeout"        json:"shutdownTimeout,omitempty"`
+	ReadTimeout           *time.Duration      `toml:"read-timeout"            json:"readTimeout,omitempty"`
+	ReadHeaderTimeout     *time.Duration      `toml:"read-header-timeout"     json:"readHeaderTimeout,omitempty"`
+	WriteTimeout          *time.Duration      `toml:"write-timeout"           json:"writeTimeout,omitempty"`
+	IdleTimeout           *time.Duration      `toml:"idle-timeout"            json:"idleTimeout,omitempty"`
+	MaxHeaderBytes        *int                `toml:"max-header-bytes"        json:"maxHeaderBytes,omitempty"`
 }
 
 func (o *Options) Execute(ctx context.Context) error {
@@ -55,6 +71,18 @@ func (o *Options) parts() (results []Option) {
 	if o.TLSKeyFile != nil {
 		results = append(results, WithTLSKeyFile(*o.TLSKeyFile))
 	}
+	if o.TLSCertType != nil {
+		results = append(results, WithTLSCertType(*o.TLSCertType))
+	}
+	if o.TLSPassword != nil {
+		results = append(results, WithTLSPassword(*o.TLSPassword))
+	}
+	if o.TLSMode != nil {
+		results = append(results, WithTLSMode(*o.TLSMode))
+	}
+	if o.TLSCertDirectory != nil {
+		results = append(results, WithTLSCertDirectory(*o.TLSCertDirectory))
+	}
 	if o.ServerHeader != nil {
 		results = append(results, WithServerHeader(*o.ServerHeader))
 	}
@@ -67,6 +95,36 @@ func (o *Options) parts() (results []Option) {
 	if o.HideDirectoryListings != nil {
 		results = append(results, WithHideDirectoryListings(*o.HideDirectoryListings))
 	}
+	if o.SPA != nil {
+		results = append(results, WithSPA(*o.SPA))
+	}
+	if o.NotFoundPage != nil {
+		results = append(results, WithNotFoundPage(*o.NotFoundPage))
+	}
+	for _, rule := range o.CacheControl {
+		results = append(results, AddCacheControl(rule))
+	}
+	if o.Watch != nil {
+		results = append(results, WithWatch(*o.Watch))
+	}
+	if o.WatchScript != nil {
+		results = append(results, WithWatchScript(*o.WatchScript))
+	}
+	if o.Compression != nil {
+		results = append(results, WithCompression(*o.Compression))
+	}
+	if o.CORS != nil {
+		results = append(results, WithCORS(*o.CORS))
+	}
+	if o.Auth != nil {
+		results = append(results, WithAuth(*o.Auth))
+	}
+	if o.RateLimit != nil {
+		results = append(results, WithRateLimit(*o.RateLimit))
+	}
+	if o.MaxConcurrent != nil {
+		results = append(results, WithMaxConcurrent(*o.MaxConcurrent))
+	}
 	if o.ShutdownTimeout != nil {
 		results = append(results, WithShutdownTimeout(*o.ShutdownTimeout))
 	}
@@ -0,0 +1,259 @@
+// Copyright 2026 The Joe-cli Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package httpserver
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Rate is a number of requests allowed per interval
+type Rate struct {
+	Count int
+	Per   time.Duration
+}
+
+// RateLimitOptions configures request rate limiting
+type RateLimitOptions struct {
+	// Rate is the sustained rate of requests allowed
+	Rate Rate `toml:"rate"  json:"rate"`
+
+	// Burst is the number of requests allowed at once above the sustained rate.
+	// When zero, the count of the rate is used.
+	Burst int `toml:"burst" json:"burst,omitempty"`
+
+	// Key specifies how clients are distinguished so that each gets its own
+	// limit: global (the default) shares one limit among all requests, ip uses
+	// the remote IP address, and header:NAME uses the value of a request header
+	Key string `toml:"key"   json:"key,omitempty"`
+}
+
+type rateLimiter struct {
+	perSecond float64
+	burst     float64
+	key       func(*http.Request) string
+	now       func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+const (
+	rateLimitSweepInterval = time.Minute
+	concurrencyRetryAfter  = "1"
+)
+
+// NewRateLimitMiddleware provides handler middleware which limits the rate of
+// requests using a token bucket.  Requests over the limit receive
+// 429 Too Many Requests with a Retry-After header.
+func NewRateLimitMiddleware(opts RateLimitOptions) (MiddlewareFunc, error) {
+	l, err := newRateLimiter(opts, time.Now)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if ok, wait := l.allow(l.key(r)); !ok {
+				w.Header().Set("Retry-After", retryAfter(wait))
+				http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}, nil
+}
+
+// NewConcurrencyLimitMiddleware provides handler middleware which limits the
+// number of requests that are handled at the same time.  Requests over the
+// limit receive 503 Service Unavailable with a Retry-After header.
+func NewConcurrencyLimitMiddleware(max int) MiddlewareFunc {
+	inFlight := make(chan struct{}, max)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case inFlight <- struct{}{}:
+				defer func() { <-inFlight }()
+				next.ServeHTTP(w, r)
+			default:
+				w.Header().Set("Retry-After", concurrencyRetryAfter)
+				http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+			}
+		})
+	}
+}
+
+func newRateLimiter(opts RateLimitOptions, now func() time.Time) (*rateLimiter, error) {
+	if opts.Rate.Count <= 0 || opts.Rate.Per <= 0 {
+		return nil, fmt.Errorf("rate limit must be positive")
+	}
+	key, err := rateLimitKey(opts.Key)
+	if err != nil {
+		return nil, err
+	}
+	burst := opts.Burst
+	if burst <= 0 {
+		burst = opts.Rate.Count
+	}
+
+	return &rateLimiter{
+		perSecond: float64(opts.Rate.Count) / opts.Rate.Per.Seconds(),
+		burst:     float64(burst),
+		key:       key,
+		now:       now,
+		buckets:   map[string]*tokenBucket{},
+		lastSweep: now(),
+	}, nil
+}
+
+// allow takes a token from the bucket for the key.  If there are none, it
+// returns how long until a token is available.
+func (l *rateLimiter) allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+
+	b.tokens = min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.perSecond)
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	return false, time.Duration((1 - b.tokens) / l.perSecond * float64(time.Second))
+}
+
+// sweep removes the buckets which have refilled so that the number of
+// buckets doesn't grow with the number of clients
+func (l *rateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < rateLimitSweepInterval {
+		return
+	}
+	l.lastSweep = now
+
+	refill := time.Duration(l.burst / l.perSecond * float64(time.Second))
+	for k, b := range l.buckets {
+		if now.Sub(b.last) >= refill {
+			delete(l.buckets, k)
+		}
+	}
+}
+
+func rateLimitKey(key string) (func(*http.Request) string, error) {
+	switch {
+	case key == "", key == "global":
+		return func(*http.Request) string { return "" }, nil
+	case key == "ip":
+		return remoteIP, nil
+	case strings.HasPrefix(key, "header:"):
+		name := http.CanonicalHeaderKey(strings.TrimPrefix(key, "header:"))
+		if name == "" {
+			return nil, fmt.Errorf("rate limit key requires a header name")
+		}
+		return func(r *http.Request) string {
+			return r.Header.Get(name)
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown rate limit key %q", key)
+}
+
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func retryAfter(wait time.Duration) string {
+	return strconv.Itoa(max(1, int(math.Ceil(wait.Seconds()))))
+}
+
+// ParseRate parses a rate such as 100/s.  The interval can be s, m, or h, or a
+// duration such as 10s.  When the interval is omitted, it is per second.
+func ParseRate(s string) (Rate, error) {
+	count, per, hasPer := strings.Cut(strings.TrimSpace(s), "/")
+	n, err := strconv.Atoi(count)
+	if err != nil || n <= 0 {
+		return Rate{}, fmt.Errorf("invalid rate %q", s)
+	}
+
+	d := time.Second
+	if hasPer {
+		switch per {
+		case "s", "sec":
+			d = time.Second
+		case "m", "min":
+			d = time.Minute
+		case "h", "hour":
+			d = time.Hour
+		default:
+			d, err = time.ParseDuration(per)
+			if err != nil || d <= 0 {
+				return Rate{}, fmt.Errorf("invalid rate %q", s)
+			}
+		}
+	}
+	return Rate{Count: n, Per: d}, nil
+}
+
+func (r Rate) String() string {
+	if r.Count == 0 {
+		return ""
+	}
+	switch r.Per {
+	case time.Second:
+		return fmt.Sprintf("%d/s", r.Count)
+	case time.Minute:
+		return fmt.Sprintf("%d/m", r.Count)
+	case time.Hour:
+		return fmt.Sprintf("%d/h", r.Count)
+	}
+	return fmt.Sprintf("%d/%s", r.Count, r.Per)
+}
+
+// MarshalText provides the textual representation
+func (r Rate) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// UnmarshalText converts the textual representation
+func (r *Rate) UnmarshalText(b []byte) error {
+	return r.Set(string(b))
+}
+
+// Set sets the rate from its textual representation
+func (r *Rate) Set(arg string) error {
+	v, err := ParseRate(arg)
+	if err != nil {
+		return err
+	}
+	*r = v
+	return nil
+}
+
+func (*Rate) Synopsis() string {
+	return "N/{s|m|h}"
+}
@@ -0,0 +1,134 @@
+// Copyright 2026 The Joe-cli Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package httpserver_test
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Carbonfrost/joe-cli-http/httpserver"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("NewRateLimitMiddleware", func() {
+
+	newHandler := func(opts httpserver.RateLimitOptions) http.Handler {
+		m, err := httpserver.NewRateLimitMiddleware(opts)
+		Expect(err).NotTo(HaveOccurred())
+		return m(httpserver.NewPingHandler())
+	}
+
+	request := func(remoteAddr string, headers ...string) *http.Request {
+		req := newRequest("GET", "/", headers...)
+		req.RemoteAddr = remoteAddr
+		return req
+	}
+
+	It("allows the burst then responds with 429", func() {
+		h := newHandler(httpserver.RateLimitOptions{
+			Rate:  httpserver.Rate{Count: 1, Per: time.Hour},
+			Burst: 2,
+		})
+
+		Expect(serveRequest(h, request("192.0.2.1:1234")).Code).To(Equal(http.StatusOK))
+		Expect(serveRequest(h, request("192.0.2.2:1234")).Code).To(Equal(http.StatusOK))
+
+		recorder := serveRequest(h, request("192.0.2.3:1234"))
+		Expect(recorder.Code).To(Equal(http.StatusTooManyRequests))
+		Expect(recorder.Header().Get("Retry-After")).To(Equal("3600"))
+	})
+
+	It("limits each IP address separately", func() {
+		h := newHandler(httpserver.RateLimitOptions{
+			Rate: httpserver.Rate{Count: 1, Per: time.Hour},
+			Key:  "ip",
+		})
+
+		Expect(serveRequest(h, request("192.0.2.1:1234")).Code).To(Equal(http.StatusOK))
+		Expect(serveRequest(h, request("192.0.2.1:5678")).Code).To(Equal(http.StatusTooManyRequests))
+		Expect(serveRequest(h, request("192.0.2.2:1234")).Code).To(Equal(http.StatusOK))
+	})
+
+	It("limits each header value separately", func() {
+		h := newHandler(httpserver.RateLimitOptions{
+			Rate: httpserver.Rate{Count: 1, Per: time.Hour},
+			Key:  "header:X-API-Key",
+		})
+
+		Expect(serveRequest(h, request("192.0.2.1:1234", "X-Api-Key", "a")).Code).To(Equal(http.StatusOK))
+		Expect(serveRequest(h, request("192.0.2.2:1234", "X-Api-Key", "a")).Code).To(Equal(http.StatusTooManyRequests))
+		Expect(serveRequest(h, request("192.0.2.1:1234", "X-Api-Key", "b")).Code).To(Equal(http.StatusOK))
+	})
+
+	DescribeTable("errors", func(opts httpserver.RateLimitOptions, expected string) {
+		_, err := httpserver.NewRateLimitMiddleware(opts)
+		Expect(err).To(MatchError(expected))
+	},
+		Entry("no rate", httpserver.RateLimitOptions{}, "rate limit must be positive"),
+		Entry("unknown key",
+			httpserver.RateLimitOptions{Rate: httpserver.Rate{Count: 1, Per: time.Second}, Key: "cookie"},
+			`unknown rate limit key "cookie"`,
+		),
+	)
+})
+
+var _ = Describe("NewConcurrencyLimitMiddleware", func() {
+
+	It("responds with 503 when the limit is reached", func() {
+		var (
+			started = make(chan struct{})
+			release = make(chan struct{})
+			done    = make(chan struct{})
+		)
+		h := httpserver.NewConcurrencyLimitMiddleware(1)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			close(started)
+			<-release
+		}))
+
+		go func() {
+			defer close(done)
+			serveRequest(h, newRequest("GET", "/"))
+		}()
+		<-started
+
+		recorder := serveRequest(h, newRequest("GET", "/"))
+		Expect(recorder.Code).To(Equal(http.StatusServiceUnavailable))
+		Expect(recorder.Header().Get("Retry-After")).To(Equal("1"))
+
+		close(release)
+		<-done
+	})
+})
+
+var _ = Describe("ParseRate", func() {
+
+	DescribeTable("examples", func(text string, expected httpserver.Rate) {
+		actual, err := httpserver.ParseRate(text)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(actual).To(Equal(expected))
+	},
+		Entry("per second", "100/s", httpserver.Rate{Count: 100, Per: time.Second}),
+		Entry("per minute", "10/m", httpserver.Rate{Count: 10, Per: time.Minute}),
+		Entry("per hour", "5/hour", httpserver.Rate{Count: 5, Per: time.Hour}),
+		Entry("duration", "3/10s", httpserver.Rate{Count: 3, Per: 10 * time.Second}),
+		Entry("implied per second", "20", httpserver.Rate{Count: 20, Per: time.Second}),
+	)
+
+	DescribeTable("errors", func(text string) {
+		_, err := httpserver.ParseRate(text)
+		Expect(err).To(MatchError(ContainSubstring("invalid rate")))
+	},
+		Entry("empty", ""),
+		Entry("zero", "0/s"),
+		Entry("unknown interval", "5/fortnight"),
+	)
+
+	It("round trips to text", func() {
+		r, _ := httpserver.ParseRate("3/10s")
+		Expect(r.String()).To(Equal("3/10s"))
+	})
+})
@@ -7,16 +7,21 @@ package httpserver
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"net"
 	"net/http"
 	"net/url"
 	"os"
+	"slices"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/Carbonfrost/joe-cli"
+	joetls "github.com/Carbonfrost/joe-cli-http/tls"
 	"github.com/Carbonfrost/joe-cli/extensions/exec"
 )
 
@@ -58,6 +63,20 @@ type Server struct {
 	TLSCertFile string
 	TLSKeyFile  string
 
+	// TLSCertType specifies the format of TLSCertFile
+	TLSCertType joetls.CertType
+
+	// TLSPassword is used to decrypt the private key if it is encrypted
+	TLSPassword string
+
+	// TLSMode specifies how certificates are obtained
+	TLSMode TLSMode
+
+	// TLSCertDirectory is a directory containing additional certificate and
+	// key pairs, which are selected using the server name (SNI) requested by
+	// the client
+	TLSCertDirectory string
+
 	// ShutdownTimeout specifies how long to wait for the server to shutdown
 	// when a signal is received
 	ShutdownTimeout time.Duration
@@ -67,12 +86,38 @@ type Server struct {
 	ready           ReadyFunc
 	shutdown        ReadyFunc
 	hideDirListings bool
+	spa             string
+	notFoundPage    string
+	cacheControl    []CacheControlRule
+	localCADir      string
+	tlsKeyPairs     []joetls.KeyPair
+	certs           *joetls.CertificateStore
+	watch           bool
+	noWatchScript   bool
+	watchDirs       []string
+	cors            *CORSOptions
+	compression     *CompressionOptions
+	auth            *AuthOptions
+	authMiddleware  MiddlewareFunc
+	rateLimit       *RateLimitOptions
+	rateLimiter     MiddlewareFunc
+	maxConcurrent   int
 	middleware      []MiddlewareFunc
+	mux             mux
 	accessLog       string
 	actualBind      struct {
+		// mu guards the address, which is set once the server is set up
+		// and is read from other goroutines to detect that it is listening
+		mu   sync.Mutex
 		addr string
 		tls  bool
 	}
+	captures struct {
+		// mu guards the stores, which are looked up again when handlers are
+		// created again, such as when the configuration is reloaded
+		mu     sync.Mutex
+		stores map[string]*CaptureStore
+	}
 }
 
 // Option is an option to configure the server
@@ -96,6 +141,27 @@ func (o option[_]) apply(s *Server) {
 	o.fn(s, o.val)
 }
 
+// TLSMode specifies how the server obtains its TLS certificate
+type TLSMode int
+
+// TLS modes
+const (
+	// TLSFiles uses the certificates from TLSCertFile, TLSKeyFile, TLSCertDirectory,
+	// and any key pairs that were added.  TLS is only used when at least one of
+	// these is set.
+	TLSFiles TLSMode = iota
+
+	// TLSAuto issues certificates from the local CA for the host name of the
+	// server, localhost, and the loopback addresses unless certificate files are set
+	TLSAuto
+	maxTLSMode
+)
+
+var tlsModeStrings = [...]string{
+	"files",
+	"auto",
+}
+
 // MiddlewareFunc defines a function that creates a middleware wrapper around another
 // handler
 type MiddlewareFunc func(next http.Handler) http.Handler
@@ -135,6 +201,36 @@ func defaultOptions(s *Server) []Option {
 		WithAccessLog(defaultAccessLog),
 		WithReadyFunc(DefaultReadyFunc),
 		WithShutdownFunc(DefaultShutdownFunc),
+		WithMiddleware(func(h http.Handler) http.Handler {
+			if s.compression != nil {
+				return NewCompressionMiddleware(*s.compression)(h)
+			}
+			return h
+		}),
+		WithMiddleware(func(h http.Handler) http.Handler {
+			if s.authMiddleware != nil {
+				return s.authMiddleware(h)
+			}
+			return h
+		}),
+		WithMiddleware(func(h http.Handler) http.Handler {
+			if s.cors != nil {
+				return NewCORSMiddleware(*s.cors)(h)
+			}
+			return h
+		}),
+		WithMiddleware(func(h http.Handler) http.Handler {
+			if s.maxConcurrent > 0 {
+				return NewConcurrencyLimitMiddleware(s.maxConcurrent)(h)
+			}
+			return h
+		}),
+		WithMiddleware(func(h http.Handler) http.Handler {
+			if s.rateLimiter != nil {
+				return s.rateLimiter(h)
+			}
+			return h
+		}),
 		WithMiddleware(func(h http.Handler) http.Handler {
 			if s.accessLog != "" {
 				return NewRequestLogger(s.accessLog, os.Stderr, h)
@@ -157,7 +253,7 @@ func (s *Server) Apply(opts ...Option) {
 func DefaultServer() *Server {
 	return New(
 		WithHandlerFactory(func(s *Server) (http.Handler, error) {
-			return newFileServerHandler(s.staticDir, s.HideDirectoryListing()), nil
+			return newFileServerHandler(s.staticDir, s.FileServerOptions()), nil
 		}),
 	)
 }
@@ -174,6 +270,8 @@ func WithDefaultAction() Option {
 			s.Action = cli.Pipeline(
 				ContextValue(s),
 				FlagsAndArgs(),
+				joetls.New(joetls.WithServerDefaultAction()),
+				WithTLSConfigFromContext(),
 			)
 			return nil
 		},
@@ -270,12 +368,178 @@ func WithTLSCertFile(filename string) Option {
 	return withAdapter((*Server).SetTLSCertFile, filename)
 }
 
+// WithTLSCertType sets the format of the TLS cert file
+func WithTLSCertType(t joetls.CertType) Option {
+	return withAdapter((*Server).SetTLSCertType, t)
+}
+
+// WithTLSPassword sets the password used to decrypt the TLS key
+func WithTLSPassword(s string) Option {
+	return withAdapter((*Server).SetTLSPassword, s)
+}
+
+// WithTLSCertDirectory sets a directory containing certificate and key pairs
+// which are selected by SNI
+func WithTLSCertDirectory(dir string) Option {
+	return withAdapter((*Server).SetTLSCertDirectory, dir)
+}
+
+// AddTLSKeyPair adds a certificate and key pair which is selected by SNI
+func AddTLSKeyPair(p joetls.KeyPair) Option {
+	return withAdapter((*Server).AddTLSKeyPair, p)
+}
+
+// WithTLSConfig sets the TLS configuration used when serving TLS
+func WithTLSConfig(cfg *tls.Config) Option {
+	return withAdapter((*Server).SetTLSConfig, cfg)
+}
+
+// WithTLSConfigFromContext sets the TLS configuration to the one from the
+// tls package that is in the context, which allows the TLS flags to
+// configure the server.
+func WithTLSConfigFromContext() cli.Action {
+	return cli.Before(cli.ActionFunc(func(c *cli.Context) error {
+		return FromContext(c).SetTLSConfig(joetls.FromContext(c).Config)
+	}))
+}
+
+// WithTLSMode sets how the server obtains its certificate
+func WithTLSMode(m TLSMode) Option {
+	return withAdapter((*Server).SetTLSMode, m)
+}
+
+// WithLocalCADirectory sets the directory containing the local CA used
+// by TLSAuto.  By default, a directory within the user config directory is used.
+func WithLocalCADirectory(dir string) Option {
+	return withAdapter((*Server).SetLocalCADirectory, dir)
+}
+
+// WithWatch sets whether browsers are notified to reload when files change
+// in the static directories
+func WithWatch(v bool) Option {
+	return withAdapter((*Server).SetWatch, v)
+}
+
+// WithWatchScript sets whether the live reload script is injected into HTML
+// pages when watching.  This is the default.
+func WithWatchScript(v bool) Option {
+	return withAdapter((*Server).SetWatchScript, v)
+}
+
+// AddWatchDirectory adds a directory that is watched for changes
+func AddWatchDirectory(dir string) Option {
+	return withAdapter((*Server).AddWatchDirectory, dir)
+}
+
+// WithCompression enables response compression using the given options
+func WithCompression(opts CompressionOptions) Option {
+	return withAdapter((*Server).SetCompression, opts)
+}
+
+// WithCORS enables cross-origin resource sharing (CORS) using the given options
+func WithCORS(opts CORSOptions) Option {
+	return withAdapter((*Server).SetCORS, opts)
+}
+
+// WithCORSEnabled sets whether CORS is enabled.  When enabling CORS, any
+// options previously set are kept.
+func WithCORSEnabled(v bool) Option {
+	return withAdapter((*Server).SetCORSEnabled, v)
+}
+
+// AddCORSOrigin enables CORS and adds an origin which is allowed
+func AddCORSOrigin(origin string) Option {
+	return withAdapter((*Server).AddCORSOrigin, origin)
+}
+
+// WithCORSMethods enables CORS and sets the methods which are allowed
+func WithCORSMethods(methods []string) Option {
+	return withAdapter((*Server).SetCORSMethods, methods)
+}
+
+// WithCORSHeaders enables CORS and sets the request headers which are allowed
+func WithCORSHeaders(headers []string) Option {
+	return withAdapter((*Server).SetCORSHeaders, headers)
+}
+
+// WithCORSCredentials enables CORS and sets whether credentials are allowed
+func WithCORSCredentials(v bool) Option {
+	return withAdapter((*Server).SetCORSCredentials, v)
+}
+
+// WithCORSMaxAge enables CORS and sets how long preflight results can be cached
+func WithCORSMaxAge(d time.Duration) Option {
+	return withAdapter((*Server).SetCORSMaxAge, d)
+}
+
+// WithAuth requires requests to be authenticated using the given options
+func WithAuth(opts AuthOptions) Option {
+	return withAdapter((*Server).SetAuth, opts)
+}
+
+// WithAuthMode sets the authentication scheme.  When enabling authentication,
+// any options previously set are kept.
+func WithAuthMode(m AuthMode) Option {
+	return withAdapter((*Server).SetAuthMode, m)
+}
+
+// AddAuthUser adds a user which is allowed by basic authentication.  The
+// user is specified as USER:PASSWORD.  Basic authentication is enabled
+// unless another mode was set.
+func AddAuthUser(userPassword string) Option {
+	return withAdapter((*Server).AddAuthUser, userPassword)
+}
+
+// WithHTPasswdFile sets the htpasswd file which contains the users allowed
+// by basic authentication.  Basic authentication is enabled unless another
+// mode was set.
+func WithHTPasswdFile(filename string) Option {
+	return withAdapter((*Server).SetHTPasswdFile, filename)
+}
+
+// WithAuthTokenFile sets the file which contains the tokens allowed by bearer
+// authentication.  Bearer authentication is enabled unless another mode was set.
+func WithAuthTokenFile(filename string) Option {
+	return withAdapter((*Server).SetAuthTokenFile, filename)
+}
+
+// WithRateLimit limits the rate of requests using the given options
+func WithRateLimit(opts RateLimitOptions) Option {
+	return withAdapter((*Server).SetRateLimit, opts)
+}
+
+// WithRequestRate limits the rate of requests.  When enabling rate limiting,
+// any options previously set are kept.
+func WithRequestRate(r Rate) Option {
+	return withAdapter((*Server).SetRequestRate, r)
+}
+
+// WithRateLimitBurst sets the number of requests allowed at once above the
+// rate limit
+func WithRateLimitBurst(n int) Option {
+	return withAdapter((*Server).SetRateLimitBurst, n)
+}
+
+// WithRateLimitKey sets how clients are distinguished by the rate limit:
+// global, ip, or header:NAME
+func WithRateLimitKey(key string) Option {
+	return withAdapter((*Server).SetRateLimitKey, key)
+}
+
+// WithMaxConcurrent sets the maximum number of requests handled at the same
+// time.  When zero, there is no limit.
+func WithMaxConcurrent(n int) Option {
+	return withAdapter((*Server).SetMaxConcurrent, n)
+}
+
 // WithServerHeader sets the contents of the server header
 func WithServerHeader(s string) Option {
 	return withAdapter((*Server).SetServerHeader, s)
 }
 
-// WithAccessLog sets the format string for the access log
+// WithAccessLog sets the format string for the access log.  The default
+// format doesn't include the authenticated user, which is available
+// as %(user).
 func WithAccessLog(s string) Option {
 	return withAdapter((*Server).SetAccessLog, s)
 }
@@ -295,6 +559,26 @@ func WithHideDirectoryListings(v bool) Option {
 	return withAdapter((*Server).SetHideDirectoryListings, v)
 }
 
+// WithSPA sets the file, such as index.html, which is served for requests
+// that don't match a file so that a single-page application can handle its
+// own routes
+func WithSPA(file string) Option {
+	return withAdapter((*Server).SetSPA, file)
+}
+
+// WithNotFoundPage sets the file, such as 404.html, which is served with
+// 404 Not Found for requests that don't match a file
+func WithNotFoundPage(file string) Option {
+	return withAdapter((*Server).SetNotFoundPage, file)
+}
+
+// AddCacheControl adds a rule which sets Cache-Control for static files that
+// match a glob.  The rule is specified as GLOB=VALUE, as in *.js=immutable.
+// The value immutable is short for public, max-age=31536000, immutable.
+func AddCacheControl(rule string) Option {
+	return withAdapter((*Server).AddCacheControl, rule)
+}
+
 // FromContext obtains the server from the context.
 func FromContext(ctx context.Context) *Server {
 	return ctx.Value(servicesKey).(*Server)
@@ -346,6 +630,16 @@ func (s *Server) HideDirectoryListing() bool {
 	return s.hideDirListings
 }
 
+// FileServerOptions gets the options used to serve static files
+func (s *Server) FileServerOptions() FileServerOptions {
+	return FileServerOptions{
+		HideDirectoryListing: s.hideDirListings,
+		SPA:                  s.spa,
+		NotFound:             s.notFoundPage,
+		CacheControl:         slices.Clone(s.cacheControl),
+	}
+}
+
 func (s *Server) ListenAndServe() error {
 	if s.Server.Handler == nil && s.handlerFactory != nil {
 		h, err := s.handlerFactory(s)
@@ -355,23 +649,224 @@ func (s *Server) ListenAndServe() error {
 		s.Server.Handler = h
 	}
 
+	// Certificates are watched for as long as the server is serving, which
+	// includes when it fails to start or is closed
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if err := s.setupTLS(ctx); err != nil {
+		return err
+	}
+
+	if err := s.setupAuth(); err != nil {
+		return err
+	}
+
+	if err := s.setupCORS(); err != nil {
+		return err
+	}
+
+	if err := s.setupRateLimit(); err != nil {
+		return err
+	}
+
 	listener, err := net.Listen("tcp", s.Server.Addr)
 	if err != nil {
 		return err
 	}
 
-	s.actualBind.addr = listener.Addr().String()
-	s.actualBind.tls = (s.TLSCertFile != "")
+	useTLS := s.usesTLS()
+	s.setupLiveReload()
 	s.applyMiddleware()
+	s.setActualBind(listener.Addr().String(), useTLS)
 
-	if s.TLSCertFile == "" {
+	if !useTLS {
 		return s.Server.Serve(listener)
 	}
 
-	return s.Server.ServeTLS(listener, s.TLSCertFile, s.TLSKeyFile)
+	// Certificates were loaded into TLSConfig
+	return s.Server.ServeTLS(listener, "", "")
+}
+
+func (s *Server) usesTLS() bool {
+	return s.usesTLSFiles() || s.TLSMode == TLSAuto
+}
+
+func (s *Server) usesTLSFiles() bool {
+	return s.TLSCertFile != "" || s.TLSCertDirectory != "" || len(s.tlsKeyPairs) > 0
+}
+
+func (s *Server) setupTLS(ctx context.Context) error {
+	switch {
+	case s.usesTLSFiles():
+		return s.loadTLSCertificates(ctx)
+	case s.TLSMode == TLSAuto:
+		ca, err := s.LocalCA()
+		if err != nil {
+			return err
+		}
+		s.ensureTLSConfig().GetCertificate = ca.GetCertificateFunc(s.autoTLSHosts()...)
+	default:
+		if opt := tlsOnlyOption(s.Server.TLSConfig); opt != "" {
+			return fmt.Errorf("%s requires TLS; specify a certificate with --cert or use --tls auto", opt)
+		}
+	}
+	return nil
+}
+
+// tlsOnlyOption gets the name of the flag that set an option in the TLS config
+// which only applies when serving TLS
+func tlsOnlyOption(cfg *tls.Config) string {
+	switch {
+	case cfg == nil:
+		return ""
+	case cfg.ClientCAs != nil:
+		return "--client-ca"
+	case cfg.ClientAuth != tls.NoClientCert:
+		return "--client-auth"
+	case cfg.MinVersion != 0:
+		// For example, TLS 1.3 is set by --tlsv1.3
+		return "--" + strings.ToLower(strings.ReplaceAll(tls.VersionName(cfg.MinVersion), " ", "v"))
+	case len(cfg.CipherSuites) > 0:
+		return "--ciphers"
+	case len(cfg.CurvePreferences) > 0:
+		return "--curves"
+	case len(cfg.NextProtos) > 0:
+		return "--next-protos"
+	case cfg.KeyLogWriter != nil:
+		return "--insecure-key-log-file"
+	}
+	return ""
+}
+
+// autoTLSHosts gets the names used in certificates issued by the local CA
+func (s *Server) autoTLSHosts() []string {
+	hosts := []string{"localhost", "127.0.0.1", "::1"}
+	host, _, _ := net.SplitHostPort(s.Server.Addr)
+	if ip := net.ParseIP(host); host != "" && (ip == nil || !ip.IsUnspecified()) {
+		hosts = append([]string{strings.ToLower(host)}, hosts...)
+	}
+	return hosts
+}
+
+func (s *Server) ensureTLSConfig() *tls.Config {
+	if s.Server.TLSConfig == nil {
+		s.Server.TLSConfig = &tls.Config{}
+	}
+	return s.Server.TLSConfig
+}
+
+// loadTLSCertificates loads the certificates into a store, which is watched
+// for changes until the context is done
+func (s *Server) loadTLSCertificates(ctx context.Context) error {
+	var password joetls.PasswordFunc
+	if s.TLSPassword != "" {
+		password = func() (string, error) {
+			return s.TLSPassword, nil
+		}
+	}
+
+	certs := joetls.NewCertificateStore(password)
+	if s.TLSCertFile != "" {
+		certs.AddKeyPair(joetls.KeyPair{
+			CertFile: s.TLSCertFile,
+			KeyFile:  s.TLSKeyFile,
+			CertType: s.TLSCertType,
+		})
+	}
+	for _, p := range s.tlsKeyPairs {
+		certs.AddKeyPair(p)
+	}
+	if s.TLSCertDirectory != "" {
+		certs.AddDirectory(s.TLSCertDirectory)
+	}
+	if err := certs.Reload(); err != nil {
+		return err
+	}
+
+	s.certs = certs
+	s.ensureTLSConfig().GetCertificate = certs.GetCertificate
+
+	if err := certs.Watch(ctx, reportCertificateError); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: unable to watch TLS certificates: %s\n", err)
+	}
+	return nil
+}
+
+func reportCertificateError(err error) {
+	fmt.Fprintf(os.Stderr, "warning: unable to reload TLS certificates: %s\n", err)
+}
+
+func (s *Server) setupLiveReload() {
+	if !s.watch {
+		return
+	}
+
+	dirs := s.watchDirs
+	if s.staticDir != "" {
+		dirs = append(dirs, s.staticDir)
+	}
+	if len(dirs) == 0 {
+		dirs = []string{"."}
+	}
+
+	live := NewLiveReload(dirs...)
+	ctx, cancel := context.WithCancel(context.Background())
+	s.Server.RegisterOnShutdown(func() {
+		cancel()
+		live.Close()
+	})
+	// Live reload is the innermost middleware so that the script is injected
+	// before responses are compressed
+	s.middleware = append([]MiddlewareFunc{live.Middleware(!s.noWatchScript)}, s.middleware...)
+
+	go func() {
+		if err := live.Watch(ctx); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: unable to watch files: %s\n", err)
+		}
+	}()
+}
+
+func (s *Server) setupAuth() error {
+	if s.auth == nil {
+		return nil
+	}
+	opts := *s.auth
+	opts.impliedMode()
+
+	m, err := NewAuthMiddleware(opts)
+	if err != nil {
+		return err
+	}
+	s.authMiddleware = m
+	return nil
+}
+
+func (s *Server) setupCORS() error {
+	if s.cors == nil {
+		return nil
+	}
+	return s.cors.validate()
+}
+
+func (s *Server) setupRateLimit() error {
+	if s.rateLimit == nil {
+		return nil
+	}
+
+	m, err := NewRateLimitMiddleware(*s.rateLimit)
+	if err != nil {
+		return err
+	}
+	s.rateLimiter = m
+	return nil
 }
 
 func (s *Server) ensureMux() (mux, error) {
+	// After middleware is applied, the handler no longer is the mux
+	if s.mux != nil {
+		return s.mux, nil
+	}
 	if m, ok := s.Server.Handler.(mux); ok {
 		return m, nil
 	}
@@ -387,6 +882,9 @@ func (s *Server) ensureMux() (mux, error) {
 
 func (s *Server) applyMiddleware() {
 	h := s.Server.Handler
+	if m, ok := h.(mux); ok {
+		s.mux = m
+	}
 	for _, m := range s.middleware {
 		h = m(h)
 	}
@@ -405,10 +903,17 @@ func (s *Server) OpenInBrowser(path ...string) error {
 	return exec.Open(bind.String())
 }
 
-// ReloadAll causes the server to reload all reloadable handlers.
-// To support reloading, the built-in mux must be used. In particular,
+// ReloadAll causes the server to reload all reloadable handlers and
+// the TLS certificates loaded from files.
+// To support reloading handlers, the built-in mux must be used. In particular,
 // you can't specify a handler or handler factory directly.
 func (s *Server) ReloadAll() {
+	if s.certs != nil {
+		if err := s.certs.Reload(); err != nil {
+			reportCertificateError(err)
+		}
+	}
+
 	mux, _ := s.ensureMux()
 	if reload, ok := mux.(reloadableMux); ok {
 		reload.ReloadAll()
@@ -493,6 +998,25 @@ func (s *Server) SetHideDirectoryListings(v bool) error {
 	return nil
 }
 
+func (s *Server) SetSPA(file string) error {
+	s.spa = file
+	return nil
+}
+
+func (s *Server) SetNotFoundPage(file string) error {
+	s.notFoundPage = file
+	return nil
+}
+
+func (s *Server) AddCacheControl(rule string) error {
+	r, err := ParseCacheControlRule(rule)
+	if err != nil {
+		return err
+	}
+	s.cacheControl = append(s.cacheControl, r)
+	return nil
+}
+
 func (s *Server) SetAccessLog(v string) error {
 	s.accessLog = v
 	return nil
@@ -513,6 +1037,181 @@ func (s *Server) SetTLSKeyFile(v string) error {
 	return nil
 }
 
+func (s *Server) SetTLSCertDirectory(v string) error {
+	s.TLSCertDirectory = v
+	return nil
+}
+
+func (s *Server) AddTLSKeyPair(v joetls.KeyPair) error {
+	s.tlsKeyPairs = append(s.tlsKeyPairs, v)
+	return nil
+}
+
+func (s *Server) SetWatch(v bool) error {
+	s.watch = v
+	return nil
+}
+
+func (s *Server) SetWatchScript(v bool) error {
+	s.noWatchScript = !v
+	return nil
+}
+
+func (s *Server) AddWatchDirectory(dir string) error {
+	s.watchDirs = append(s.watchDirs, dir)
+	return nil
+}
+
+func (s *Server) SetCompression(opts CompressionOptions) error {
+	s.compression = &opts
+	return nil
+}
+
+func (s *Server) SetCORS(opts CORSOptions) error {
+	s.cors = &opts
+	return nil
+}
+
+func (s *Server) SetCORSEnabled(v bool) error {
+	if !v {
+		s.cors = nil
+		return nil
+	}
+	s.ensureCORS()
+	return nil
+}
+
+func (s *Server) AddCORSOrigin(origin string) error {
+	c := s.ensureCORS()
+	c.AllowedOrigins = append(c.AllowedOrigins, origin)
+	return nil
+}
+
+func (s *Server) SetCORSMethods(v []string) error {
+	s.ensureCORS().AllowedMethods = v
+	return nil
+}
+
+func (s *Server) SetCORSHeaders(v []string) error {
+	s.ensureCORS().AllowedHeaders = v
+	return nil
+}
+
+func (s *Server) SetCORSCredentials(v bool) error {
+	s.ensureCORS().AllowCredentials = v
+	return nil
+}
+
+func (s *Server) SetCORSMaxAge(v time.Duration) error {
+	s.ensureCORS().MaxAge = v
+	return nil
+}
+
+func (s *Server) ensureCORS() *CORSOptions {
+	if s.cors == nil {
+		s.cors = new(CORSOptions)
+	}
+	return s.cors
+}
+
+func (s *Server) SetAuth(opts AuthOptions) error {
+	s.auth = &opts
+	return nil
+}
+
+func (s *Server) SetAuthMode(v AuthMode) error {
+	s.ensureAuth().Mode = v
+	return nil
+}
+
+func (s *Server) AddAuthUser(userPassword string) error {
+	user, password, err := splitUserPassword(userPassword)
+	if err != nil {
+		return err
+	}
+	s.ensureAuth().addUser(user, password)
+	return nil
+}
+
+func (s *Server) SetHTPasswdFile(v string) error {
+	s.ensureAuth().HTPasswdFile = v
+	return nil
+}
+
+func (s *Server) SetAuthTokenFile(v string) error {
+	s.ensureAuth().TokenFile = v
+	return nil
+}
+
+func (s *Server) ensureAuth() *AuthOptions {
+	if s.auth == nil {
+		s.auth = new(AuthOptions)
+	}
+	return s.auth
+}
+
+func (s *Server) SetRateLimit(opts RateLimitOptions) error {
+	s.rateLimit = &opts
+	return nil
+}
+
+func (s *Server) SetRequestRate(v Rate) error {
+	s.ensureRateLimit().Rate = v
+	return nil
+}
+
+func (s *Server) SetRateLimitBurst(v int) error {
+	s.ensureRateLimit().Burst = v
+	return nil
+}
+
+func (s *Server) SetRateLimitKey(v string) error {
+	s.ensureRateLimit().Key = v
+	return nil
+}
+
+func (s *Server) SetMaxConcurrent(v int) error {
+	s.maxConcurrent = v
+	return nil
+}
+
+func (s *Server) ensureRateLimit() *RateLimitOptions {
+	if s.rateLimit == nil {
+		s.rateLimit = new(RateLimitOptions)
+	}
+	return s.rateLimit
+}
+
+func (s *Server) SetTLSConfig(v *tls.Config) error {
+	s.Server.TLSConfig = v
+	return nil
+}
+
+func (s *Server) SetTLSMode(v TLSMode) error {
+	s.TLSMode = v
+	return nil
+}
+
+func (s *Server) SetLocalCADirectory(v string) error {
+	s.localCADir = v
+	return nil
+}
+
+// LocalCA loads the local CA used by TLSAuto, creating it if it doesn't exist
+func (s *Server) LocalCA() (*joetls.LocalCA, error) {
+	return joetls.LoadOrCreateLocalCA(s.localCADir)
+}
+
+func (s *Server) SetTLSCertType(v joetls.CertType) error {
+	s.TLSCertType = v
+	return nil
+}
+
+func (s *Server) SetTLSPassword(v string) error {
+	s.TLSPassword = v
+	return nil
+}
+
 func (s *Server) setAction(value cli.Action) error {
 	s.Action = value
 	return nil
@@ -550,24 +1249,70 @@ func (s *Server) actualShutdown() ReadyFunc {
 }
 
 func (s *Server) checkIfListening() error {
-	if s.actualBind.addr == "" {
+	if addr, _ := s.bind(); addr == "" {
 		return ErrNotListening
 	}
 	return nil
 }
 
+func (s *Server) setActualBind(addr string, useTLS bool) {
+	s.actualBind.mu.Lock()
+	defer s.actualBind.mu.Unlock()
+	s.actualBind.addr = addr
+	s.actualBind.tls = useTLS
+}
+
+func (s *Server) bind() (addr string, useTLS bool) {
+	s.actualBind.mu.Lock()
+	defer s.actualBind.mu.Unlock()
+	return s.actualBind.addr, s.actualBind.tls
+}
+
 func (s *Server) url() *url.URL {
-	if s.actualBind.addr == "" {
+	addr, useTLS := s.bind()
+	if addr == "" {
 		return nil
 	}
 	proto := "http://"
-	if s.actualBind.tls {
+	if useTLS {
 		proto = "https://"
 	}
-	res, _ := url.Parse(proto + s.actualBind.addr)
+	res, _ := url.Parse(proto + addr)
 	return res
 }
 
 func withAdapter[T any](fn func(*Server, T) error, value T) Option {
 	return option[T]{value, fn}
 }
+
+func (m TLSMode) String() string {
+	if m >= 0 && m < maxTLSMode {
+		return tlsModeStrings[int(m)]
+	}
+	return ""
+}
+
+// MarshalText provides the textual representation
+func (m TLSMode) MarshalText() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalText converts the textual representation
+func (m *TLSMode) UnmarshalText(b []byte) error {
+	return m.Set(string(b))
+}
+
+// Set sets the mode from its name
+func (m *TLSMode) Set(arg string) error {
+	for i, s := range tlsModeStrings {
+		if strings.TrimSpace(arg) == s {
+			*m = TLSMode(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown TLS mode %q", arg)
+}
+
+func (*TLSMode) Synopsis() string {
+	return "{files|auto}"
+}
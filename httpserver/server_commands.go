@@ -8,12 +8,15 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"os"
+	"os/signal"
 	"reflect"
 	"syscall"
 	"time"
 
 	"github.com/Carbonfrost/joe-cli"
 	"github.com/Carbonfrost/joe-cli-http/httpclient"
+	joetls "github.com/Carbonfrost/joe-cli-http/tls"
 	"github.com/Carbonfrost/joe-cli/extensions/bind"
 	"github.com/Carbonfrost/joe-cli/extensions/provider"
 )
@@ -22,6 +25,8 @@ const (
 	listenerCategory = "Listener options"
 	advancedCategory = "Advanced options"
 	serverCategory   = "Server options"
+	corsCategory     = "CORS options"
+	authCategory     = "Authentication options"
 
 	allowStartupTime = 1 * time.Second
 )
@@ -54,12 +59,37 @@ func FlagsAndArgs() cli.Action {
 			{Uses: SetMaxHeaderBytes()},
 			{Uses: SetStaticDirectory()},
 			{Uses: SetHideDirectoryListings()},
+			{Uses: SetSPA()},
+			{Uses: SetNotFoundPage()},
+			{Uses: SetCacheControl()},
 			{Uses: SetOpenInBrowser()},
+			{Uses: SetWatch()},
+			{Uses: SetNoWatchScript()},
+			{Uses: SetCompression()},
+			{Uses: SetCORS()},
+			{Uses: SetCORSOrigin()},
+			{Uses: SetCORSMethods()},
+			{Uses: SetCORSHeaders()},
+			{Uses: SetCORSCredentials()},
+			{Uses: SetCORSMaxAge()},
+			{Uses: SetAuth()},
+			{Uses: SetAuthUser()},
+			{Uses: SetHTPasswdFile()},
+			{Uses: SetAuthTokenFile()},
+			{Uses: SetRateLimit()},
+			{Uses: SetRateLimitBurst()},
+			{Uses: SetRateLimitKey()},
+			{Uses: SetMaxConcurrent()},
 			{Uses: SetAccessLog()},
 			{Uses: SetNoAccessLog()},
 			{Uses: SetServerHeader()},
 			{Uses: SetTLSCertFile()},
 			{Uses: SetTLSKeyFile()},
+			{Uses: SetTLSCertType()},
+			{Uses: SetTLSPassword()},
+			{Uses: SetTLSMode()},
+			{Uses: SetTLSCertDirectory()},
+			{Uses: SetTLSKeyPair()},
 		}...),
 	)
 }
@@ -234,6 +264,47 @@ func SetHideDirectoryListings() cli.Action {
 	)
 }
 
+// SetSPA sets the file served for the routes of a single-page application
+func SetSPA(v ...string) cli.Action {
+	return cli.Pipeline(
+		&cli.Prototype{
+			Name:     "spa",
+			HelpText: "Serve the {FILE}, such as index.html, for requests that don't match a file so that a single-page application can handle its own routes",
+			Category: serverCategory,
+		},
+		bind.Action(WithSPA, bind.Exact(v...)),
+		tagged,
+	)
+}
+
+// SetNotFoundPage sets the file served for requests that don't match a file
+func SetNotFoundPage(v ...string) cli.Action {
+	return cli.Pipeline(
+		&cli.Prototype{
+			Name:     "not-found",
+			HelpText: "Serve the {FILE}, such as 404.html, with 404 Not Found for requests that don't match a file",
+			Category: serverCategory,
+		},
+		bind.Action(WithNotFoundPage, bind.Exact(v...)),
+		tagged,
+	)
+}
+
+// SetCacheControl adds a rule which sets Cache-Control for static files
+func SetCacheControl(v ...string) cli.Action {
+	return cli.Pipeline(
+		&cli.Prototype{
+			Name:      "cache-control",
+			UsageText: "GLOB=VALUE",
+			HelpText:  "Set Cache-Control for static files that match the glob, as in *.js=immutable",
+			Category:  serverCategory,
+			Options:   cli.EachOccurrence,
+		},
+		bind.Action(AddCacheControl, bind.Exact(v...)),
+		tagged,
+	)
+}
+
 // SetOpenInBrowser causes the default Web browser to open when the server
 // is ready
 func SetOpenInBrowser() cli.Action {
@@ -249,6 +320,253 @@ func SetOpenInBrowser() cli.Action {
 	)
 }
 
+// SetWatch causes browsers to reload when files in the static directories
+// change.  An events endpoint is served at LiveReloadPath, and a script
+// which subscribes to it is injected into HTML pages.
+func SetWatch() cli.Action {
+	return cli.Pipeline(
+		&cli.Prototype{
+			Name:     "watch",
+			HelpText: "Reload the Web browser when files in the static directories change",
+			Category: serverCategory,
+			Value:    new(bool),
+		},
+		cli.At(cli.ActionTiming, WithWatch(true)),
+		tagged,
+	)
+}
+
+// SetNoWatchScript prevents the live reload script from being injected into
+// HTML pages, which is useful when pages subscribe to the events endpoint
+// themselves
+func SetNoWatchScript() cli.Action {
+	return cli.Pipeline(
+		&cli.Prototype{
+			Name:     "no-watch-script",
+			HelpText: "When watching, don't inject the live reload script into HTML pages",
+			Category: serverCategory,
+			Value:    new(bool),
+		},
+		cli.At(cli.ActionTiming, WithWatchScript(false)),
+		tagged,
+	)
+}
+
+// SetCompression enables compressing responses with zstd, brotli, or gzip
+// depending upon what the client accepts
+func SetCompression() cli.Action {
+	return cli.Pipeline(
+		&cli.Prototype{
+			Name:     "compress",
+			HelpText: "Compress responses using zstd, br, or gzip when the client accepts them",
+			Category: serverCategory,
+			Value:    new(bool),
+		},
+		cli.At(cli.ActionTiming, WithCompression(CompressionOptions{})),
+		tagged,
+	)
+}
+
+// SetCORS enables cross-origin resource sharing (CORS).  By default,
+// any origin is allowed.
+func SetCORS() cli.Action {
+	return cli.Pipeline(
+		&cli.Prototype{
+			Name:     "cors",
+			HelpText: "Enable cross-origin resource sharing (CORS) for any origin",
+			Category: corsCategory,
+			Value:    new(bool),
+		},
+		cli.At(cli.ActionTiming, WithCORSEnabled(true)),
+		tagged,
+	)
+}
+
+// SetCORSOrigin enables CORS and adds an origin which is allowed
+func SetCORSOrigin(v ...string) cli.Action {
+	return cli.Pipeline(
+		&cli.Prototype{
+			Name:     "cors-origin",
+			HelpText: "Allow the {ORIGIN} for CORS, which can use a wildcard for subdomains, as in https://*.example.com",
+			Category: corsCategory,
+			Options:  cli.EachOccurrence,
+		},
+		bind.Action(AddCORSOrigin, bind.Exact(v...)),
+		tagged,
+	)
+}
+
+// SetCORSMethods enables CORS and sets the methods which are allowed
+func SetCORSMethods(v ...[]string) cli.Action {
+	return cli.Pipeline(
+		&cli.Prototype{
+			Name:     "cors-methods",
+			HelpText: "List of {METHODS} allowed for CORS",
+			Category: corsCategory,
+		},
+		bind.Action(WithCORSMethods, bind.Exact(v...)),
+		tagged,
+	)
+}
+
+// SetCORSHeaders enables CORS and sets the request headers which are allowed
+func SetCORSHeaders(v ...[]string) cli.Action {
+	return cli.Pipeline(
+		&cli.Prototype{
+			Name:     "cors-headers",
+			HelpText: "List of request {HEADERS} allowed for CORS",
+			Category: corsCategory,
+		},
+		bind.Action(WithCORSHeaders, bind.Exact(v...)),
+		tagged,
+	)
+}
+
+// SetCORSCredentials enables CORS and allows credentials.  The origins must
+// be specified with --cors-origin.
+func SetCORSCredentials() cli.Action {
+	return cli.Pipeline(
+		&cli.Prototype{
+			Name:     "cors-credentials",
+			HelpText: "Allow credentials such as cookies for CORS from the origins specified with --cors-origin",
+			Category: corsCategory,
+			Value:    new(bool),
+		},
+		cli.At(cli.ActionTiming, WithCORSCredentials(true)),
+		tagged,
+	)
+}
+
+// SetCORSMaxAge enables CORS and sets how long preflight results can be cached
+func SetCORSMaxAge(d ...time.Duration) cli.Action {
+	return cli.Pipeline(
+		&cli.Prototype{
+			Name:     "cors-max-age",
+			HelpText: "Sets the {DURATION} that the results of CORS preflight requests can be cached",
+			Category: corsCategory,
+		},
+		bind.Action(WithCORSMaxAge, bind.Exact(d...)),
+		tagged,
+	)
+}
+
+// SetAuth sets the scheme used to authenticate requests
+func SetAuth(v ...AuthMode) cli.Action {
+	return cli.Pipeline(
+		&cli.Prototype{
+			Name:     "auth",
+			HelpText: "Require authentication: basic (using --user or --htpasswd) or bearer (using --token-file)",
+			Category: authCategory,
+		},
+		bind.Action(WithAuthMode, bind.Exact(v...)),
+		tagged,
+	)
+}
+
+// SetAuthUser adds a user allowed by basic authentication
+func SetAuthUser(v ...string) cli.Action {
+	return cli.Pipeline(
+		&cli.Prototype{
+			Name:      "user",
+			UsageText: "USER:PASSWORD",
+			HelpText:  "Allow the user and password with basic authentication",
+			Category:  authCategory,
+			Options:   cli.EachOccurrence,
+		},
+		bind.Action(AddAuthUser, bind.Exact(v...)),
+		tagged,
+	)
+}
+
+// SetHTPasswdFile sets the htpasswd file containing the users allowed by
+// basic authentication
+func SetHTPasswdFile(v ...*cli.File) cli.Action {
+	return cli.Pipeline(
+		&cli.Prototype{
+			Name:     "htpasswd",
+			HelpText: "Allow the users in the htpasswd {FILE} with basic authentication (bcrypt, SHA, or APR1 hashes)",
+			Value:    new(cli.File),
+			Category: authCategory,
+			Options:  cli.MustExist,
+		},
+		bind.Action(WithHTPasswdFile, bind.Exact(v...).(*bind.FileBinder).Name()),
+		tagged,
+	)
+}
+
+// SetAuthTokenFile sets the file containing the tokens allowed by bearer
+// authentication
+func SetAuthTokenFile(v ...*cli.File) cli.Action {
+	return cli.Pipeline(
+		&cli.Prototype{
+			Name:     "token-file",
+			HelpText: "Allow the tokens listed in {FILE} with bearer authentication",
+			Value:    new(cli.File),
+			Category: authCategory,
+			Options:  cli.MustExist,
+		},
+		bind.Action(WithAuthTokenFile, bind.Exact(v...).(*bind.FileBinder).Name()),
+		tagged,
+	)
+}
+
+// SetRateLimit limits the rate of requests.  Requests over the limit
+// receive 429 Too Many Requests.
+func SetRateLimit(v ...Rate) cli.Action {
+	return cli.Pipeline(
+		&cli.Prototype{
+			Name:     "rate-limit",
+			HelpText: "Limit requests to the {RATE}, as in 100/s, responding with 429 over the limit",
+			Category: advancedCategory,
+			Value:    new(Rate),
+		},
+		bind.Action(WithRequestRate, bind.Exact(v...)),
+		tagged,
+	)
+}
+
+// SetRateLimitBurst sets the number of requests allowed at once above the rate limit
+func SetRateLimitBurst(v ...int) cli.Action {
+	return cli.Pipeline(
+		&cli.Prototype{
+			Name:     "burst",
+			HelpText: "Allow a burst of {N} requests above the rate limit",
+			Category: advancedCategory,
+			Uses:     cli.Requires("rate-limit"),
+		},
+		bind.Action(WithRateLimitBurst, bind.Exact(v...)),
+		tagged,
+	)
+}
+
+// SetRateLimitKey sets how clients are distinguished by the rate limit
+func SetRateLimitKey(v ...string) cli.Action {
+	return cli.Pipeline(
+		&cli.Prototype{
+			Name:     "rate-limit-key",
+			HelpText: "Apply the rate limit per {KEY}: global (the default), ip, or header:NAME",
+			Category: advancedCategory,
+			Uses:     cli.Requires("rate-limit"),
+		},
+		bind.Action(WithRateLimitKey, bind.Exact(v...)),
+		tagged,
+	)
+}
+
+// SetMaxConcurrent sets the maximum number of requests handled at the same
+// time.  Requests over the limit receive 503 Service Unavailable.
+func SetMaxConcurrent(v ...int) cli.Action {
+	return cli.Pipeline(
+		&cli.Prototype{
+			Name:     "max-concurrent",
+			HelpText: "Handle at most {N} requests at the same time, responding with 503 over the limit",
+			Category: advancedCategory,
+		},
+		bind.Action(WithMaxConcurrent, bind.Exact(v...)),
+		tagged,
+	)
+}
+
 // SetHandler adds the specified handler to the mux. This can be called multiple
 // times. SetHandler only works if a Registry named "handlers" is present
 // in the context to convert the handler spec to the correct implementation.
@@ -295,10 +613,17 @@ func HandleFunc(path string, h http.HandlerFunc) cli.Action {
 	})
 }
 
-// HandleSpec registers the given handler spec with the context server
+// HandleSpec registers the given handler spec with the context server.
+// Authentication is required for the handler when the options of the
+// virtual path contain auth (basic or bearer), auth_user (USER:PASSWORD),
+// auth_htpasswd (FILE), auth_tokens (FILE), or auth_realm.  Faults are
+// injected when the options contain fault_latency, fault_error_rate,
+// fault_error_codes, fault_reset_rate, fault_truncate_rate, fault_trickle_rate,
+// fault_trickle_speed, or fault_seed, as described by [FaultOptions].  These
+// options are not passed to the handler spec.
 func HandleSpec(vpath httpclient.VirtualPath, spec HandlerSpec) cli.Action {
 	return cli.ActionFunc(func(c *cli.Context) error {
-		handler, err := spec(c, vpath)
+		handler, err := newSpecHandler(c, vpath, spec)
 		if err != nil {
 			return err
 		}
@@ -306,6 +631,35 @@ func HandleSpec(vpath httpclient.VirtualPath, spec HandlerSpec) cli.Action {
 	})
 }
 
+// SetConfig serves the routes described in a configuration file, which can
+// be YAML, JSON, or TOML.  The routes are reloaded when the server is reloaded.
+// This handler is not included in [FlagsAndArgs]
+func SetConfig(v ...*cli.File) cli.Action {
+	return cli.Pipeline(
+		&cli.Prototype{
+			Name:     "config",
+			HelpText: "Serve the routes described in the configuration {FILE} (YAML, JSON, or TOML)",
+			Value:    new(cli.File),
+			Options:  cli.MustExist,
+			Category: serverCategory,
+		},
+		bind.Action(HandleConfig, bind.Exact(v...).(*bind.FileBinder).Name()),
+		tagged,
+	)
+}
+
+// HandleConfig registers a handler for the routes in the configuration file
+// with the context server
+func HandleConfig(filename string) cli.Action {
+	return cli.ActionFunc(func(c *cli.Context) error {
+		handler, err := NewConfigHandler(c, filename)
+		if err != nil {
+			return err
+		}
+		return FromContext(c).Handle("/", handler)
+	})
+}
+
 // SetFileServerHandler adds the specified file server handler to the mux.
 // This can be called multiple times.
 // This handler is not included in [FlagsAndArgs]
@@ -318,17 +672,24 @@ func SetFileServerHandler(v ...httpclient.VirtualPath) cli.Action {
 			Value:     new(httpclient.VirtualPath),
 			Options:   cli.EachOccurrence,
 		},
-		bind.Action2(HandleSpec, bind.Exact(v...), bind.Exact(FileServerHandlerSpec())),
+		bind.Action(handleFileServer, bind.Exact(v...)),
 		tagged,
 	)
 }
 
+func handleFileServer(vpath httpclient.VirtualPath) cli.Action {
+	return cli.Pipeline(
+		HandleSpec(vpath, FileServerHandlerSpec()),
+		AddWatchDirectory(vpath.PhysicalPath),
+	)
+}
+
 func SetAccessLog(v ...string) cli.Action {
 	return cli.Pipeline(
 		&cli.Prototype{
 			Name:     "access-log",
 			Aliases:  []string{"a"},
-			HelpText: "Set access log format",
+			HelpText: "Set access log format.  Use %(user) for the authenticated user",
 			Category: advancedCategory,
 		},
 		bind.Action(WithAccessLog, bind.Exact(v...)),
@@ -383,21 +744,130 @@ func SetTLSCertFile(v ...*cli.File) cli.Action {
 			HelpText: "Specify the FILE that contains the TLS certificate",
 			Category: listenerCategory,
 			Options:  cli.MustExist,
-			Uses:     cli.Requires("key"),
 		},
 		bind.Action(WithTLSCertFile, bind.Exact(v...).(*bind.FileBinder).Name()),
 		tagged,
 	)
 }
 
+// SetTLSCertDirectory sets a directory containing certificate and key pairs
+// which are selected using the server name (SNI) requested by the client
+func SetTLSCertDirectory(v ...*cli.File) cli.Action {
+	return cli.Pipeline(
+		&cli.Prototype{
+			Name:     "cert-dir",
+			HelpText: "Serve the certificates in {DIRECTORY}, selected by the server name the client requests",
+			Value:    new(cli.File),
+			Category: listenerCategory,
+			Options:  cli.MustExist,
+		},
+		bind.Action(WithTLSCertDirectory, bind.Exact(v...).(*bind.FileBinder).Name()),
+		tagged,
+	)
+}
+
+// SetTLSKeyPair adds a certificate and key pair which is selected
+// using the server name (SNI) requested by the client
+func SetTLSKeyPair(v ...joetls.KeyPair) cli.Action {
+	return cli.Pipeline(
+		&cli.Prototype{
+			Name:     "cert-pair",
+			HelpText: "Serve an additional certificate and key, selected by the server name the client requests",
+			Category: listenerCategory,
+			Options:  cli.EachOccurrence,
+		},
+		bind.Action(AddTLSKeyPair, bind.Exact(v...)),
+		tagged,
+	)
+}
+
+// SetTLSCertType sets the format of the TLS certificate file, either PEM or P12
+func SetTLSCertType(v ...joetls.CertType) cli.Action {
+	return cli.Pipeline(
+		&cli.Prototype{
+			Name:     "cert-type",
+			HelpText: "Format of the TLS certificate file, {TYPE} is PEM (default) or P12",
+			Category: listenerCategory,
+		},
+		bind.Action(WithTLSCertType, bind.Exact(v...)),
+		tagged,
+	)
+}
+
+// SetTLSPassword sets the pass phrase used to decrypt the TLS private key
+func SetTLSPassword(v ...string) cli.Action {
+	return cli.Pipeline(
+		&cli.Prototype{
+			Name:     "pass",
+			HelpText: "Pass {PHRASE} for the TLS private key",
+			Category: listenerCategory,
+		},
+		bind.Action(WithTLSPassword, bind.Exact(v...)),
+		tagged,
+	)
+}
+
+// SetTLSMode sets how the server obtains its certificate.  Using auto
+// issues certificates from a local CA, which can be exported using
+// ExportLocalCA.
+func SetTLSMode(v ...TLSMode) cli.Action {
+	return cli.Pipeline(
+		&cli.Prototype{
+			Name:     "tls",
+			HelpText: "Sets how the TLS certificate is obtained: files (from --cert, the default) or auto (issued by a local CA)",
+			Category: listenerCategory,
+		},
+		bind.Action(WithTLSMode, bind.Exact(v...)),
+		tagged,
+	)
+}
+
+// ExportLocalCA writes the certificate of the local CA used by --tls auto to
+// stdout so that it can be trusted by browsers and clients.  The CA is created
+// if it doesn't exist.
+func ExportLocalCA() cli.Action {
+	return cli.ActionOf(func(c *cli.Context) error {
+		ca, err := FromContext(c).LocalCA()
+		if err != nil {
+			return err
+		}
+		_, err = c.Stdout.Write(ca.CertPEM())
+		return err
+	})
+}
+
+// PrintLocalCAPath provides a flag that prints the path to the certificate
+// of the local CA and exits
+func PrintLocalCAPath() cli.Action {
+	return cli.Pipeline(
+		&cli.Prototype{
+			Name:     "local-ca-path",
+			Value:    cli.Bool(),
+			Options:  cli.Exits,
+			HelpText: "Print the path to the local CA certificate and exit",
+		},
+		cli.At(cli.ActionTiming, cli.ActionOf(func(c *cli.Context) error {
+			ca, err := FromContext(c).LocalCA()
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(c.Stdout, ca.CertFile())
+			return nil
+		})),
+		tagged,
+	)
+}
+
 // RunServer locates the server in context and runs it until interrupt signal
 // is detected. Optional actions run just before the server starts up, typically
 // used to provide context-bound modifications to the server just in time.
+// The hangup signal (SIGHUP) reloads the server by calling ReloadAll.
 func RunServer(actionopt ...cli.Action) cli.Action {
 	return cli.Setup{
-		Uses: cli.HandleSignal(syscall.SIGHUP, syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT),
+		Uses: cli.HandleSignal(syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT),
 		Action: cli.Pipeline(cli.ActionOf(actionopt), cli.ActionFunc(func(c *cli.Context) error {
 			srv := FromContext(c)
+			reloadOnHangup(c, srv)
 			c.After(cli.ActionOf(func() {
 				// Shutting down happens in After because the signal handler will be unregistered
 				timeoutCtx, cancel := context.WithTimeout(context.Background(), srv.ShutdownTimeout)
@@ -412,6 +882,23 @@ func RunServer(actionopt ...cli.Action) cli.Action {
 	}
 }
 
+func reloadOnHangup(c context.Context, srv *Server) {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+
+	go func() {
+		defer signal.Stop(hangup)
+		for {
+			select {
+			case <-hangup:
+				srv.ReloadAll()
+			case <-c.Done():
+				return
+			}
+		}
+	}()
+}
+
 func execContext(c context.Context, fn func() error, ready func(context.Context)) error {
 	var (
 		errors = make(chan error, 1)
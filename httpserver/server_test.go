@@ -1,4 +1,4 @@
-// Copyright 2025 The Joe-cli Authors. All rights reserved.
+// Copyright 2025, 2026 The Joe-cli Authors. All rights reserved.
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
@@ -6,13 +6,19 @@ package httpserver_test
 
 import (
 	"context"
+	"crypto/tls"
+	"net/http"
+	"os"
+	"path/filepath"
 
 	"github.com/Carbonfrost/joe-cli"
 	"github.com/Carbonfrost/joe-cli-http/httpserver"
+	joetls "github.com/Carbonfrost/joe-cli-http/tls"
 	"github.com/Carbonfrost/joe-cli/joe-clifakes"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gstruct"
 )
 
 var _ = Describe("Server", func() {
@@ -67,4 +73,152 @@ var _ = Describe("Server", func() {
 			Expect(fakeAct.ExecuteCallCount()).To(Equal(1))
 		})
 	})
+
+	Describe("TLS", func() {
+
+		It("loads the certificate from PKCS#12", func() {
+			s := httpserver.New(
+				httpserver.WithAddr("127.0.0.1:0"),
+				httpserver.WithTLSCertFile("../tls/testdata/client.p12"),
+				httpserver.WithTLSCertType(joetls.CertTypeP12),
+				httpserver.WithTLSPassword("secret"),
+			)
+			s.Server.Handler = http.NotFoundHandler()
+			go func() {
+				defer GinkgoRecover()
+				Expect(s.ListenAndServe()).To(MatchError(http.ErrServerClosed))
+			}()
+			Eventually(s.ReportListening).Should(Succeed())
+			Expect(s.Close()).To(Succeed())
+
+			cert, err := s.TLSConfig.GetCertificate(&tls.ClientHelloInfo{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cert.Certificate).To(HaveLen(1))
+		})
+
+		It("reloads the certificate from files", func() {
+			dir := GinkgoT().TempDir()
+			certFile := filepath.Join(dir, "cert.pem")
+			keyFile := filepath.Join(dir, "key.pem")
+			copyFile("../tls/testdata/cert.pem", certFile)
+			copyFile("../tls/testdata/key.pem", keyFile)
+
+			s := httpserver.New(
+				httpserver.WithAddr("127.0.0.1:0"),
+				httpserver.WithTLSCertFile(certFile),
+				httpserver.WithTLSKeyFile(keyFile),
+			)
+			s.Server.Handler = http.NotFoundHandler()
+			go func() {
+				defer GinkgoRecover()
+				Expect(s.ListenAndServe()).To(MatchError(http.ErrServerClosed))
+			}()
+			Eventually(s.ReportListening).Should(Succeed())
+			defer s.Close()
+
+			before, _ := s.TLSConfig.GetCertificate(&tls.ClientHelloInfo{})
+			s.ReloadAll()
+			after, _ := s.TLSConfig.GetCertificate(&tls.ClientHelloInfo{})
+			Expect(after).NotTo(BeIdenticalTo(before))
+		})
+
+		DescribeTable("flags configure the TLS config", func(args string, expected Fields) {
+			var cfg *tls.Config
+			app := &cli.App{
+				Uses: httpserver.New(),
+				Action: func(c *cli.Context) {
+					cfg = httpserver.FromContext(c).TLSConfig
+				},
+			}
+			argv, _ := cli.Split("app " + args)
+			Expect(app.RunContext(context.Background(), argv)).To(Succeed())
+			Expect(cfg).To(PointTo(MatchFields(IgnoreExtras, expected)))
+		},
+			Entry("TLS version", "--tlsv1.3", Fields{
+				"MinVersion": Equal(uint16(tls.VersionTLS13)),
+				"MaxVersion": Equal(uint16(tls.VersionTLS13)),
+			}),
+			Entry("ciphers", "--ciphers TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256", Fields{
+				"CipherSuites": Equal([]uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256}),
+			}),
+			Entry("client CA implies require", "--client-ca ../tls/testdata/cert.pem", Fields{
+				"ClientAuth": Equal(tls.RequireAndVerifyClientCert),
+				"ClientCAs":  Not(BeNil()),
+			}),
+			Entry("client auth", "--client-ca ../tls/testdata/cert.pem --client-auth verify-if-given", Fields{
+				"ClientAuth": Equal(tls.VerifyClientCertIfGiven),
+			}),
+		)
+
+		It("issues certificates from the local CA in auto mode", func() {
+			dir := GinkgoT().TempDir()
+			s := httpserver.New(
+				httpserver.WithAddr("127.0.0.1:0"),
+				httpserver.WithTLSMode(httpserver.TLSAuto),
+				httpserver.WithLocalCADirectory(dir),
+			)
+			s.Server.Handler = http.NotFoundHandler()
+			go func() {
+				defer GinkgoRecover()
+				Expect(s.ListenAndServe()).To(MatchError(http.ErrServerClosed))
+			}()
+			Eventually(s.ReportListening).Should(Succeed())
+			defer s.Close()
+
+			ca, err := s.LocalCA()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ca.CertFile()).To(BeARegularFile())
+
+			cert, err := s.TLSConfig.GetCertificate(&tls.ClientHelloInfo{ServerName: "localhost"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cert.Leaf.DNSNames).To(ConsistOf("localhost"))
+		})
+
+		It("requires the password for an encrypted key", func() {
+			s := httpserver.New(
+				httpserver.WithAddr("127.0.0.1:0"),
+				httpserver.WithTLSCertFile("../tls/testdata/cert.pem"),
+				httpserver.WithTLSKeyFile("../tls/testdata/key-aes256.pem"),
+			)
+			Expect(s.ListenAndServe()).To(MatchError(joetls.ErrPasswordRequired))
+		})
+
+		DescribeTable("requires a certificate for options that only apply to TLS", func(args string, expected string) {
+			app := &cli.App{
+				Uses: httpserver.New(
+					httpserver.WithAddr("127.0.0.1:0"),
+					httpserver.WithNoAccessLog(),
+				),
+				Action: func(c *cli.Context) error {
+					return httpserver.FromContext(c).ListenAndServe()
+				},
+			}
+			argv, _ := cli.Split("app " + args)
+			Expect(app.RunContext(context.Background(), argv)).To(MatchError(expected + " requires TLS; specify a certificate with --cert or use --tls auto"))
+		},
+			Entry("client CA", "--client-ca ../tls/testdata/cert.pem", "--client-ca"),
+			Entry("client auth", "--client-auth request", "--client-auth"),
+			Entry("TLS version", "--tlsv1.3", "--tlsv1.3"),
+			Entry("ciphers", "--ciphers TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256", "--ciphers"),
+			Entry("key log file", "--insecure-key-log-file /dev/null", "--insecure-key-log-file"),
+		)
+	})
+
+	Describe("CORS", func() {
+
+		It("requires origins for credentials", func() {
+			s := httpserver.New(
+				httpserver.WithAddr("127.0.0.1:0"),
+				httpserver.WithNoAccessLog(),
+				httpserver.WithCORSCredentials(true),
+			)
+			Expect(s.ListenAndServe()).To(MatchError("CORS credentials require the allowed origins to be specified with --cors-origin"))
+		})
+	})
 })
+
+func copyFile(src, dst string) {
+	data, err := os.ReadFile(src)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(os.WriteFile(dst, data, 0o600)).To(Succeed())
+}
@@ -0,0 +1,338 @@
+// Copyright 2026 The Joe-cli Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package httpserver
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/Carbonfrost/joe-cli-http/httpclient"
+)
+
+// OverwritePolicy specifies what happens when an uploaded file already exists
+type OverwritePolicy int
+
+// Overwrite policies
+const (
+	// OverwriteDeny responds with 409 Conflict when the file exists
+	OverwriteDeny OverwritePolicy = iota
+
+	// OverwriteAllow replaces the file that exists
+	OverwriteAllow
+
+	// OverwriteRename saves the file with a new name such as file-1.txt
+	OverwriteRename
+	maxOverwritePolicy
+)
+
+// UploadOptions configures the upload handler
+type UploadOptions struct {
+	// MaxSize is the largest request body which is accepted.  When zero,
+	// a default of 100 MiB is used.
+	MaxSize int64
+
+	// Overwrite specifies what happens when an uploaded file already exists
+	Overwrite OverwritePolicy
+}
+
+type uploadHandler struct {
+	dir       string
+	maxSize   int64
+	overwrite OverwritePolicy
+	files     http.Handler
+}
+
+type uploadedFile struct {
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+
+	created bool
+	sum     []byte
+}
+
+const defaultUploadMaxSize = 100 << 20
+
+var overwritePolicyStrings = [...]string{
+	"deny",
+	"allow",
+	"rename",
+}
+
+var errUploadExists = errors.New("file already exists")
+
+// UploadHandlerSpec creates a handler which saves files uploaded to the directory
+// in the physical path of the virtual path.  The options max_size and overwrite
+// set the largest request body, such as 10M, and the overwrite policy (deny, allow,
+// or rename).
+func UploadHandlerSpec() HandlerSpec {
+	return func(_ context.Context, vp httpclient.VirtualPath) (http.Handler, error) {
+		var opts UploadOptions
+		if v, ok := vp.Options["max_size"]; ok {
+			var err error
+			if opts.MaxSize, err = ParseByteSize(v); err != nil {
+				return nil, fmt.Errorf("max_size: %w", err)
+			}
+		}
+		if v, ok := vp.Options["overwrite"]; ok {
+			if err := opts.Overwrite.Set(v); err != nil {
+				return nil, fmt.Errorf("overwrite: %w", err)
+			}
+		}
+		return http.StripPrefix(vp.RequestPath, NewUploadHandler(vp.PhysicalPath, opts)), nil
+	}
+}
+
+// NewUploadHandler provides a handler which saves uploaded files to the
+// directory.  PUT saves the request body as the file at the request path.  POST
+// with a multipart form saves each file in the form to the directory at the
+// request path.  The response lists the files that were saved and their SHA-256
+// checksums.  When one file is saved, the checksum is also in the Repr-Digest and
+// X-Checksum-Sha256 response headers.  GET and HEAD serve the files.
+func NewUploadHandler(dir string, opts UploadOptions) http.Handler {
+	maxSize := opts.MaxSize
+	if maxSize <= 0 {
+		maxSize = defaultUploadMaxSize
+	}
+	return &uploadHandler{
+		dir:       dir,
+		maxSize:   maxSize,
+		overwrite: opts.Overwrite,
+		files:     newFileServerHandler(dir, FileServerOptions{}),
+	}
+}
+
+func (h *uploadHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		h.files.ServeHTTP(w, r)
+		return
+	case http.MethodPut, http.MethodPost:
+	default:
+		w.Header().Set("Allow", "GET, HEAD, POST, PUT")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, h.maxSize)
+
+	var (
+		files []uploadedFile
+		err   error
+	)
+	if r.Method == http.MethodPut {
+		var f uploadedFile
+		f, err = h.save(r.URL.Path, r.Body)
+		files = append(files, f)
+	} else {
+		files, err = h.saveMultipart(r)
+	}
+	if err != nil {
+		uploadError(w, err)
+		return
+	}
+
+	status := http.StatusOK
+	if len(files) == 1 {
+		f := files[0]
+		w.Header().Set("Repr-Digest", "sha-256=:"+base64.StdEncoding.EncodeToString(f.sum)+":")
+		w.Header().Set("X-Checksum-Sha256", f.SHA256)
+		if f.created {
+			w.Header().Set("Location", path.Base(f.Name))
+			status = http.StatusCreated
+		}
+	}
+	writeJSON(w, status, map[string]any{"files": files})
+}
+
+func (h *uploadHandler) saveMultipart(r *http.Request) ([]uploadedFile, error) {
+	mr, err := r.MultipartReader()
+	if err != nil {
+		return nil, err
+	}
+
+	files := []uploadedFile{}
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			return files, nil
+		}
+		if err != nil {
+			return files, err
+		}
+		if part.FileName() == "" {
+			continue
+		}
+
+		name := path.Join(r.URL.Path, path.Base(filepath.ToSlash(part.FileName())))
+		f, err := h.save(name, part)
+		if err != nil {
+			return files, err
+		}
+		files = append(files, f)
+	}
+}
+
+// save writes the file at the path relative to the directory.  The file is
+// written to a temporary file first so that a failed upload doesn't leave
+// a partial file.
+func (h *uploadHandler) save(name string, body io.Reader) (uploadedFile, error) {
+	name = path.Clean("/" + name)
+	if name == "/" {
+		return uploadedFile{}, fmt.Errorf("file name is required")
+	}
+	dest := filepath.Join(h.dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return uploadedFile{}, err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(dest), ".upload-*")
+	if err != nil {
+		return uploadedFile{}, err
+	}
+	defer os.Remove(tmp.Name())
+
+	sha := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, sha), body)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return uploadedFile{}, err
+	}
+
+	dest, created, err := h.destination(dest)
+	if err != nil {
+		return uploadedFile{}, err
+	}
+	if err := os.Rename(tmp.Name(), dest); err != nil {
+		return uploadedFile{}, err
+	}
+	os.Chmod(dest, 0o644)
+
+	rel, _ := filepath.Rel(h.dir, dest)
+	sum := sha.Sum(nil)
+	return uploadedFile{
+		Name:    "/" + filepath.ToSlash(rel),
+		Size:    size,
+		SHA256:  hex.EncodeToString(sum),
+		created: created,
+		sum:     sum,
+	}, nil
+}
+
+// destination applies the overwrite policy to get the file name to use and
+// whether the file is new
+func (h *uploadHandler) destination(dest string) (string, bool, error) {
+	info, err := os.Stat(dest)
+	if errors.Is(err, fs.ErrNotExist) {
+		return dest, true, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	if info.IsDir() {
+		return "", false, errUploadExists
+	}
+
+	switch h.overwrite {
+	case OverwriteAllow:
+		return dest, false, nil
+	case OverwriteRename:
+		ext := filepath.Ext(dest)
+		base := strings.TrimSuffix(dest, ext)
+		for i := 1; ; i++ {
+			candidate := base + "-" + strconv.Itoa(i) + ext
+			if _, err := os.Stat(candidate); errors.Is(err, fs.ErrNotExist) {
+				return candidate, true, nil
+			}
+		}
+	}
+	return "", false, errUploadExists
+}
+
+func uploadError(w http.ResponseWriter, err error) {
+	var maxBytes *http.MaxBytesError
+	switch {
+	case errors.As(err, &maxBytes):
+		http.Error(w, fmt.Sprintf("request body exceeds %d bytes", maxBytes.Limit), http.StatusRequestEntityTooLarge)
+	case errors.Is(err, errUploadExists):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, http.ErrNotMultipart), errors.Is(err, http.ErrMissingBoundary):
+		http.Error(w, err.Error(), http.StatusUnsupportedMediaType)
+	case errors.Is(err, fs.ErrPermission):
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+	default:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	}
+}
+
+// ParseByteSize parses a number of bytes with an optional suffix K, M, or G
+// for KiB, MiB, or GiB, as in 10M
+func ParseByteSize(s string) (int64, error) {
+	text := strings.TrimSuffix(strings.TrimSuffix(strings.ToUpper(strings.TrimSpace(s)), "B"), "I")
+	shift := 0
+	switch {
+	case strings.HasSuffix(text, "K"):
+		shift = 10
+	case strings.HasSuffix(text, "M"):
+		shift = 20
+	case strings.HasSuffix(text, "G"):
+		shift = 30
+	}
+	if shift > 0 {
+		text = text[:len(text)-1]
+	}
+
+	n, err := strconv.ParseInt(text, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	return n << shift, nil
+}
+
+func (p OverwritePolicy) String() string {
+	if p >= 0 && p < maxOverwritePolicy {
+		return overwritePolicyStrings[int(p)]
+	}
+	return ""
+}
+
+// MarshalText provides the textual representation
+func (p OverwritePolicy) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+// UnmarshalText converts the textual representation
+func (p *OverwritePolicy) UnmarshalText(b []byte) error {
+	return p.Set(string(b))
+}
+
+// Set sets the policy from its name
+func (p *OverwritePolicy) Set(arg string) error {
+	for i, s := range overwritePolicyStrings {
+		if strings.TrimSpace(arg) == s {
+			*p = OverwritePolicy(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown overwrite policy %q", arg)
+}
+
+func (*OverwritePolicy) Synopsis() string {
+	return "{deny|allow|rename}"
+}
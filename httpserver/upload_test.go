@@ -0,0 +1,129 @@
+// Copyright 2026 The Joe-cli Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package httpserver_test
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Carbonfrost/joe-cli-http/httpserver"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("NewUploadHandler", func() {
+
+	const helloSHA256 = "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+
+	var (
+		dir  string
+		opts httpserver.UploadOptions
+	)
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+		opts = httpserver.UploadOptions{}
+	})
+
+	serve := func(req *http.Request) *httptest.ResponseRecorder {
+		return serveRequest(httpserver.NewUploadHandler(dir, opts), req)
+	}
+
+	put := func(path, body string) *httptest.ResponseRecorder {
+		return serve(newBodyRequest("PUT", path, strings.NewReader(body)))
+	}
+
+	readFile := func(name string) string {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		Expect(err).NotTo(HaveOccurred())
+		return string(data)
+	}
+
+	It("saves the body of PUT", func() {
+		recorder := put("/docs/hello.txt", "hello")
+		Expect(recorder.Code).To(Equal(http.StatusCreated))
+		Expect(recorder.Header().Get("Location")).To(Equal("hello.txt"))
+		Expect(recorder.Header().Get("X-Checksum-Sha256")).To(Equal(helloSHA256))
+		Expect(recorder.Header().Get("Repr-Digest")).To(Equal("sha-256=:LPJNul+wow4m6DsqxbninhsWHlwfp0JecwQzYpOLmCQ=:"))
+		Expect(readFile("docs/hello.txt")).To(Equal("hello"))
+	})
+
+	It("saves the files of a multipart POST", func() {
+		var buf bytes.Buffer
+		mw := multipart.NewWriter(&buf)
+		fw, _ := mw.CreateFormFile("file", "a.txt")
+		fw.Write([]byte("hello"))
+		fw, _ = mw.CreateFormFile("file", "../../b.txt")
+		fw.Write([]byte("world"))
+		mw.WriteField("comment", "ignored")
+		mw.Close()
+
+		recorder := serve(newBodyRequest("POST", "/in/", &buf, "Content-Type", mw.FormDataContentType()))
+
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(recorder.Body.String()).To(MatchJSON(`{"files": [
+			{"name": "/in/a.txt", "size": 5, "sha256": "` + helloSHA256 + `"},
+			{"name": "/in/b.txt", "size": 5, "sha256": "486ea46224d1bb4fb680f34f7c9ad96a8f24ec88be73ea8e5a6c65260e9cb8a7"}
+		]}`))
+		Expect(readFile("in/b.txt")).To(Equal("world"))
+	})
+
+	It("doesn't write outside the directory", func() {
+		put("/../../escape.txt", "hello")
+		Expect(readFile("escape.txt")).To(Equal("hello"))
+	})
+
+	It("responds with 413 when the body is too large", func() {
+		opts.MaxSize = 4
+		Expect(put("/big.txt", "hello").Code).To(Equal(http.StatusRequestEntityTooLarge))
+		Expect(filepath.Join(dir, "big.txt")).NotTo(BeAnExistingFile())
+	})
+
+	DescribeTable("overwrite policy", func(policy httpserver.OverwritePolicy, expected int, name, content string) {
+		opts.Overwrite = policy
+		put("/a.txt", "first")
+
+		Expect(put("/a.txt", "second").Code).To(Equal(expected))
+		Expect(readFile(name)).To(Equal(content))
+	},
+		Entry("deny", httpserver.OverwriteDeny, http.StatusConflict, "a.txt", "first"),
+		Entry("allow", httpserver.OverwriteAllow, http.StatusOK, "a.txt", "second"),
+		Entry("rename", httpserver.OverwriteRename, http.StatusCreated, "a-1.txt", "second"),
+	)
+
+	It("serves the files", func() {
+		put("/a.txt", "hello")
+		Expect(serve(newRequest("GET", "/a.txt")).Body.String()).To(Equal("hello"))
+	})
+
+	It("responds with 405 for other methods", func() {
+		recorder := serve(newRequest("DELETE", "/a.txt"))
+		Expect(recorder.Code).To(Equal(http.StatusMethodNotAllowed))
+		Expect(recorder.Header().Get("Allow")).To(Equal("GET, HEAD, POST, PUT"))
+	})
+})
+
+var _ = Describe("ParseByteSize", func() {
+
+	DescribeTable("examples", func(text string, expected int64) {
+		Expect(httpserver.ParseByteSize(text)).To(Equal(expected))
+	},
+		Entry("bytes", "512", int64(512)),
+		Entry("KiB", "4K", int64(4096)),
+		Entry("MiB", "10MB", int64(10<<20)),
+		Entry("GiB", "1GiB", int64(1<<30)),
+	)
+
+	It("returns an error for an invalid size", func() {
+		_, err := httpserver.ParseByteSize("lots")
+		Expect(err).To(MatchError(`invalid size "lots"`))
+	})
+})
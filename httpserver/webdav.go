@@ -0,0 +1,54 @@
+// Copyright 2026 The Joe-cli Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package httpserver
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/Carbonfrost/joe-cli-http/httpclient"
+	"golang.org/x/net/webdav"
+)
+
+// WebDAVHandlerSpec creates a WebDAV server for the directory in the physical
+// path of the virtual path.  The directory can be mounted by file managers to
+// read and write files.
+func WebDAVHandlerSpec() HandlerSpec {
+	return func(_ context.Context, vp httpclient.VirtualPath) (http.Handler, error) {
+		return NewWebDAVHandler(vp.PhysicalPath, vp.RequestPath), nil
+	}
+}
+
+// NewWebDAVHandler provides a WebDAV server for the directory, which supports
+// PROPFIND, PROPPATCH, MKCOL, COPY, MOVE, DELETE, LOCK, UNLOCK, and PUT.
+// Locks are kept in memory.  The prefix is the request path where the handler
+// is mounted.  GET for a directory responds with the directory listing.
+func NewWebDAVHandler(dir string, prefix string) http.Handler {
+	prefix = strings.TrimSuffix(prefix, "/")
+	dav := &webdav.Handler{
+		Prefix:     prefix,
+		FileSystem: webdav.Dir(dir),
+		LockSystem: webdav.NewMemLS(),
+	}
+	listing := http.StripPrefix(prefix, newFileServerHandler(dir, FileServerOptions{}))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			name, ok := strings.CutPrefix(r.URL.Path, prefix)
+			if ok {
+				info, err := os.Stat(filepath.Join(dir, filepath.FromSlash(path.Clean("/"+name))))
+				if err == nil && info.IsDir() {
+					listing.ServeHTTP(w, r)
+					return
+				}
+			}
+		}
+		dav.ServeHTTP(w, r)
+	})
+}
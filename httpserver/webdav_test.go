@@ -0,0 +1,52 @@
+// Copyright 2026 The Joe-cli Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package httpserver_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+
+	"github.com/Carbonfrost/joe-cli-http/httpserver"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("NewWebDAVHandler", func() {
+
+	var (
+		dir     string
+		handler http.Handler
+	)
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+		handler = httpserver.NewWebDAVHandler(dir, "/dav/")
+	})
+
+	serve := func(method, path string, headers ...string) *httptest.ResponseRecorder {
+		return serveRequest(handler, newRequest(method, path, headers...))
+	}
+
+	It("creates, moves, and lists files", func() {
+		Expect(serve("MKCOL", "/dav/docs").Code).To(Equal(http.StatusCreated))
+		Expect(os.WriteFile(filepath.Join(dir, "a.txt"), []byte("hello"), 0600)).To(Succeed())
+
+		Expect(serve("MOVE", "/dav/a.txt", "Destination", "http://example.com/dav/docs/b.txt").Code).
+			To(Equal(http.StatusCreated))
+		Expect(filepath.Join(dir, "docs", "b.txt")).To(BeAnExistingFile())
+
+		recorder := serve("PROPFIND", "/dav/docs/", "Depth", "1")
+		Expect(recorder.Code).To(Equal(http.StatusMultiStatus))
+		Expect(recorder.Body.String()).To(ContainSubstring("<D:href>/dav/docs/b.txt</D:href>"))
+	})
+
+	It("serves the directory listing", func() {
+		Expect(os.WriteFile(filepath.Join(dir, "a.txt"), []byte("hello"), 0600)).To(Succeed())
+		Expect(serve("GET", "/dav/").Body.String()).To(ContainSubstring(`<a href="a.txt">`))
+	})
+})
@@ -1,10 +1,12 @@
-// Copyright 2025 The Joe-cli Authors. All rights reserved.
+// Copyright 2025, 2026 The Joe-cli Authors. All rights reserved.
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
 package weave
 
 import (
+	"strings"
+
 	"github.com/Carbonfrost/joe-cli"
 	"github.com/Carbonfrost/joe-cli-http/httpserver"
 	"github.com/Carbonfrost/joe-cli-http/internal/build"
@@ -27,6 +29,10 @@ func NewApp() *cli.App {
 			cli.Sorted,
 		),
 		Version: build.Version,
+		// The server runs from the action pipeline of RunServer; this
+		// prevents the help screen being the default action because of the
+		// sub-commands
+		Action: cli.ActionOf(func() {}),
 		Flags: []*cli.Flag{
 			{
 				Name:     "chdir",
@@ -40,6 +46,9 @@ func NewApp() *cli.App {
 			{
 				Uses: httpserver.ListHandlers(),
 			},
+			{
+				Uses: httpserver.SetConfig(),
+			},
 			{
 				Name: "help",
 				Uses: cli.Pipeline(
@@ -48,13 +57,49 @@ func NewApp() *cli.App {
 				),
 			},
 		},
+		Commands: []*cli.Command{
+			{
+				Name:     "tls",
+				HelpText: "Export the certificate of the local CA used by --tls auto (PEM format)",
+				Flags: []*cli.Flag{
+					{Uses: httpserver.PrintLocalCAPath()},
+				},
+				Action: httpserver.ExportLocalCA(),
+			},
+		},
 		Args: []*cli.Arg{
 			{
 				Name:     "directories",
 				HelpText: "Specifies static directories to serve",
-				NArg:     cli.TakeUntilNextFlag,
+				NArg:     new(directoriesCounter),
 				Uses:     httpserver.SetFileServerHandler(),
 			},
 		},
 	}
 }
+
+// directoriesCounter takes directories until the next flag.  A leading
+// argument which names a command is left for the command instead.
+type directoriesCounter struct {
+	count int
+}
+
+func (d *directoriesCounter) Take(arg string, possibleFlag bool) error {
+	if possibleFlag && strings.HasPrefix(arg, "-") {
+		return cli.EndOfArguments
+	}
+	if d.count == 0 && arg == "tls" {
+		return cli.EndOfArguments
+	}
+	d.count++
+	return nil
+}
+
+func (d *directoriesCounter) Done() error {
+	d.count = 0
+	return nil
+}
+
+func (*directoriesCounter) Usage() (optional, multi bool) {
+	return true, true
+}
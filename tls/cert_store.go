@@ -0,0 +1,344 @@
+// Copyright 2026 The Joe-cli Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tls
+
+import (
+	"context"
+	gotls "crypto/tls"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+const certReloadDebounce = 100 * time.Millisecond
+
+// KeyPair identifies the files that contain a certificate and its private key
+type KeyPair struct {
+	// CertFile is the file that contains the certificate
+	CertFile string
+
+	// KeyFile is the file that contains the private key.  When empty, the key
+	// is expected to be in CertFile.
+	KeyFile string
+
+	// CertType specifies the format of CertFile
+	CertType CertType
+
+	// Password is used to decrypt the private key if it is encrypted
+	Password PasswordFunc
+}
+
+// CertificateStore provides certificates loaded from files to a server.
+// The files are loaded again when they change or when Reload is called, which
+// allows certificates to be rotated without restarting the server.  When the
+// store contains several certificates, the one to use is selected by the
+// server name (SNI) that the client requests.  The first certificate is used
+// when none of them match.
+type CertificateStore struct {
+	pairs    []KeyPair
+	dirs     []string
+	password PasswordFunc
+
+	mu       sync.RWMutex
+	certs    []*gotls.Certificate
+	modTimes map[string]time.Time
+}
+
+// NewCertificateStore creates a certificate store.  The password is used for
+// any key pair that does not specify its own.
+func NewCertificateStore(password PasswordFunc) *CertificateStore {
+	return &CertificateStore{
+		password: password,
+	}
+}
+
+// AddKeyPair adds a certificate and key pair to the store.  It is loaded
+// on the next call to Reload.
+func (s *CertificateStore) AddKeyPair(p KeyPair) {
+	s.pairs = append(s.pairs, p)
+}
+
+// AddDirectory adds the certificates within a directory to the store.  They
+// are loaded on the next call to Reload, which is also when the directory is
+// listed.  Certificate files use the extensions .crt, .cert, or .pem, and
+// the private key is expected in a file with the same name and the
+// extension .key, or the suffix -key.pem.  When no such file exists, the key
+// must be in the certificate file.  Files with the extensions .p12 and
+// .pfx are loaded as PKCS#12 bundles.
+func (s *CertificateStore) AddDirectory(dir string) {
+	s.dirs = append(s.dirs, dir)
+}
+
+// Reload loads the certificates from their files.  If any of them can't be
+// loaded, an error is returned and the certificates previously loaded
+// remain in use.
+func (s *CertificateStore) Reload() error {
+	pairs, err := s.keyPairs()
+	if err != nil {
+		return err
+	}
+	if len(pairs) == 0 {
+		return errors.New("no certificates found")
+	}
+
+	modTimes := s.statFiles(pairs)
+	certs := make([]*gotls.Certificate, 0, len(pairs))
+	for _, p := range pairs {
+		password := p.Password
+		if password == nil {
+			password = s.password
+		}
+		cert, err := LoadX509KeyPair(p.CertFile, p.KeyFile, p.CertType, password)
+		if err != nil {
+			return fmt.Errorf("loading certificate %s: %w", p.CertFile, err)
+		}
+		certs = append(certs, &cert)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.certs = certs
+	s.modTimes = modTimes
+	return nil
+}
+
+// Changed determines whether any of the files or directories used by the
+// store have changed since the certificates were last loaded.  A directory
+// that can't be read is not considered to have changed because the
+// certificates couldn't be loaded from it.
+func (s *CertificateStore) Changed() bool {
+	pairs, err := s.keyPairs()
+	if err != nil {
+		return false
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return !mapsEqual(s.statFiles(pairs), s.modTimes)
+}
+
+// Watch watches the files used by the store and reloads the certificates
+// when they change until the context is done.  The directories that contain
+// the files are watched so that files which are replaced are detected.
+// Changes that occur close together, such as writing the certificate and
+// then its key, cause one reload.  Errors from reloading are passed to the
+// error function if it is non-nil.  The files are being watched when Watch
+// returns, and reloading happens in the background.
+func (s *CertificateStore) Watch(ctx context.Context, onError func(error)) error {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	for _, dir := range s.watchDirs() {
+		if err := w.Add(dir); err != nil {
+			w.Close()
+			return err
+		}
+	}
+
+	go func() {
+		defer w.Close()
+
+		timer := time.NewTimer(certReloadDebounce)
+		timer.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+
+			case e, ok := <-w.Events:
+				if !ok {
+					return
+				}
+				if !e.Has(fsnotify.Chmod) {
+					timer.Reset(certReloadDebounce)
+				}
+
+			case <-timer.C:
+				if !s.Changed() {
+					continue
+				}
+				if err := s.Reload(); err != nil && onError != nil {
+					onError(err)
+				}
+
+			case err, ok := <-w.Errors:
+				if !ok {
+					return
+				}
+				if onError != nil {
+					onError(err)
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// Certificates gets the certificates that were loaded
+func (s *CertificateStore) Certificates() []*gotls.Certificate {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return slices.Clone(s.certs)
+}
+
+// GetCertificate selects the certificate for the client hello.  It is
+// suitable for gotls.Config.GetCertificate.
+func (s *CertificateStore) GetCertificate(hello *gotls.ClientHelloInfo) (*gotls.Certificate, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if len(s.certs) == 0 {
+		return nil, errors.New("no certificates loaded")
+	}
+	if len(s.certs) == 1 || hello.ServerName == "" {
+		return s.certs[0], nil
+	}
+	for _, cert := range s.certs {
+		if hello.SupportsCertificate(cert) == nil {
+			return cert, nil
+		}
+	}
+	return s.certs[0], nil
+}
+
+func (s *CertificateStore) keyPairs() ([]KeyPair, error) {
+	pairs := slices.Clone(s.pairs)
+	for _, dir := range s.dirs {
+		found, err := keyPairsInDirectory(dir)
+		if err != nil {
+			return nil, err
+		}
+		pairs = append(pairs, found...)
+	}
+	return pairs, nil
+}
+
+// watchDirs gets the directories which contain the files used by the store
+func (s *CertificateStore) watchDirs() []string {
+	dirs := slices.Clone(s.dirs)
+	for _, p := range s.pairs {
+		dirs = append(dirs, filepath.Dir(p.CertFile))
+		if p.KeyFile != "" {
+			dirs = append(dirs, filepath.Dir(p.KeyFile))
+		}
+	}
+	slices.Sort(dirs)
+	return slices.Compact(dirs)
+}
+
+func (s *CertificateStore) statFiles(pairs []KeyPair) map[string]time.Time {
+	res := map[string]time.Time{}
+	stat := func(name string) {
+		if name == "" {
+			return
+		}
+		if info, err := os.Stat(name); err == nil {
+			res[name] = info.ModTime()
+		}
+	}
+	for _, dir := range s.dirs {
+		stat(dir)
+	}
+	for _, p := range pairs {
+		stat(p.CertFile)
+		stat(p.KeyFile)
+	}
+	return res
+}
+
+func keyPairsInDirectory(dir string) ([]KeyPair, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var pairs []KeyPair
+	for _, e := range entries {
+		if e.IsDir() || isKeyFile(e.Name()) {
+			continue
+		}
+		name := filepath.Join(dir, e.Name())
+		ext := strings.ToLower(filepath.Ext(name))
+
+		switch ext {
+		case ".p12", ".pfx":
+			pairs = append(pairs, KeyPair{CertFile: name, CertType: CertTypeP12})
+		case ".crt", ".cert", ".pem":
+			pairs = append(pairs, KeyPair{CertFile: name, KeyFile: findKeyFile(name, ext)})
+		}
+	}
+	return pairs, nil
+}
+
+func isKeyFile(name string) bool {
+	name = strings.ToLower(name)
+	return strings.HasSuffix(name, ".key") || strings.HasSuffix(name, "-key.pem")
+}
+
+func findKeyFile(certFile, ext string) string {
+	base := strings.TrimSuffix(certFile, ext)
+	for _, candidate := range []string{base + ".key", base + "-key.pem"} {
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate
+		}
+	}
+	return ""
+}
+
+func mapsEqual(a, b map[string]time.Time) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if w, ok := b[k]; !ok || !w.Equal(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// Set sets the key pair from the certificate file and the key file
+// separated by a comma.  The key file is optional.  The certificate type
+// is P12 when the certificate file uses the extension .p12 or .pfx.
+func (p *KeyPair) Set(arg string) error {
+	cert, key, _ := strings.Cut(arg, ",")
+	cert = strings.TrimSpace(cert)
+	if cert == "" {
+		return fmt.Errorf("certificate file is required")
+	}
+	p.CertFile = cert
+	p.KeyFile = strings.TrimSpace(key)
+
+	switch strings.ToLower(filepath.Ext(cert)) {
+	case ".p12", ".pfx":
+		p.CertType = CertTypeP12
+	default:
+		p.CertType = CertTypePEM
+	}
+	return nil
+}
+
+func (p KeyPair) String() string {
+	if p.KeyFile == "" {
+		return p.CertFile
+	}
+	return p.CertFile + "," + p.KeyFile
+}
+
+func (*KeyPair) Synopsis() string {
+	return "CERT[,KEY]"
+}
+
+var _ flag.Value = (*KeyPair)(nil)
@@ -0,0 +1,159 @@
+// Copyright 2026 The Joe-cli Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tls_test
+
+import (
+	"context"
+	gotls "crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Carbonfrost/joe-cli-http/tls"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CertificateStore", func() {
+
+	var dir string
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+	})
+
+	serverName := func(cert *gotls.Certificate) string {
+		return cert.Leaf.Subject.CommonName
+	}
+
+	DescribeTable("selects the certificate by server name", func(name string, expected string) {
+		s := tls.NewCertificateStore(nil)
+		s.AddKeyPair(writeTestKeyPair(dir, "a.example"))
+		s.AddKeyPair(writeTestKeyPair(dir, "b.example"))
+		Expect(s.Reload()).To(Succeed())
+
+		cert, err := s.GetCertificate(&gotls.ClientHelloInfo{
+			ServerName:        name,
+			SignatureSchemes:  []gotls.SignatureScheme{gotls.ECDSAWithP256AndSHA256},
+			SupportedVersions: []uint16{gotls.VersionTLS13},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(serverName(cert)).To(Equal(expected))
+	},
+		Entry("first", "a.example", "a.example"),
+		Entry("second", "b.example", "b.example"),
+		Entry("unknown uses first", "c.example", "a.example"),
+		Entry("no server name uses first", "", "a.example"),
+	)
+
+	It("loads the certificates in a directory", func() {
+		writeTestKeyPair(dir, "a.example")
+		writeTestKeyPair(dir, "b.example")
+
+		s := tls.NewCertificateStore(nil)
+		s.AddDirectory(dir)
+		Expect(s.Reload()).To(Succeed())
+		Expect(s.Certificates()).To(HaveLen(2))
+	})
+
+	It("detects when files change", func() {
+		s := tls.NewCertificateStore(nil)
+		s.AddDirectory(dir)
+		writeTestKeyPair(dir, "a.example")
+		Expect(s.Reload()).To(Succeed())
+		Expect(s.Changed()).To(BeFalse())
+
+		p := writeTestKeyPair(dir, "a.example")
+		later := time.Now().Add(time.Minute)
+		Expect(os.Chtimes(p.CertFile, later, later)).To(Succeed())
+		Expect(s.Changed()).To(BeTrue())
+	})
+
+	It("reloads the certificates when watching", func() {
+		p := writeTestKeyPair(dir, "a.example")
+		s := tls.NewCertificateStore(nil)
+		s.AddKeyPair(p)
+		Expect(s.Reload()).To(Succeed())
+		before := s.Certificates()[0]
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		Expect(s.Watch(ctx, nil)).To(Succeed())
+
+		writeTestKeyPair(dir, "a.example")
+		later := time.Now().Add(time.Minute)
+		Expect(os.Chtimes(p.CertFile, later, later)).To(Succeed())
+		Eventually(func() *gotls.Certificate {
+			return s.Certificates()[0]
+		}).ShouldNot(BeIdenticalTo(before))
+	})
+
+	It("reloads the certificates when a directory changes", func() {
+		writeTestKeyPair(dir, "a.example")
+		s := tls.NewCertificateStore(nil)
+		s.AddDirectory(dir)
+		Expect(s.Reload()).To(Succeed())
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		Expect(s.Watch(ctx, nil)).To(Succeed())
+
+		writeTestKeyPair(dir, "b.example")
+		Eventually(s.Certificates).Should(HaveLen(2))
+	})
+
+	It("doesn't consider a directory that can't be read as changed", func() {
+		sub := filepath.Join(dir, "certs")
+		Expect(os.Mkdir(sub, 0o755)).To(Succeed())
+		writeTestKeyPair(sub, "a.example")
+
+		s := tls.NewCertificateStore(nil)
+		s.AddDirectory(sub)
+		Expect(s.Reload()).To(Succeed())
+
+		Expect(os.RemoveAll(sub)).To(Succeed())
+		Expect(s.Changed()).To(BeFalse())
+	})
+
+	It("keeps the previous certificates when reloading fails", func() {
+		p := writeTestKeyPair(dir, "a.example")
+		s := tls.NewCertificateStore(nil)
+		s.AddKeyPair(p)
+		Expect(s.Reload()).To(Succeed())
+
+		Expect(os.WriteFile(p.KeyFile, []byte("corrupt"), 0o600)).To(Succeed())
+		Expect(s.Reload()).To(HaveOccurred())
+		Expect(s.Certificates()).To(HaveLen(1))
+	})
+
+	DescribeTable("KeyPair.Set", func(arg string, expected tls.KeyPair) {
+		var p tls.KeyPair
+		Expect(p.Set(arg)).To(Succeed())
+		Expect(p).To(Equal(expected))
+	},
+		Entry("cert and key", "a.pem,a-key.pem", tls.KeyPair{CertFile: "a.pem", KeyFile: "a-key.pem"}),
+		Entry("combined", "a.pem", tls.KeyPair{CertFile: "a.pem"}),
+		Entry("P12", "a.p12", tls.KeyPair{CertFile: "a.p12", CertType: tls.CertTypeP12}),
+	)
+})
+
+func writeTestKeyPair(dir, name string) tls.KeyPair {
+	_, cert := newTestCertificates(name)
+	keyDER, err := x509.MarshalPKCS8PrivateKey(cert.PrivateKey)
+	Expect(err).NotTo(HaveOccurred())
+
+	p := tls.KeyPair{
+		CertFile: filepath.Join(dir, name+".crt"),
+		KeyFile:  filepath.Join(dir, name+".key"),
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Certificate[0]})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+	Expect(os.WriteFile(p.CertFile, certPEM, 0o644)).To(Succeed())
+	Expect(os.WriteFile(p.KeyFile, keyPEM, 0o600)).To(Succeed())
+	return p
+}
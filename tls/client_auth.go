@@ -0,0 +1,73 @@
+// Copyright 2026 The Joe-cli Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tls
+
+import (
+	gotls "crypto/tls"
+	"crypto/x509"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ClientAuthType is the policy a server uses for client certificates
+type ClientAuthType gotls.ClientAuthType
+
+var clientAuthNames = map[ClientAuthType]string{
+	ClientAuthType(gotls.NoClientCert):               "none",
+	ClientAuthType(gotls.RequestClientCert):          "request",
+	ClientAuthType(gotls.RequireAnyClientCert):       "require-any",
+	ClientAuthType(gotls.VerifyClientCertIfGiven):    "verify-if-given",
+	ClientAuthType(gotls.RequireAndVerifyClientCert): "require",
+}
+
+// WithClientAuth sets the policy for client certificates when serving
+func WithClientAuth(t ClientAuthType) Option {
+	return func(c *Config) error {
+		c.ClientAuth = gotls.ClientAuthType(t)
+		return nil
+	}
+}
+
+// AddClientCACertFile adds a CA certificate to the pool used to verify
+// client certificates when serving
+func AddClientCACertFile(filename string) Option {
+	return func(c *Config) error {
+		cert, err := os.ReadFile(filename)
+		if err != nil {
+			return err
+		}
+		if c.ClientCAs == nil {
+			c.ClientCAs = x509.NewCertPool()
+		}
+		if !c.ClientCAs.AppendCertsFromPEM(cert) {
+			return fmt.Errorf("no certificates found in %q", filename)
+		}
+		return nil
+	}
+}
+
+func (t ClientAuthType) String() string {
+	return clientAuthNames[t]
+}
+
+// Set sets the client authentication policy from its name
+func (t *ClientAuthType) Set(arg string) error {
+	name := strings.TrimSpace(arg)
+	for k, v := range clientAuthNames {
+		if v == name {
+			*t = k
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown client auth type %q", arg)
+}
+
+func (*ClientAuthType) Synopsis() string {
+	return "{none|request|require-any|verify-if-given|require}"
+}
+
+var _ flag.Value = (*ClientAuthType)(nil)
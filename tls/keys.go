@@ -0,0 +1,343 @@
+// Copyright 2026 The Joe-cli Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tls
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des"
+	"crypto/pbkdf2"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	gotls "crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"flag"
+	"fmt"
+	"hash"
+	"os"
+	"strings"
+
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+// CertType specifies the format of a certificate file
+type CertType int
+
+// PasswordFunc obtains the password used to decrypt a private key.  It is
+// only called when the key is encrypted.
+type PasswordFunc func() (string, error)
+
+// Certificate types
+const (
+	// CertTypePEM is a PEM certificate and private key, which are in separate
+	// files or concatenated in the same file.  The key can be encrypted with PKCS#8.
+	CertTypePEM CertType = iota
+
+	// CertTypeP12 is a PKCS#12 bundle containing the certificate, its chain,
+	// and the private key
+	CertTypeP12
+	maxCertType
+)
+
+var (
+	// ErrIncorrectPassword occurs when the password used to decrypt a key is wrong
+	ErrIncorrectPassword = errors.New("incorrect password or corrupt private key")
+
+	// ErrPasswordRequired occurs when the key is encrypted and no password was provided
+	ErrPasswordRequired = errors.New("private key is encrypted and requires a password")
+
+	certTypeStrings = [...]string{
+		"PEM",
+		"P12",
+	}
+
+	oidPBES2      = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 13}
+	oidPBKDF2     = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 12}
+	oidHMACSHA1   = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 7}
+	oidHMACSHA224 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 8}
+	oidHMACSHA256 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 9}
+	oidHMACSHA384 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 10}
+	oidHMACSHA512 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 11}
+	oidAES128CBC  = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 2}
+	oidAES192CBC  = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 22}
+	oidAES256CBC  = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42}
+	oidDESEDE3CBC = asn1.ObjectIdentifier{1, 2, 840, 113549, 3, 7}
+)
+
+type encryptedPrivateKeyInfo struct {
+	Algorithm     pkix.AlgorithmIdentifier
+	EncryptedData []byte
+}
+
+type pbes2Params struct {
+	KeyDerivationFunc pkix.AlgorithmIdentifier
+	EncryptionScheme  pkix.AlgorithmIdentifier
+}
+
+type pbkdf2Params struct {
+	Salt           []byte
+	IterationCount int
+	KeyLength      int                      `asn1:"optional"`
+	PRF            pkix.AlgorithmIdentifier `asn1:"optional"`
+}
+
+// LoadX509KeyPair reads a certificate and its private key.  For PEM, the
+// key file can be empty when the key is contained in the certificate file.
+// For PKCS#12, the key file is not used.  The password function is called
+// when the private key is encrypted, and it can be nil when no password
+// is available.
+func LoadX509KeyPair(certFile, keyFile string, certType CertType, password PasswordFunc) (gotls.Certificate, error) {
+	if password == nil {
+		password = func() (string, error) {
+			return "", ErrPasswordRequired
+		}
+	}
+
+	certData, err := os.ReadFile(certFile)
+	if err != nil {
+		return gotls.Certificate{}, err
+	}
+
+	switch certType {
+	case CertTypeP12:
+		return parsePKCS12(certData, password)
+
+	case CertTypePEM:
+		keyData := certData
+		if keyFile != "" {
+			keyData, err = os.ReadFile(keyFile)
+			if err != nil {
+				return gotls.Certificate{}, err
+			}
+		} else if !containsPrivateKey(certData) {
+			return gotls.Certificate{}, fmt.Errorf("%s does not contain a private key; the key file must be specified", certFile)
+		}
+		keyData, err = decryptPEMKey(keyData, password)
+		if err != nil {
+			return gotls.Certificate{}, err
+		}
+		return gotls.X509KeyPair(certData, keyData)
+
+	default:
+		return gotls.Certificate{}, fmt.Errorf("unsupported certificate type %d", certType)
+	}
+}
+
+// DecryptPKCS8PrivateKey decrypts the DER-encoded contents of an
+// ENCRYPTED PRIVATE KEY PEM block.  PBES2 is supported using PBKDF2 and AES
+// or 3DES in CBC mode.  The result is a DER-encoded PKCS#8 private key.
+func DecryptPKCS8PrivateKey(der []byte, password string) ([]byte, error) {
+	var info encryptedPrivateKeyInfo
+	if _, err := asn1.Unmarshal(der, &info); err != nil {
+		return nil, fmt.Errorf("invalid encrypted private key: %w", err)
+	}
+	if !info.Algorithm.Algorithm.Equal(oidPBES2) {
+		return nil, fmt.Errorf("unsupported private key encryption algorithm %v", info.Algorithm.Algorithm)
+	}
+
+	var params pbes2Params
+	if _, err := asn1.Unmarshal(info.Algorithm.Parameters.FullBytes, &params); err != nil {
+		return nil, fmt.Errorf("invalid PBES2 parameters: %w", err)
+	}
+	if !params.KeyDerivationFunc.Algorithm.Equal(oidPBKDF2) {
+		return nil, fmt.Errorf("unsupported key derivation function %v", params.KeyDerivationFunc.Algorithm)
+	}
+
+	var kdf pbkdf2Params
+	if _, err := asn1.Unmarshal(params.KeyDerivationFunc.Parameters.FullBytes, &kdf); err != nil {
+		return nil, fmt.Errorf("invalid PBKDF2 parameters: %w", err)
+	}
+	prf, err := pbkdf2Hash(kdf.PRF.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	newCipher, keyLen, err := pbes2Cipher(params.EncryptionScheme.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+	var iv []byte
+	if _, err := asn1.Unmarshal(params.EncryptionScheme.Parameters.FullBytes, &iv); err != nil {
+		return nil, fmt.Errorf("invalid encryption scheme parameters: %w", err)
+	}
+
+	key, err := pbkdf2.Key(prf, password, kdf.Salt, kdf.IterationCount, keyLen)
+	if err != nil {
+		return nil, err
+	}
+	block, err := newCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	data := info.EncryptedData
+	if len(iv) != block.BlockSize() || len(data) == 0 || len(data)%block.BlockSize() != 0 {
+		return nil, ErrIncorrectPassword
+	}
+
+	plain := make([]byte, len(data))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(plain, data)
+	plain, ok := unpad(plain, block.BlockSize())
+	if !ok {
+		return nil, ErrIncorrectPassword
+	}
+	if _, err := x509.ParsePKCS8PrivateKey(plain); err != nil {
+		return nil, ErrIncorrectPassword
+	}
+	return plain, nil
+}
+
+func parsePKCS12(data []byte, password PasswordFunc) (gotls.Certificate, error) {
+	// Bundles are commonly protected with an empty password, so try it first
+	key, cert, chain, err := pkcs12.DecodeChain(data, "")
+	if errors.Is(err, pkcs12.ErrIncorrectPassword) {
+		var pass string
+		pass, err = password()
+		if err != nil {
+			return gotls.Certificate{}, err
+		}
+		key, cert, chain, err = pkcs12.DecodeChain(data, pass)
+	}
+	if errors.Is(err, pkcs12.ErrIncorrectPassword) {
+		return gotls.Certificate{}, ErrIncorrectPassword
+	}
+	if err != nil {
+		return gotls.Certificate{}, err
+	}
+
+	res := gotls.Certificate{
+		Certificate: [][]byte{cert.Raw},
+		PrivateKey:  key,
+		Leaf:        cert,
+	}
+	for _, c := range chain {
+		res.Certificate = append(res.Certificate, c.Raw)
+	}
+	return res, nil
+}
+
+// decryptPEMKey replaces the first encrypted PKCS#8 key in the PEM data
+// with its decrypted form
+func decryptPEMKey(data []byte, password PasswordFunc) ([]byte, error) {
+	rest := data
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			return data, nil
+		}
+
+		if strings.Contains(block.Headers["Proc-Type"], "ENCRYPTED") {
+			return nil, fmt.Errorf("legacy encrypted PEM keys are not supported; convert the key to PKCS#8")
+		}
+		if block.Type != "ENCRYPTED PRIVATE KEY" {
+			continue
+		}
+
+		pass, err := password()
+		if err != nil {
+			return nil, err
+		}
+		der, err := DecryptPKCS8PrivateKey(block.Bytes, pass)
+		if err != nil {
+			return nil, err
+		}
+		return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+	}
+}
+
+func pbkdf2Hash(oid asn1.ObjectIdentifier) (func() hash.Hash, error) {
+	switch {
+	case len(oid) == 0, oid.Equal(oidHMACSHA1):
+		return sha1.New, nil
+	case oid.Equal(oidHMACSHA224):
+		return sha256.New224, nil
+	case oid.Equal(oidHMACSHA256):
+		return sha256.New, nil
+	case oid.Equal(oidHMACSHA384):
+		return sha512.New384, nil
+	case oid.Equal(oidHMACSHA512):
+		return sha512.New, nil
+	}
+	return nil, fmt.Errorf("unsupported PBKDF2 hash function %v", oid)
+}
+
+func pbes2Cipher(oid asn1.ObjectIdentifier) (func([]byte) (cipher.Block, error), int, error) {
+	switch {
+	case oid.Equal(oidAES128CBC):
+		return aes.NewCipher, 16, nil
+	case oid.Equal(oidAES192CBC):
+		return aes.NewCipher, 24, nil
+	case oid.Equal(oidAES256CBC):
+		return aes.NewCipher, 32, nil
+	case oid.Equal(oidDESEDE3CBC):
+		return des.NewTripleDESCipher, 24, nil
+	}
+	return nil, 0, fmt.Errorf("unsupported encryption scheme %v", oid)
+}
+
+func containsPrivateKey(data []byte) bool {
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return false
+		}
+		if strings.HasSuffix(block.Type, "PRIVATE KEY") {
+			return true
+		}
+	}
+}
+
+func unpad(b []byte, blockSize int) ([]byte, bool) {
+	n := int(b[len(b)-1])
+	if n == 0 || n > blockSize || n > len(b) {
+		return nil, false
+	}
+	for _, p := range b[len(b)-n:] {
+		if int(p) != n {
+			return nil, false
+		}
+	}
+	return b[:len(b)-n], true
+}
+
+func (t CertType) String() string {
+	if t >= 0 && t < maxCertType {
+		return certTypeStrings[int(t)]
+	}
+	return ""
+}
+
+// MarshalText provides the textual representation
+func (t CertType) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText converts the textual representation
+func (t *CertType) UnmarshalText(b []byte) error {
+	return t.Set(string(b))
+}
+
+// Set sets the certificate type from its name
+func (t *CertType) Set(arg string) error {
+	for i, s := range certTypeStrings {
+		if strings.EqualFold(strings.TrimSpace(arg), s) {
+			*t = CertType(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown certificate type %q", arg)
+}
+
+func (*CertType) Synopsis() string {
+	return "PEM|P12"
+}
+
+var _ flag.Value = (*CertType)(nil)
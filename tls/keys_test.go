@@ -0,0 +1,97 @@
+// Copyright 2026 The Joe-cli Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tls_test
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/Carbonfrost/joe-cli"
+	"github.com/Carbonfrost/joe-cli-http/tls"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/types"
+)
+
+var _ = Describe("LoadX509KeyPair", func() {
+
+	password := func(s string) tls.PasswordFunc {
+		return func() (string, error) {
+			return s, nil
+		}
+	}
+
+	DescribeTable("examples", func(certFile, keyFile string, certType tls.CertType, pass tls.PasswordFunc) {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile, certType, pass)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cert.Certificate).To(HaveLen(1))
+		Expect(cert.PrivateKey).NotTo(BeNil())
+	},
+		Entry("PEM", "testdata/cert.pem", "testdata/key.pem", tls.CertTypePEM, nil),
+		Entry("PEM combined", "testdata/combined.pem", "", tls.CertTypePEM, nil),
+		Entry("PKCS#8 AES-128 SHA-1", "testdata/cert.pem", "testdata/key-aes128-sha1.pem", tls.CertTypePEM, password("secret")),
+		Entry("PKCS#8 AES-128 SHA-256", "testdata/cert.pem", "testdata/key-aes128.pem", tls.CertTypePEM, password("secret")),
+		Entry("PKCS#8 AES-256 SHA-1", "testdata/cert.pem", "testdata/key-aes256-sha1.pem", tls.CertTypePEM, password("secret")),
+		Entry("PKCS#8 AES-256 SHA-256", "testdata/cert.pem", "testdata/key-aes256.pem", tls.CertTypePEM, password("secret")),
+		Entry("PKCS#8 3DES", "testdata/cert.pem", "testdata/key-3des.pem", tls.CertTypePEM, password("secret")),
+		Entry("P12", "testdata/client.p12", "", tls.CertTypeP12, password("secret")),
+		Entry("P12 without password", "testdata/client-nopass.p12", "", tls.CertTypeP12, nil),
+	)
+
+	DescribeTable("errors", func(certFile, keyFile string, certType tls.CertType, pass tls.PasswordFunc, expected error) {
+		_, err := tls.LoadX509KeyPair(certFile, keyFile, certType, pass)
+		Expect(err).To(MatchError(expected))
+	},
+		Entry("PKCS#8 wrong password", "testdata/cert.pem", "testdata/key-aes256.pem", tls.CertTypePEM, password("wrong"), tls.ErrIncorrectPassword),
+		Entry("PKCS#8 AES-128 wrong password", "testdata/cert.pem", "testdata/key-aes128.pem", tls.CertTypePEM, password("wrong"), tls.ErrIncorrectPassword),
+		Entry("PKCS#8 3DES wrong password", "testdata/cert.pem", "testdata/key-3des.pem", tls.CertTypePEM, password("wrong"), tls.ErrIncorrectPassword),
+		Entry("PKCS#8 no password", "testdata/cert.pem", "testdata/key-aes256.pem", tls.CertTypePEM, nil, tls.ErrPasswordRequired),
+		Entry("P12 wrong password", "testdata/client.p12", "", tls.CertTypeP12, password("wrong"), tls.ErrIncorrectPassword),
+		Entry("P12 no password", "testdata/client.p12", "", tls.CertTypeP12, nil, tls.ErrPasswordRequired),
+		Entry("PEM without key", "testdata/cert.pem", "", tls.CertTypePEM, nil, errors.New("testdata/cert.pem does not contain a private key; the key file must be specified")),
+	)
+
+	It("loads the certificate using flags", func() {
+		config := tls.New()
+		app := &cli.App{
+			Uses:   config,
+			Action: func() {},
+			Stdout: io.Discard,
+		}
+
+		err := app.RunContext(context.Background(), []string{"app", "--cert", "testdata/client.p12", "--cert-type", "P12", "--pass", "secret"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(config.Certificates).To(HaveLen(1))
+	})
+
+	DescribeTable("flags", func(args string, expected types.GomegaMatcher) {
+		app := &cli.App{
+			Uses:   tls.New(),
+			Action: func() {},
+			Stdout: io.Discard,
+		}
+
+		argv, _ := cli.Split("app " + args)
+		err := app.RunContext(context.Background(), argv)
+		Expect(err).To(expected)
+	},
+		Entry("pass before cert", "--pass secret --cert testdata/cert.pem --key testdata/key-aes256.pem", Succeed()),
+		Entry("pass after cert", "--cert testdata/cert.pem --key testdata/key-aes256.pem --pass secret", Succeed()),
+		Entry("no pass", "--cert testdata/cert.pem --key testdata/key-aes256.pem", MatchError(tls.ErrPasswordRequired)),
+		Entry("cert without key", "--cert testdata/cert.pem", MatchError(ContainSubstring("does not contain a private key"))),
+	)
+
+	DescribeTable("CertType.Set", func(arg string, expected tls.CertType) {
+		var t tls.CertType
+		Expect(t.Set(arg)).To(Succeed())
+		Expect(t).To(Equal(expected))
+	},
+		Entry("PEM", "PEM", tls.CertTypePEM),
+		Entry("P12", "P12", tls.CertTypeP12),
+		Entry("case insensitive", "p12", tls.CertTypeP12),
+	)
+})
@@ -0,0 +1,254 @@
+// Copyright 2026 The Joe-cli Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tls
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	gotls "crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LocalCA is a certificate authority stored in a local directory which
+// issues leaf certificates for local development.  The CA is created the
+// first time it is needed, and it is reused afterwards so that it only
+// needs to be trusted once.
+type LocalCA struct {
+	dir  string
+	cert *x509.Certificate
+	key  crypto.Signer
+
+	mu     sync.Mutex
+	leaves map[string]*gotls.Certificate
+}
+
+const (
+	localCACertFile = "ca.pem"
+	localCAKeyFile  = "ca-key.pem"
+
+	localCAValidity   = 10 * 365 * 24 * time.Hour
+	leafValidity      = 30 * 24 * time.Hour
+	leafRenewalWindow = 24 * time.Hour
+)
+
+// DefaultLocalCADirectory gets the directory where the local CA is stored
+// by default, which is within the user config directory.
+func DefaultLocalCADirectory() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "joe-cli-http", "ca"), nil
+}
+
+// LoadOrCreateLocalCA loads the local CA from the directory, creating it
+// if it doesn't exist.  When dir is empty, the default directory is used.
+func LoadOrCreateLocalCA(dir string) (*LocalCA, error) {
+	if dir == "" {
+		var err error
+		dir, err = DefaultLocalCADirectory()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ca := &LocalCA{
+		dir:    dir,
+		leaves: map[string]*gotls.Certificate{},
+	}
+	err := ca.load()
+	if errors.Is(err, fs.ErrNotExist) {
+		err = ca.create()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ca, nil
+}
+
+// CertFile gets the path to the CA certificate file (PEM format)
+func (ca *LocalCA) CertFile() string {
+	return filepath.Join(ca.dir, localCACertFile)
+}
+
+// Certificate gets the CA certificate
+func (ca *LocalCA) Certificate() *x509.Certificate {
+	return ca.cert
+}
+
+// CertPEM gets the CA certificate in PEM format
+func (ca *LocalCA) CertPEM() []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.cert.Raw})
+}
+
+// Issue creates a leaf certificate for the given host names and IP addresses
+// signed by the CA
+func (ca *LocalCA) Issue(names ...string) (*gotls.Certificate, error) {
+	if len(names) == 0 {
+		return nil, fmt.Errorf("at least one name is required")
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: newSerialNumber(),
+		Subject:      pkix.Name{CommonName: names[0], Organization: []string{"joe-cli-http local development"}},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(leafValidity),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	for _, name := range names {
+		if ip := net.ParseIP(name); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, name)
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		return nil, err
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+	return &gotls.Certificate{
+		Certificate: [][]byte{der, ca.cert.Raw},
+		PrivateKey:  key,
+		Leaf:        leaf,
+	}, nil
+}
+
+// GetCertificateFunc provides a function suitable for gotls.Config.GetCertificate
+// which issues leaf certificates on demand.  The server name from the client
+// hello is used if it is one of the hosts; otherwise, the certificate contains
+// all of the hosts.  Certificates are cached until they are close to expiring.
+func (ca *LocalCA) GetCertificateFunc(hosts ...string) func(*gotls.ClientHelloInfo) (*gotls.Certificate, error) {
+	hosts = slices.Compact(hosts)
+	return func(hello *gotls.ClientHelloInfo) (*gotls.Certificate, error) {
+		names := hosts
+		if name := strings.ToLower(hello.ServerName); slices.Contains(hosts, name) {
+			names = []string{name}
+		}
+		return ca.leaf(names)
+	}
+}
+
+func (ca *LocalCA) leaf(names []string) (*gotls.Certificate, error) {
+	key := strings.Join(names, ",")
+
+	ca.mu.Lock()
+	defer ca.mu.Unlock()
+
+	if cert, ok := ca.leaves[key]; ok && time.Until(cert.Leaf.NotAfter) > leafRenewalWindow {
+		return cert, nil
+	}
+	cert, err := ca.Issue(names...)
+	if err != nil {
+		return nil, err
+	}
+	ca.leaves[key] = cert
+	return cert, nil
+}
+
+func (ca *LocalCA) load() error {
+	certPEM, err := os.ReadFile(filepath.Join(ca.dir, localCACertFile))
+	if err != nil {
+		return err
+	}
+	keyPEM, err := os.ReadFile(filepath.Join(ca.dir, localCAKeyFile))
+	if err != nil {
+		return err
+	}
+
+	pair, err := gotls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return fmt.Errorf("loading local CA from %s: %w", ca.dir, err)
+	}
+	signer, ok := pair.PrivateKey.(crypto.Signer)
+	if !ok {
+		return fmt.Errorf("loading local CA from %s: unsupported private key", ca.dir)
+	}
+	ca.cert = pair.Leaf
+	ca.key = signer
+	return nil
+}
+
+func (ca *LocalCA) create() error {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	hostname, _ := os.Hostname()
+	template := &x509.Certificate{
+		SerialNumber: newSerialNumber(),
+		Subject: pkix.Name{
+			CommonName:   "joe-cli-http local CA " + hostname,
+			Organization: []string{"joe-cli-http local development"},
+		},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(localCAValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLenZero:        true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return err
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(ca.dir, 0o700); err != nil {
+		return err
+	}
+	err = os.WriteFile(
+		filepath.Join(ca.dir, localCAKeyFile),
+		pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}),
+		0o600,
+	)
+	if err != nil {
+		return err
+	}
+	err = os.WriteFile(
+		filepath.Join(ca.dir, localCACertFile),
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		0o644,
+	)
+	if err != nil {
+		return err
+	}
+	return ca.load()
+}
+
+func newSerialNumber() *big.Int {
+	n, _ := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	return n
+}
@@ -0,0 +1,78 @@
+// Copyright 2026 The Joe-cli Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tls_test
+
+import (
+	gotls "crypto/tls"
+	"crypto/x509"
+	"path/filepath"
+
+	"github.com/Carbonfrost/joe-cli-http/tls"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("LocalCA", func() {
+
+	var dir string
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+	})
+
+	It("creates the CA in the directory", func() {
+		ca, err := tls.LoadOrCreateLocalCA(dir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ca.CertFile()).To(Equal(filepath.Join(dir, "ca.pem")))
+		Expect(ca.Certificate().IsCA).To(BeTrue())
+		Expect(filepath.Join(dir, "ca-key.pem")).To(BeARegularFile())
+	})
+
+	It("reuses the CA on subsequent loads", func() {
+		ca, _ := tls.LoadOrCreateLocalCA(dir)
+		again, err := tls.LoadOrCreateLocalCA(dir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(again.CertPEM()).To(Equal(ca.CertPEM()))
+	})
+
+	It("issues certificates that verify against the CA", func() {
+		ca, _ := tls.LoadOrCreateLocalCA(dir)
+		cert, err := ca.Issue("elvis.localhost", "127.0.0.1")
+		Expect(err).NotTo(HaveOccurred())
+
+		roots := x509.NewCertPool()
+		roots.AddCert(ca.Certificate())
+		for _, name := range []string{"elvis.localhost", "127.0.0.1"} {
+			_, err = cert.Leaf.Verify(x509.VerifyOptions{DNSName: name, Roots: roots})
+			Expect(err).NotTo(HaveOccurred())
+		}
+	})
+
+	Describe("GetCertificateFunc", func() {
+
+		DescribeTable("examples", func(serverName string, expected []string) {
+			ca, _ := tls.LoadOrCreateLocalCA(dir)
+			get := ca.GetCertificateFunc("elvis.localhost", "localhost", "127.0.0.1")
+
+			cert, err := get(&gotls.ClientHelloInfo{ServerName: serverName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cert.Leaf.DNSNames).To(Equal(expected))
+		},
+			Entry("server name in hosts", "localhost", []string{"localhost"}),
+			Entry("no server name", "", []string{"elvis.localhost", "localhost"}),
+			Entry("unknown server name", "other.example", []string{"elvis.localhost", "localhost"}),
+		)
+
+		It("caches the certificate", func() {
+			ca, _ := tls.LoadOrCreateLocalCA(dir)
+			get := ca.GetCertificateFunc("localhost")
+
+			first, _ := get(&gotls.ClientHelloInfo{ServerName: "localhost"})
+			second, _ := get(&gotls.ClientHelloInfo{ServerName: "localhost"})
+			Expect(second).To(BeIdenticalTo(first))
+		})
+	})
+})
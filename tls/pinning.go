@@ -0,0 +1,145 @@
+// Copyright 2026 The Joe-cli Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tls
+
+import (
+	"bytes"
+	"crypto/sha256"
+	gotls "crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// PublicKeyPins provides the SHA-256 hashes of the public keys (SubjectPublicKeyInfo)
+// that the peer is allowed to present.  The textual syntax is the same as
+// cURL: sha256//BASE64 with multiple hashes separated by commas or semicolons.
+type PublicKeyPins [][]byte
+
+const pinPrefix = "sha256//"
+
+// ErrPublicKeyPinMismatch occurs when the peer public key doesn't match any pin
+var ErrPublicKeyPinMismatch = errors.New("peer public key does not match any pinned public key")
+
+// WithPinnedPublicKeys requires that the public key of the peer certificate
+// matches one of the pins.  The check is performed in addition to the usual
+// verification of the certificate chain.  When used more than once, the
+// pins are combined.
+func WithPinnedPublicKeys(pins PublicKeyPins) Option {
+	return func(c *Config) error {
+		if len(pins) == 0 {
+			return nil
+		}
+		if len(c.pins) == 0 {
+			next := c.VerifyPeerCertificate
+			c.VerifyPeerCertificate = func(rawCerts [][]byte, chains [][]*x509.Certificate) error {
+				if err := c.pins.verify(rawCerts); err != nil {
+					return err
+				}
+				if next != nil {
+					return next(rawCerts, chains)
+				}
+				return nil
+			}
+		}
+		c.pins = append(c.pins, pins...)
+		return nil
+	}
+}
+
+// WithVerifyHostname verifies the peer certificate against the given name
+// instead of the server name used in the TLS handshake.  This is useful
+// when the certificate is for an internal name but the server is reached
+// by another name.
+func WithVerifyHostname(name string) Option {
+	return func(c *Config) error {
+		c.verifyHostname = name
+
+		// Verification is performed by VerifyConnection instead
+		c.InsecureSkipVerify = true
+		c.VerifyConnection = c.verifyConnection
+		return nil
+	}
+}
+
+func (c *Config) verifyConnection(cs gotls.ConnectionState) error {
+	if c.skipVerify || len(cs.PeerCertificates) == 0 {
+		return nil
+	}
+
+	opts := x509.VerifyOptions{
+		DNSName:       c.verifyHostname,
+		Roots:         c.RootCAs,
+		Intermediates: x509.NewCertPool(),
+	}
+	if c.Time != nil {
+		opts.CurrentTime = c.Time()
+	}
+	for _, cert := range cs.PeerCertificates[1:] {
+		opts.Intermediates.AddCert(cert)
+	}
+	_, err := cs.PeerCertificates[0].Verify(opts)
+	return err
+}
+
+func (p PublicKeyPins) verify(rawCerts [][]byte) error {
+	if len(rawCerts) == 0 {
+		return ErrPublicKeyPinMismatch
+	}
+	cert, err := x509.ParseCertificate(rawCerts[0])
+	if err != nil {
+		return err
+	}
+	actual := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	for _, pin := range p {
+		if bytes.Equal(pin, actual[:]) {
+			return nil
+		}
+	}
+	return ErrPublicKeyPinMismatch
+}
+
+// PublicKeyPin computes the pin of the public key of the certificate
+func PublicKeyPin(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return pinPrefix + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// Set will append the pins to the list
+func (p *PublicKeyPins) Set(arg string) error {
+	fields := strings.FieldsFunc(arg, func(r rune) bool {
+		return r == ',' || r == ';'
+	})
+	for _, f := range fields {
+		f = strings.TrimSpace(f)
+		enc, ok := strings.CutPrefix(f, pinPrefix)
+		if !ok {
+			return fmt.Errorf("invalid public key pin %q: expected sha256//BASE64", f)
+		}
+		b, err := base64.StdEncoding.DecodeString(enc)
+		if err != nil || len(b) != sha256.Size {
+			return fmt.Errorf("invalid public key pin %q: not a base64-encoded SHA-256 hash", f)
+		}
+		*p = append(*p, b)
+	}
+	return nil
+}
+
+func (p PublicKeyPins) String() string {
+	res := make([]string, len(p))
+	for i, pin := range p {
+		res[i] = pinPrefix + base64.StdEncoding.EncodeToString(pin)
+	}
+	return strings.Join(res, ",")
+}
+
+func (*PublicKeyPins) Synopsis() string {
+	return "sha256//BASE64,..."
+}
+
+var _ flag.Value = (*PublicKeyPins)(nil)
@@ -0,0 +1,153 @@
+// Copyright 2026 The Joe-cli Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tls_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	gotls "crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"time"
+
+	"github.com/Carbonfrost/joe-cli-http/tls"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Pinning", func() {
+
+	var (
+		roots *x509.CertPool
+		leaf  gotls.Certificate
+	)
+
+	BeforeEach(func() {
+		roots, leaf = newTestCertificates("internal.example")
+	})
+
+	handshake := func(serverName string, opts ...tls.Option) error {
+		config := tls.New(opts...)
+		config.RootCAs = roots
+		config.ServerName = serverName
+
+		l, err := gotls.Listen("tcp", "127.0.0.1:0", &gotls.Config{Certificates: []gotls.Certificate{leaf}})
+		Expect(err).NotTo(HaveOccurred())
+		defer l.Close()
+
+		go func() {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+			_ = conn.(*gotls.Conn).Handshake()
+		}()
+
+		client, err := net.Dial("tcp", l.Addr().String())
+		Expect(err).NotTo(HaveOccurred())
+		defer client.Close()
+		return gotls.Client(client, config.Config).Handshake()
+	}
+
+	Describe("WithPinnedPublicKeys", func() {
+
+		It("succeeds when the pin matches", func() {
+			var pins tls.PublicKeyPins
+			Expect(pins.Set("sha256//AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=")).To(Succeed())
+			Expect(pins.Set(tls.PublicKeyPin(leaf.Leaf))).To(Succeed())
+
+			err := handshake("internal.example", tls.WithPinnedPublicKeys(pins))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("fails when no pin matches", func() {
+			var pins tls.PublicKeyPins
+			Expect(pins.Set("sha256//AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=")).To(Succeed())
+
+			err := handshake("internal.example", tls.WithPinnedPublicKeys(pins))
+			Expect(err).To(MatchError(tls.ErrPublicKeyPinMismatch))
+		})
+	})
+
+	Describe("WithVerifyHostname", func() {
+
+		It("verifies against the given name instead of the server name", func() {
+			err := handshake("public.example", tls.WithVerifyHostname("internal.example"))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("fails when the certificate doesn't match the name", func() {
+			err := handshake("internal.example", tls.WithVerifyHostname("other.example"))
+			Expect(err).To(MatchError(ContainSubstring("other.example")))
+		})
+
+		It("is skipped when verification is insecure", func() {
+			err := handshake("internal.example",
+				tls.WithVerifyHostname("other.example"),
+				tls.WithInsecureSkipVerify(true),
+			)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	DescribeTable("PublicKeyPins.Set errors",
+		func(arg string, expected string) {
+			var pins tls.PublicKeyPins
+			Expect(pins.Set(arg)).To(MatchError(ContainSubstring(expected)))
+		},
+		Entry("missing prefix", "AAAA", "expected sha256//BASE64"),
+		Entry("wrong length", "sha256//AAAA", "not a base64-encoded SHA-256 hash"),
+		Entry("not base64", "sha256//!!", "not a base64-encoded SHA-256 hash"),
+	)
+
+	It("formats pins as a list", func() {
+		var pins tls.PublicKeyPins
+		Expect(pins.Set("sha256//AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=;sha256//AQAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=")).To(Succeed())
+		Expect(pins.String()).To(Equal("sha256//AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=,sha256//AQAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA="))
+	})
+})
+
+func newTestCertificates(name string) (*x509.CertPool, gotls.Certificate) {
+	caKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	ca := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, ca, ca, &caKey.PublicKey, caKey)
+	Expect(err).NotTo(HaveOccurred())
+	ca, _ = x509.ParseCertificate(caDER)
+
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+	Expect(err).NotTo(HaveOccurred())
+	leaf, _ := x509.ParseCertificate(der)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+	return roots, gotls.Certificate{
+		Certificate: [][]byte{der},
+		PrivateKey:  key,
+		Leaf:        leaf,
+	}
+}
@@ -21,6 +21,13 @@ type contextKey string
 type Config struct {
 	*gotls.Config
 	cli.Action
+
+	verifyHostname string
+	skipVerify     bool
+	pins           PublicKeyPins
+	certType       CertType
+	password       PasswordFunc
+	passwordPrompt PasswordFunc
 }
 
 // Option provides an option to the TLS configuration
@@ -68,6 +75,18 @@ func WithDefaultAction() Option {
 	}
 }
 
+// WithServerDefaultAction sets the action to the default used when
+// serving, which registers the flags that apply to servers
+func WithServerDefaultAction() Option {
+	return func(c *Config) error {
+		c.Action = cli.Pipeline(
+			ContextValue(c),
+			ServerFlagsAndArgs(),
+		)
+		return nil
+	}
+}
+
 func ContextValue(c *Config) cli.Action {
 	return cli.WithContextValue(servicesKey, c)
 }
@@ -94,7 +113,8 @@ func (c *Config) ensureCACertPool() *x509.CertPool {
 // handshake. This option is insecure.
 func WithInsecureSkipVerify(v bool) Option {
 	return func(c *Config) error {
-		c.InsecureSkipVerify = v
+		c.InsecureSkipVerify = v || c.verifyHostname != ""
+		c.skipVerify = v
 		return nil
 	}
 }
@@ -198,10 +218,50 @@ func AddRootCACertFile(filename string) Option {
 	}
 }
 
-// AddX509KeyPair adds a certificate to the pool
+// WithCertType sets the format of certificate files that are subsequently
+// added with AddX509KeyPair
+func WithCertType(t CertType) Option {
+	return func(c *Config) error {
+		c.certType = t
+		return nil
+	}
+}
+
+// WithPassword sets the password used to decrypt private keys
+func WithPassword(s string) Option {
+	return WithPasswordFunc(func() (string, error) {
+		return s, nil
+	})
+}
+
+// WithPasswordFunc sets the function which obtains the password used to
+// decrypt private keys.  It is only called if the key is encrypted.
+func WithPasswordFunc(fn PasswordFunc) Option {
+	return func(c *Config) error {
+		c.password = fn
+		return nil
+	}
+}
+
+// WithPasswordPrompt sets the function which prompts for the password used to
+// decrypt private keys when it wasn't set with WithPassword
+func WithPasswordPrompt(fn PasswordFunc) Option {
+	return func(c *Config) error {
+		c.passwordPrompt = fn
+		return nil
+	}
+}
+
+// AddX509KeyPair adds a certificate to the pool.  The format of the files
+// and the password for encrypted keys are those previously set with
+// WithCertType and WithPassword.
 func AddX509KeyPair(certFile, keyFile string) Option {
 	return func(cfg *Config) error {
-		cert, err := gotls.LoadX509KeyPair(certFile, keyFile)
+		password := cfg.password
+		if password == nil {
+			password = cfg.passwordPrompt
+		}
+		cert, err := LoadX509KeyPair(certFile, keyFile, cfg.certType, password)
 
 		if err != nil {
 			return err
@@ -211,6 +271,5 @@ func AddX509KeyPair(certFile, keyFile string) Option {
 }
 
 func (o Option) Execute(c context.Context) error {
-	o(FromContext(c))
-	return nil
+	return o(FromContext(c))
 }
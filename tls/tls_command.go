@@ -82,10 +82,9 @@ func SetClientCertFile(path ...string) cli.Action {
 		&cli.Prototype{
 			Name:      "cert",
 			Aliases:   []string{"E"},
-			HelpText:  "Client certificate file (PEM format)",
+			HelpText:  "Client certificate file (PEM or P12 format, see --cert-type)",
 			UsageText: "PATH",
 			Category:  tlsOptions,
-			Uses:      cli.Requires("key"),
 		},
 		bind.Action2(
 			AddX509KeyPair, bind.File("cert").Name(), bind.File("key").Name(),
@@ -109,6 +108,87 @@ func SetKeyFile(path ...string) cli.Action {
 	)
 }
 
+// SetCertType sets the format of the certificate file.  The action runs
+// before the certificate is loaded.
+func SetCertType(v ...CertType) cli.Action {
+	return cli.Pipeline(
+		&cli.Prototype{
+			Name:     "cert-type",
+			HelpText: "Format of the client certificate file, {TYPE} is PEM (default) or P12",
+			Category: tlsOptions,
+		},
+		bind.Before(WithCertType, bind.Exact(v...)),
+		tagged,
+	)
+}
+
+// SetPassword sets the pass phrase for the private key.  The action runs
+// before the certificate is loaded.
+func SetPassword(s ...string) cli.Action {
+	return cli.Pipeline(
+		&cli.Prototype{
+			Name:     "pass",
+			HelpText: "Pass {PHRASE} for the private key",
+			Category: tlsOptions,
+			Value:    new(string),
+		},
+		cli.Before(cli.IfMatch(cli.Seen, bind.Before(WithPassword, bind.Exact(s...)))),
+		tagged,
+	)
+}
+
+func SetClientCACertFile(path ...string) cli.Action {
+	return cli.Pipeline(
+		&cli.Prototype{
+			Name:      "client-ca",
+			HelpText:  "CA certificate to verify client certificates against (PEM format)",
+			UsageText: "PATH",
+			Options:   cli.EachOccurrence,
+			Category:  tlsOptions,
+			Uses:      cli.Implies("client-auth", "require"),
+		},
+		bind.Action(AddClientCACertFile, bind.Exact(path...)),
+		tagged,
+	)
+}
+
+func SetClientAuth(v ...ClientAuthType) cli.Action {
+	return cli.Pipeline(
+		&cli.Prototype{
+			Name:     "client-auth",
+			HelpText: "Sets the {POLICY} for client certificates: none, request, require-any, verify-if-given, or require",
+			Category: tlsOptions,
+			Options:  cli.ImpliedAction,
+		},
+		bind.Action(WithClientAuth, bind.Exact(v...)),
+		tagged,
+	)
+}
+
+func SetPinnedPublicKeys(v ...PublicKeyPins) cli.Action {
+	return cli.Pipeline(
+		&cli.Prototype{
+			Name:     "pinnedpubkey",
+			HelpText: "Require the peer public key to match one of the SHA-256 {HASHES} (sha256//BASE64)",
+			Category: tlsOptions,
+		},
+		bind.Action(WithPinnedPublicKeys, bind.Exact(v...)),
+		tagged,
+	)
+}
+
+func SetVerifyHostname(s ...string) cli.Action {
+	return cli.Pipeline(
+		&cli.Prototype{
+			Name:     "verify-hostname",
+			HelpText: "Verify the peer certificate against {HOSTNAME} instead of the server name",
+			Category: tlsOptions,
+		},
+		bind.Action(WithVerifyHostname, bind.Exact(s...)),
+		tagged,
+	)
+}
+
 func SetTime(s ...*cli.File) cli.Action {
 	return cli.Pipeline(
 		&cli.Prototype{
@@ -286,6 +366,7 @@ func FlagsAndArgs() cli.Action {
 			{Uses: ListCurves()},
 			{Uses: SetCACertFile()},
 			{Uses: SetCACertPath()},
+			{Uses: SetCertType()},
 			{Uses: SetCiphers()},
 			{Uses: SetClientCertFile()},
 			{Uses: SetCurves()},
@@ -293,9 +374,34 @@ func FlagsAndArgs() cli.Action {
 			{Uses: SetInsecureSkipVerify()},
 			{Uses: SetKeyFile()},
 			{Uses: SetNextProtos()},
+			{Uses: SetPassword()},
+			{Uses: SetPinnedPublicKeys()},
 			{Uses: SetRandom()},
 			{Uses: SetServerName()},
 			{Uses: SetTime()},
+			{Uses: SetVerifyHostname()},
+			{Uses: SetTLSv1()},
+			{Uses: SetTLSv1_0()},
+			{Uses: SetTLSv1_1()},
+			{Uses: SetTLSv1_2()},
+			{Uses: SetTLSv1_3()},
+		}...))
+}
+
+// ServerFlagsAndArgs provides the flags that apply when serving TLS.  Unlike
+// FlagsAndArgs, flags that only apply to clients are omitted.  The server
+// certificate is not specified by these flags.
+func ServerFlagsAndArgs() cli.Action {
+	return cli.Pipeline(
+		cli.AddFlags([]*cli.Flag{
+			{Uses: ListCiphers()},
+			{Uses: ListCurves()},
+			{Uses: SetCiphers()},
+			{Uses: SetClientAuth()},
+			{Uses: SetClientCACertFile()},
+			{Uses: SetCurves()},
+			{Uses: SetInsecureKeyLogFile()},
+			{Uses: SetNextProtos()},
 			{Uses: SetTLSv1()},
 			{Uses: SetTLSv1_0()},
 			{Uses: SetTLSv1_1()},
@@ -59,6 +59,12 @@ var _ = Describe("Set actions", func() {
 				"CipherSuites": Equal([]uint16{gotls.TLS_AES_128_GCM_SHA256}),
 			}),
 
+		Entry(
+			"SetClientAuth",
+			tls.SetClientAuth(),
+			"app -a verify-if-given",
+			Fields{"ClientAuth": Equal(gotls.VerifyClientCertIfGiven)}),
+
 		Entry(
 			"SetCurves",
 			tls.SetCurves(),
@@ -77,6 +83,12 @@ var _ = Describe("Set actions", func() {
 			"app -a F -a G",
 			Fields{"NextProtos": Equal([]string{"F", "G"})}),
 
+		Entry(
+			"SetVerifyHostname",
+			tls.SetVerifyHostname(),
+			"app -a internal.example",
+			Fields{"InsecureSkipVerify": BeTrue(), "VerifyConnection": Not(BeNil())}),
+
 		Entry(
 			"SetServerName",
 			tls.SetServerName(),